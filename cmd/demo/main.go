@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -9,6 +13,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/sokoide/rv32i-go/pkg/rv32i"
+	"github.com/sokoide/rv32i-go/pkg/rv32idebug"
+	"github.com/sokoide/rv32i-go/pkg/rv32idiff"
+	"github.com/sokoide/rv32i-go/pkg/rv32igdb"
 )
 
 func chkerr(err error) {
@@ -22,17 +29,39 @@ type options struct {
 	demo       bool
 	sourcePath string
 	end        string
+	root       string
+	max        uint64
+	timeout    time.Duration
+	gdb        string
+	debug      bool
+	commitLog  string
+	logFormat  string
+	difftest   string
 }
 
 var opts options = options{
-	demo: false,
+	demo:      false,
+	logFormat: "spike",
 }
 
 func parseArgs() {
 	flag.BoolVar(&opts.demo, "demo", false, "Run demo")
 	flag.StringVar(&opts.logLevel, "logLevel", opts.logLevel, "Log level (trace, debug, info, warn, error, fatal, panic)")
 	flag.StringVar(&opts.sourcePath, "sourcePath", opts.sourcePath, "Source path")
-	flag.StringVar(&opts.end, "end", opts.end, "End address")
+	flag.StringVar(&opts.end, "end", opts.end, "End address or symbol. Runs until exit if omitted")
+	flag.StringVar(&opts.root, "root", opts.root, "Directory the guest can open files in")
+	flag.Uint64Var(&opts.max, "max", opts.max, "Maximum number of instructions. Unlimited if 0")
+	flag.DurationVar(&opts.timeout, "timeout", opts.timeout, "Stops the guest after the duration. Unlimited if 0")
+	flag.StringVar(&opts.gdb, "gdb", opts.gdb, "Waits for GDB on the address, e.g. :1234, instead of running")
+	flag.StringVar(&opts.commitLog, "commitLog", opts.commitLog, "Writes the commit log of the instructions to the file")
+	flag.StringVar(&opts.logFormat, "commitLogFormat", opts.logFormat, "Commit log format (spike, spike-disasm, binary)")
+	flag.StringVar(&opts.difftest, "difftest", opts.difftest, "Runs in lockstep with the reference commit log of spike or rv32i, and reports the first divergence")
+	// "demo debug [flags]" starts the command line debugger
+	if len(os.Args) > 1 && os.Args[1] == "debug" {
+		opts.debug = true
+		flag.CommandLine.Parse(os.Args[2:])
+		return
+	}
 	flag.Parse()
 }
 
@@ -68,8 +97,9 @@ func runDemo() {
 	chkerr(err)
 
 	log.Info("*** code ***")
-	for i := 0; i < 0x108; i += 4 {
-		log.Infof("0x%08x: 0x%02x%02x%02x%02x,", i, emu.Memory[i+3], emu.Memory[i+2], emu.Memory[i+1], emu.Memory[i])
+	for i := uint32(0); i < 0x108; i += 4 {
+		u32, _ := emu.ReadU32(i)
+		log.Infof("0x%08x: 0x%08x,", i, u32)
 	}
 	log.Info("*** code end ***")
 
@@ -78,35 +108,158 @@ func runDemo() {
 	emu.StepUntil(0x1c)
 }
 
-func run(sourcePath string, end string) {
+// parseAddress accepts a hex (0x...) or decimal address, or a symbol name
+func parseAddress(emu *rv32i.Emulator, s string) (uint32, error) {
+	var u64 uint64
 	var err error
 
-	emu := rv32i.NewEmulator()
-	emu.Reset()
+	if strings.HasPrefix(s, "0x") {
+		u64, err = strconv.ParseUint(s[2:], 16, 32)
+	} else if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		u64, err = strconv.ParseUint(s, 10, 32)
+	} else if addr, ok := emu.Symbols.Lookup(s); ok {
+		u64 = uint64(addr)
+	} else {
+		err = fmt.Errorf("symbol %s not found", s)
+	}
+
+	return uint32(u64), err
+}
+
+// openCommitLog sets the Tracer which writes the commit log to path. The
+// returned function flushes and closes it.
+func openCommitLog(emu *rv32i.Emulator, path string, format string) (func() error, error) {
+	fp, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(fp)
+	switch format {
+	case "spike", "spike-disasm":
+		sw := rv32i.NewSpikeCommitWriter(w)
+		sw.Disassemble = format == "spike-disasm"
+		emu.Tracer = sw
+	case "binary":
+		emu.Tracer = rv32i.NewBinaryCommitWriter(w)
+	default:
+		fp.Close()
+		return nil, fmt.Errorf("unknown commit log format %s", format)
+	}
+	return func() error {
+		emu.Tracer = nil
+		if err := w.Flush(); err != nil {
+			fp.Close()
+			return err
+		}
+		return fp.Close()
+	}, nil
+}
+
+// difftest runs the emulator in lockstep with the reference commit log, and
+// returns 1 if they diverge
+func difftest(ctx context.Context, emu *rv32i.Emulator, runOpts rv32i.RunOptions, path string) int {
+	fp, err := os.Open(path)
+	chkerr(err)
+	defer fp.Close()
+
+	r, err := rv32idiff.Run(ctx, emu, rv32idiff.NewReader(fp), rv32idiff.Options{RunOptions: runOpts})
+	chkerr(err)
+	log.Infof("difftest: %d instructions matched, stopped: %v", r.Instructions, r.Stop)
+	if r.Divergence != nil {
+		err = r.Divergence.Report(os.Stdout)
+		chkerr(err)
+		return 1
+	}
+	return 0
+}
+
+func run(sourcePath string, end string) int {
+	// the debugger reads commands from stdin instead of the guest
+	var stdin io.Reader = os.Stdin
+	if opts.debug {
+		stdin = nil
+	}
+
+	// firmware can print through the UART as well as syscalls
+	cfg := rv32i.DefaultEmulatorConfig()
+	cfg.Console = os.Stdout
+	cfg.Devices = append(cfg.Devices, rv32i.Mapping{
+		Base:   rv32i.UARTBase,
+		Size:   rv32i.UARTSize,
+		Device: rv32i.NewUART(stdin, os.Stdout),
+		IRQ:    rv32i.UARTIRQ,
+	})
+	emu, err := rv32i.NewEmulatorWithConfig(cfg)
+	chkerr(err)
+	emu.Syscalls = rv32i.NewDefaultSyscalls(rv32i.SyscallConfig{
+		Stdin:  stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Root:   opts.root,
+	})
 
 	err = emu.Load(sourcePath)
 	chkerr(err)
 
+	if len(opts.commitLog) > 0 {
+		closeLog, err := openCommitLog(emu, opts.commitLog, opts.logFormat)
+		chkerr(err)
+		defer func() { chkerr(closeLog()) }()
+	}
+
+	if len(opts.gdb) > 0 {
+		err = rv32igdb.NewServer(emu).ListenAndServe(opts.gdb)
+		chkerr(err)
+		return 0
+	}
+	if opts.debug {
+		err = rv32idebug.NewDebugger(emu, os.Stdin, os.Stdout).Run()
+		chkerr(err)
+		return 0
+	}
+
+	runOpts := rv32i.RunOptions{
+		MaxInstructions: opts.max,
+		StopOnSelfLoop:  true,
+	}
 	if len(end) > 0 {
 		var uintEnd uint32
-		var uintEnd64 uint64
-		if strings.HasPrefix(end, "0x") {
-			uintEnd64, err = strconv.ParseUint(end[2:], 16, 32)
-			uintEnd = uint32(uintEnd64)
-		} else {
-			uintEnd64, err = strconv.ParseUint(end, 10, 32)
-			uintEnd = uint32(uintEnd64)
-		}
-
+		uintEnd, err = parseAddress(emu, end)
 		chkerr(err)
+		runOpts.StopPCs = []uint32{uintEnd}
+	}
+	ctx := context.Background()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
 
-		startTime := time.Now()
-		err = emu.StepUntil(uintEnd)
-		endTime := time.Now()
-		log.Infof("elapsed time: %v\n", endTime.Sub(startTime))
-	} else {
-		panic("please specify the end address")
+	if len(opts.difftest) > 0 {
+		return difftest(ctx, emu, runOpts, opts.difftest)
+	}
+
+	startTime := time.Now()
+	reason := emu.RunWithOptions(ctx, runOpts)
+	endTime := time.Now()
+	log.Infof("elapsed time: %v\n", endTime.Sub(startTime))
+	log.Infof("stats: %+v", emu.Stats())
+	log.Infof("stopped: %v", reason)
+
+	switch reason.Kind {
+	case rv32i.StopPC:
+		return 0
+	case rv32i.StopExit, rv32i.StopToHost:
+		log.Infof("exit code: %d", reason.ExitCode)
+		return reason.ExitCode
+	case rv32i.StopTrap:
+		log.Errorf("unhandled trap: %v", reason.Err)
+		emu.Dump()
+		return 1
+	case rv32i.StopError:
+		chkerr(reason.Err)
 	}
+	return 1
 }
 
 func main() {
@@ -125,11 +278,13 @@ func main() {
 	log.Info("* Started")
 	log.Info("* Running a small program")
 
+	code := 0
 	if opts.demo {
 		runDemo()
 	} else {
-		run(opts.sourcePath, opts.end)
+		code = run(opts.sourcePath, opts.end)
 	}
 
 	log.Info("* Completed")
+	os.Exit(code)
 }
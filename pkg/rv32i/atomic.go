@@ -0,0 +1,141 @@
+package rv32i
+
+// reservationSize is the size of a reservation set made by lr.w
+const reservationSize = uint32(4)
+
+// Reservations keeps the reservation sets of lr.w for each hart.
+// Reservations are shared by the harts on the same memory, and any write to
+// a reserved address invalidates the reservations of all harts.
+type Reservations struct {
+	addrs map[uint32]uint32 // mhartid -> reserved address
+}
+
+func NewReservations() *Reservations {
+	return &Reservations{
+		addrs: make(map[uint32]uint32),
+	}
+}
+
+// Reserve registers a reservation set at addr for the hart.
+// The previous reservation of the hart is released.
+func (r *Reservations) Reserve(hart uint32, addr uint32) {
+	r.addrs[hart] = addr
+}
+
+// Release clears the reservation of the hart. It returns true if the hart
+// had a valid reservation at addr.
+func (r *Reservations) Release(hart uint32, addr uint32) bool {
+	reserved, ok := r.addrs[hart]
+	delete(r.addrs, hart)
+	return ok && reserved == addr
+}
+
+// Invalidate clears the reservations which overlap addr..addr+size
+func (r *Reservations) Invalidate(addr uint32, size uint32) {
+	for hart, reserved := range r.addrs {
+		if uint64(addr) < uint64(reserved)+uint64(reservationSize) && uint64(reserved) < uint64(addr)+uint64(size) {
+			delete(r.addrs, hart)
+		}
+	}
+}
+
+// executeAtomic runs lr.w, sc.w and amo*.w.
+// aq and rl are ignored because instructions are executed in order.
+func (c *Cpu) executeAtomic(op OpName, i *Instruction) {
+	addr := c.X[i.Rs1]
+	hart := c.Csr.Mhartid
+
+	if op == OpLrW {
+		if addr%4 != 0 {
+			c.raise(CauseLoadAddressMisaligned, addr)
+			return
+		}
+		pa, err := c.translate(addr, accessLoad)
+		if err != nil {
+			c.err = err
+			return
+		}
+		data, ok := c.read(pa, addr, 4, accessLoad)
+		if !ok {
+			return
+		}
+		// reservations are physical addresses as stores invalidate them
+		c.Emu.Reservations.Reserve(hart, pa)
+		if i.Rd > 0 {
+			c.X[i.Rd] = data
+		}
+		return
+	}
+
+	// sc and amo raise store/AMO exceptions
+	if addr%4 != 0 {
+		c.raise(CauseStoreAddressMisaligned, addr)
+		return
+	}
+	pa, err := c.translate(addr, accessStore)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	if op == OpScW {
+		result := uint32(1)
+		if c.Emu.Reservations.Release(hart, pa) {
+			if !c.write(pa, addr, 4, c.X[i.Rs2]) {
+				return
+			}
+			result = 0
+		}
+		if i.Rd > 0 {
+			c.X[i.Rd] = result
+		}
+		return
+	}
+
+	old, ok := c.read(pa, addr, 4, accessStore)
+	if !ok {
+		return
+	}
+
+	src := c.X[i.Rs2]
+	var data uint32
+	switch op {
+	case OpAmoswapW:
+		data = src
+	case OpAmoaddW:
+		data = old + src
+	case OpAmoxorW:
+		data = old ^ src
+	case OpAmoandW:
+		data = old & src
+	case OpAmoorW:
+		data = old | src
+	case OpAmominW:
+		data = old
+		if int32(src) < int32(old) {
+			data = src
+		}
+	case OpAmomaxW:
+		data = old
+		if int32(src) > int32(old) {
+			data = src
+		}
+	case OpAmominuW:
+		data = old
+		if src < old {
+			data = src
+		}
+	case OpAmomaxuW:
+		data = old
+		if src > old {
+			data = src
+		}
+	}
+
+	if !c.write(pa, addr, 4, data) {
+		return
+	}
+	if i.Rd > 0 {
+		c.X[i.Rd] = old
+	}
+}
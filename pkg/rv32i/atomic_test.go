@@ -0,0 +1,155 @@
+package rv32i
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ExecuteA(t *testing.T) {
+	type TestData struct {
+		Op   OpName
+		Mem  uint32
+		Rs2  uint32
+		Want uint32 // memory after the instruction
+	}
+
+	for _, td := range []TestData{
+		{OpAmoswapW, 5, 7, 7},
+		{OpAmoaddW, 5, 0xffffffff, 4},
+		{OpAmoxorW, 0b1100, 0b1010, 0b0110},
+		{OpAmoandW, 0b1100, 0b1010, 0b1000},
+		{OpAmoorW, 0b1100, 0b1010, 0b1110},
+		{OpAmominW, 5, 0xffffffff, 0xffffffff},
+		{OpAmomaxW, 5, 0xffffffff, 5},
+		{OpAmominuW, 5, 0xffffffff, 5},
+		{OpAmomaxuW, 5, 0xffffffff, 0xffffffff},
+	} {
+		e := NewEmulator()
+		e.WriteU32(0x100, td.Mem)
+		e.Cpu.X[10] = 0x100
+		e.Cpu.X[11] = td.Rs2
+		loadCode(e, 0, GenCode(td.Op, 12, 10, 11))
+
+		if err := e.Step(); err != nil {
+			t.Fatalf("%v: %v", td.Op, err)
+		}
+		if e.Cpu.X[12] != td.Mem {
+			t.Errorf("%v: rd must be 0x%08x, but was 0x%08x", td.Op, td.Mem, e.Cpu.X[12])
+		}
+		got, _ := e.ReadU32(0x100)
+		if got != td.Want {
+			t.Errorf("%v: memory must be 0x%08x, but was 0x%08x", td.Op, td.Want, got)
+		}
+	}
+}
+
+func Test_LrSc(t *testing.T) {
+	lrsc := []uint32{
+		GenCode(OpLrW, 12, 10, 0),
+		GenCode(OpScW, 13, 10, 11),
+	}
+
+	// sc succeeds after lr
+	e := NewEmulator()
+	e.WriteU32(0x100, 5)
+	e.Cpu.X[10] = 0x100
+	e.Cpu.X[11] = 7
+	loadCode(e, 0, lrsc...)
+	e.Step()
+	e.Step()
+	got, _ := e.ReadU32(0x100)
+	if e.Cpu.X[12] != 5 || e.Cpu.X[13] != 0 || got != 7 {
+		t.Errorf("lr/sc failed. lr:%d, sc:%d, mem:%d", e.Cpu.X[12], e.Cpu.X[13], got)
+	}
+
+	// sc fails without a reservation
+	e.Cpu.PC = 4
+	e.Cpu.X[11] = 9
+	e.Step()
+	got, _ = e.ReadU32(0x100)
+	if e.Cpu.X[13] != 1 || got != 7 {
+		t.Errorf("sc without lr must fail. sc:%d, mem:%d", e.Cpu.X[13], got)
+	}
+
+	// sc fails if another hart stored to the reserved address
+	e = NewEmulator()
+	e.Cpu.X[10] = 0x100
+	e.Cpu.X[11] = 7
+	loadCode(e, 0, lrsc...)
+	e.Step()
+	e.Reservations.Reserve(1, 0x100)
+	e.WriteU8(0x102, 0xff)
+	e.Step()
+	got, _ = e.ReadU32(0x100)
+	if e.Cpu.X[13] != 1 || got != 0x00ff0000 {
+		t.Errorf("sc after a store must fail. sc:%d, mem:0x%08x", e.Cpu.X[13], got)
+	}
+	if e.Reservations.Release(1, 0x100) {
+		t.Error("the reservation of hart 1 must be invalidated")
+	}
+
+	// a store to another address keeps the reservation
+	e = NewEmulator()
+	e.Cpu.X[10] = 0x100
+	e.Cpu.X[11] = 7
+	loadCode(e, 0, lrsc...)
+	e.Step()
+	e.WriteU32(0x104, 1)
+	e.Step()
+	if e.Cpu.X[13] != 0 {
+		t.Errorf("sc must succeed. sc:%d", e.Cpu.X[13])
+	}
+}
+
+func Test_AtomicTrap(t *testing.T) {
+	type TestData struct {
+		Op    OpName
+		Addr  uint32
+		Cause TrapCause
+	}
+
+	for _, td := range []TestData{
+		{OpLrW, 0x102, CauseLoadAddressMisaligned},
+		{OpScW, 0x102, CauseStoreAddressMisaligned},
+		{OpAmoaddW, 0x101, CauseStoreAddressMisaligned},
+		{OpLrW, MaxMemory, CauseLoadAccessFault},
+		{OpAmoswapW, MaxMemory, CauseStoreAccessFault},
+	} {
+		e := NewEmulator()
+		e.Cpu.X[10] = td.Addr
+		rs2 := 11
+		if td.Op == OpLrW {
+			rs2 = 0
+		}
+		loadCode(e, 0, GenCode(td.Op, 12, 10, rs2))
+
+		err := e.Step()
+		var trap *Trap
+		if !errors.As(err, &trap) {
+			t.Errorf("%v: Step must return *Trap, but was %v", td.Op, err)
+			continue
+		}
+		if trap.Cause != td.Cause || trap.Tval != td.Addr {
+			t.Errorf("%v: got %+v, want cause:%v, tval:0x%x", td.Op, trap, td.Cause, td.Addr)
+		}
+	}
+}
+
+func Test_AtomicCodeString(t *testing.T) {
+	type TestData struct {
+		Code uint32
+		Want string
+	}
+
+	for _, td := range []TestData{
+		{0x1005262f, "lr.w a2, (a0)"},
+		{0x1ab5262f, "sc.w.rl a2, a1, (a0)"},
+		{0x06b5262f, "amoadd.w.aqrl a2, a1, (a0)"},
+		{0xe0b5262f, "amomaxu.w a2, a1, (a0)"},
+	} {
+		got := NewInstruction(td.Code).GetCodeString()
+		if got != td.Want {
+			t.Errorf("0x%08x: got %s, want %s", td.Code, got, td.Want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package rv32i
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BreakpointID identifies a breakpoint or a watchpoint of the Emulator
+type BreakpointID int
+
+//go:generate stringer -type WatchKind -trimprefix Watch
+type WatchKind int
+
+const (
+	// WatchNone is an execution breakpoint
+	WatchNone WatchKind = iota
+	// WatchWrite stops after a store or an AMO writes the range
+	WatchWrite
+	// WatchRead stops after a load or an AMO reads the range
+	WatchRead
+	// WatchAccess stops after any of them
+	WatchAccess
+)
+
+// Condition is the predicate of a conditional breakpoint
+type Condition func(e *Emulator) bool
+
+// RegisterEquals is a Condition that x[reg] is v
+func RegisterEquals(reg int, v uint32) Condition {
+	return func(e *Emulator) bool {
+		return e.Cpu.X[reg] == v
+	}
+}
+
+// MemoryEquals is a Condition that the word at addr is v
+func MemoryEquals(addr uint32, v uint32) Condition {
+	return func(e *Emulator) bool {
+		u32, err := e.ReadU32(addr)
+		return err == nil && u32 == v
+	}
+}
+
+// Breakpoint is an execution breakpoint at PC, or a watchpoint on
+// [Addr, Addr+Size) if Kind is not WatchNone
+type Breakpoint struct {
+	ID   BreakpointID
+	Kind WatchKind
+	PC   uint32
+	Cond Condition // stops only if it returns true. nil stops always
+	Addr uint32
+	Size uint32
+}
+
+func (bp *Breakpoint) String() string {
+	if bp.Kind == WatchNone {
+		s := fmt.Sprintf("breakpoint %d at 0x%08x", bp.ID, bp.PC)
+		if bp.Cond != nil {
+			s += " (conditional)"
+		}
+		return s
+	}
+	return fmt.Sprintf("%v watchpoint %d on 0x%08x-0x%08x", bp.Kind, bp.ID, bp.Addr, uint64(bp.Addr)+uint64(bp.Size)-1)
+}
+
+// breakpoints are the breakpoints and watchpoints RunWithOptions stops at
+type breakpoints struct {
+	lastID  BreakpointID
+	pcs     map[uint32][]*Breakpoint
+	watches []*Breakpoint
+	hit     *Breakpoint // the watchpoint hit by the current instruction
+	hitAddr uint32
+}
+
+// AddBreakpoint adds a breakpoint at pc, which stops only if cond returns
+// true unless cond is nil
+func (e *Emulator) AddBreakpoint(pc uint32, cond Condition) BreakpointID {
+	b := &e.breakpoints
+	if b.pcs == nil {
+		b.pcs = map[uint32][]*Breakpoint{}
+	}
+	b.lastID++
+	b.pcs[pc] = append(b.pcs[pc], &Breakpoint{ID: b.lastID, PC: pc, Cond: cond})
+	return b.lastID
+}
+
+// AddWatchpoint adds a watchpoint on the size bytes from addr, which is
+// compared with the virtual addresses of the guest
+func (e *Emulator) AddWatchpoint(kind WatchKind, addr uint32, size uint32) (BreakpointID, error) {
+	if kind <= WatchNone || kind > WatchAccess {
+		return 0, fmt.Errorf("invalid watchpoint kind %d", kind)
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("watchpoint size must not be 0")
+	}
+	b := &e.breakpoints
+	b.lastID++
+	b.watches = append(b.watches, &Breakpoint{ID: b.lastID, Kind: kind, Addr: addr, Size: size})
+	return b.lastID, nil
+}
+
+// RemoveBreakpoint removes a breakpoint or a watchpoint. It returns false if
+// id doesn't exist.
+func (e *Emulator) RemoveBreakpoint(id BreakpointID) bool {
+	b := &e.breakpoints
+	for pc, bps := range b.pcs {
+		for i, bp := range bps {
+			if bp.ID != id {
+				continue
+			}
+			if len(bps) == 1 {
+				delete(b.pcs, pc)
+			} else {
+				b.pcs[pc] = append(bps[:i:i], bps[i+1:]...)
+			}
+			return true
+		}
+	}
+	for i, bp := range b.watches {
+		if bp.ID == id {
+			b.watches = append(b.watches[:i:i], b.watches[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearBreakpoints removes all of the breakpoints and watchpoints
+func (e *Emulator) ClearBreakpoints() {
+	e.breakpoints = breakpoints{lastID: e.breakpoints.lastID}
+}
+
+// Breakpoints returns copies of the breakpoints and watchpoints in the order
+// of IDs
+func (e *Emulator) Breakpoints() []Breakpoint {
+	b := &e.breakpoints
+	list := make([]Breakpoint, 0, len(b.watches))
+	for _, bps := range b.pcs {
+		for _, bp := range bps {
+			list = append(list, *bp)
+		}
+	}
+	for _, bp := range b.watches {
+		list = append(list, *bp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// breakpointAt returns the first breakpoint at pc whose condition is true
+func (e *Emulator) breakpointAt(pc uint32) *Breakpoint {
+	for _, bp := range e.breakpoints.pcs[pc] {
+		if bp.Cond == nil || bp.Cond(e) {
+			return bp
+		}
+	}
+	return nil
+}
+
+// accessed is called after the guest accessed memory at the virtual address
+func (e *Emulator) accessed(addr uint32, size uint32, write bool) {
+	if h := e.MemoryHook; h != nil {
+		h(addr, size, write)
+	}
+	b := &e.breakpoints
+	if b.hit != nil {
+		return
+	}
+	for _, bp := range b.watches {
+		if bp.Kind == WatchWrite && !write || bp.Kind == WatchRead && write {
+			continue
+		}
+		if uint64(addr) < uint64(bp.Addr)+uint64(bp.Size) && uint64(bp.Addr) < uint64(addr)+uint64(size) {
+			b.hit = bp
+			b.hitAddr = addr
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package rv32i
+
+import (
+	"context"
+	"testing"
+)
+
+// newLoopEmulator counts t0 up to 5 with a store to 0x100 and a load from
+// 0x104 in each iteration, and loops at 0x14
+func newLoopEmulator() *Emulator {
+	t0, t1, t2 := Regs["t0"], Regs["t1"], Regs["t2"]
+	e := NewEmulator()
+	loadCode(e, 0,
+		GenCode(OpAddi, t2, 0, 5),
+		GenCode(OpAddi, t0, t0, 1), // 0x4
+		GenCode(OpSw, t0, 0x100, 0),
+		GenCode(OpLw, t1, 0x104, 0), // 0xc
+		GenCode(OpBne, t0, t2, -12),
+		GenCode(OpJal, 0, 0, 0), // 0x14
+	)
+	return e
+}
+
+func Test_Breakpoint(t *testing.T) {
+	e := newLoopEmulator()
+	ctx := context.Background()
+	opts := RunOptions{StopOnSelfLoop: true}
+
+	id := e.AddBreakpoint(0xc, RegisterEquals(Regs["t0"], 3))
+	r := e.RunWithOptions(ctx, opts)
+	if r.Kind != StopBreakpoint || r.PC != 0xc || r.Breakpoint.ID != id || e.Cpu.X[Regs["t0"]] != 3 {
+		t.Errorf("run must stop at the conditional breakpoint, but was %v", r)
+	}
+
+	// resumes from the breakpoint
+	id2 := e.AddBreakpoint(0x4, nil)
+	r = e.RunWithOptions(ctx, opts)
+	if r.Kind != StopBreakpoint || r.PC != 0x4 || r.Breakpoint.ID != id2 || r.Steps != 2 {
+		t.Errorf("run must stop at 0x4, but was %v", r)
+	}
+	if !e.RemoveBreakpoint(id2) || e.RemoveBreakpoint(id2) {
+		t.Error("breakpoint must be removed once")
+	}
+
+	id3 := e.AddBreakpoint(0x10, MemoryEquals(0x100, 5))
+	r = e.RunWithOptions(ctx, opts)
+	if r.Kind != StopBreakpoint || r.PC != 0x10 || r.Breakpoint.ID != id3 || e.Cpu.X[Regs["t0"]] != 5 {
+		t.Errorf("run must stop at the memory condition, but was %v", r)
+	}
+
+	if bps := e.Breakpoints(); len(bps) != 2 || bps[0].ID != id || bps[1].ID != id3 {
+		t.Errorf("Breakpoints must return %d and %d, but was %v", id, id3, bps)
+	}
+	e.ClearBreakpoints()
+	if r = e.RunWithOptions(ctx, opts); r.Kind != StopSelfLoop {
+		t.Errorf("run must not stop at the cleared breakpoints, but was %v", r)
+	}
+}
+
+func Test_Watchpoint(t *testing.T) {
+	type TestData struct {
+		Kind WatchKind
+		Addr uint32
+		Size uint32
+		PC   uint32
+		Hit  uint32
+	}
+	for _, td := range []TestData{
+		{WatchWrite, 0x102, 2, 0xc, 0x100},
+		{WatchWrite, 0x104, 4, 0x14, 0},
+		{WatchRead, 0x104, 1, 0x10, 0x104},
+		{WatchRead, 0x100, 4, 0x14, 0},
+		{WatchAccess, 0x0fc, 8, 0xc, 0x100},
+	} {
+		e := newLoopEmulator()
+		id, err := e.AddWatchpoint(td.Kind, td.Addr, td.Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := e.RunWithOptions(context.Background(), RunOptions{StopOnSelfLoop: true})
+		if td.Hit == 0 {
+			if r.Kind != StopSelfLoop {
+				t.Errorf("%v 0x%x must not stop, but was %v", td.Kind, td.Addr, r)
+			}
+			continue
+		}
+		if r.Kind != StopWatchpoint || r.PC != td.PC || r.Breakpoint.ID != id || r.WatchAddr != td.Hit {
+			t.Errorf("%v 0x%x must stop at 0x%x, but was %v", td.Kind, td.Addr, td.PC, r)
+		}
+	}
+
+	e := NewEmulator()
+	if _, err := e.AddWatchpoint(WatchNone, 0, 4); err == nil {
+		t.Error("WatchNone must be an error")
+	}
+	if _, err := e.AddWatchpoint(WatchRead, 0, 0); err == nil {
+		t.Error("size 0 must be an error")
+	}
+}
@@ -0,0 +1,219 @@
+package rv32i
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Mapping is a device mapped at Base..Base+Size on the Bus
+type Mapping struct {
+	Base   uint32
+	Size   uint32
+	Device Device
+	IRQ    uint32 // PLIC source of the device's interrupt, 0 if none
+}
+
+// End returns the address next to the last byte of the mapping
+func (m *Mapping) End() uint64 {
+	return uint64(m.Base) + uint64(m.Size)
+}
+
+func (m *Mapping) contains(addr uint32, size uint32) bool {
+	return addr >= m.Base && uint64(addr)+uint64(size) <= m.End()
+}
+
+// Bus routes physical addresses to the mapped devices
+type Bus struct {
+	mappings []*Mapping // sorted by Base
+	last     *Mapping   // cache of the last found mapping
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Map maps dev at base..base+size. Mappings must not be empty nor overlap.
+func (b *Bus) Map(base uint32, size uint32, dev Device) error {
+	m := &Mapping{Base: base, Size: size, Device: dev}
+	if size == 0 {
+		return fmt.Errorf("mapping at 0x%08x is empty", base)
+	}
+	for _, o := range b.mappings {
+		if uint64(m.Base) < o.End() && uint64(o.Base) < m.End() {
+			return fmt.Errorf("mapping 0x%08x-0x%08x overlaps 0x%08x-0x%08x", m.Base, m.End(), o.Base, o.End())
+		}
+	}
+	b.mappings = append(b.mappings, m)
+	sort.Slice(b.mappings, func(i, j int) bool { return b.mappings[i].Base < b.mappings[j].Base })
+	return nil
+}
+
+// Mappings returns the mappings sorted by their base addresses
+func (b *Bus) Mappings() []*Mapping {
+	return b.mappings
+}
+
+// find returns the mapping which has addr..addr+size, or nil
+func (b *Bus) find(addr uint32, size uint32) *Mapping {
+	if b.last != nil && b.last.contains(addr, size) {
+		return b.last
+	}
+	i := sort.Search(len(b.mappings), func(i int) bool { return b.mappings[i].End() > uint64(addr) })
+	if i < len(b.mappings) && b.mappings[i].contains(addr, size) {
+		b.last = b.mappings[i]
+		return b.last
+	}
+	return nil
+}
+
+// IsMapped returns true if every byte of addr..addr+size is mapped.
+// The range can span adjacent mappings.
+func (b *Bus) IsMapped(addr uint32, size uint32) bool {
+	end := uint64(addr) + uint64(size)
+	for cur := uint64(addr); cur < end; {
+		m := b.find(uint32(cur), 1)
+		if m == nil {
+			return false
+		}
+		cur = m.End()
+	}
+	return true
+}
+
+func unmapped(addr uint32, size uint32) error {
+	return fmt.Errorf("0x%08x-0x%08x is not mapped", addr, uint64(addr)+uint64(size))
+}
+
+// Read reads 1, 2 or 4 bytes. An access across mappings is split into bytes.
+func (b *Bus) Read(addr uint32, size uint32) (uint32, error) {
+	if m := b.find(addr, size); m != nil {
+		return m.Device.Read(addr-m.Base, size)
+	}
+	if size == 1 || !b.IsMapped(addr, size) {
+		return 0, unmapped(addr, size)
+	}
+	var data uint32
+	for i := uint32(0); i < size; i++ {
+		u8, err := b.Read(addr+i, 1)
+		if err != nil {
+			return 0, err
+		}
+		data |= u8 << (8 * i)
+	}
+	return data, nil
+}
+
+// Write writes 1, 2 or 4 bytes. An access across mappings is split into bytes.
+func (b *Bus) Write(addr uint32, size uint32, data uint32) error {
+	if m := b.find(addr, size); m != nil {
+		return m.Device.Write(addr-m.Base, size, data)
+	}
+	if size == 1 || !b.IsMapped(addr, size) {
+		return unmapped(addr, size)
+	}
+	for i := uint32(0); i < size; i++ {
+		if err := b.Write(addr+i, 1, data>>(8*i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// each calls f for every part of addr..addr+len(data) in each mapping
+func (b *Bus) each(addr uint32, data []byte, f func(m *Mapping, offset uint32, part []byte) error) error {
+	if !b.IsMapped(addr, uint32(len(data))) {
+		return unmapped(addr, uint32(len(data)))
+	}
+	for len(data) > 0 {
+		m := b.find(addr, 1)
+		n := m.End() - uint64(addr)
+		if n > uint64(len(data)) {
+			n = uint64(len(data))
+		}
+		if err := f(m, addr-m.Base, data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+		addr += uint32(n)
+	}
+	return nil
+}
+
+// ReadBytes copies len(data) bytes at addr into data
+func (b *Bus) ReadBytes(addr uint32, data []byte) error {
+	return b.each(addr, data, func(m *Mapping, offset uint32, part []byte) error {
+		if d, ok := m.Device.(bytesDevice); ok {
+			return d.ReadBytes(offset, part)
+		}
+		for i := range part {
+			u8, err := m.Device.Read(offset+uint32(i), 1)
+			if err != nil {
+				return err
+			}
+			part[i] = uint8(u8)
+		}
+		return nil
+	})
+}
+
+// WriteBytes copies data to addr
+func (b *Bus) WriteBytes(addr uint32, data []byte) error {
+	return b.each(addr, data, func(m *Mapping, offset uint32, part []byte) error {
+		if d, ok := m.Device.(bytesDevice); ok {
+			return d.WriteBytes(offset, part)
+		}
+		for i, u8 := range part {
+			if err := m.Device.Write(offset+uint32(i), 1, uint32(u8)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load is WriteBytes for the loader. It can write read-only devices like ROM.
+func (b *Bus) Load(addr uint32, data []byte) error {
+	return b.each(addr, data, func(m *Mapping, offset uint32, part []byte) error {
+		if d, ok := m.Device.(loadableDevice); ok {
+			return d.Load(offset, part)
+		}
+		return fmt.Errorf("can't load into the device at 0x%08x", m.Base)
+	})
+}
+
+// Clear is Load of size zero bytes. RAM doesn't allocate pages for it.
+func (b *Bus) Clear(addr uint32, size uint32) error {
+	if !b.IsMapped(addr, size) {
+		return unmapped(addr, size)
+	}
+	end := uint64(addr) + uint64(size)
+	for cur := uint64(addr); cur < end; {
+		m := b.find(uint32(cur), 1)
+		n := m.End() - cur
+		if n > end-cur {
+			n = end - cur
+		}
+		offset := uint32(cur) - m.Base
+		switch d := m.Device.(type) {
+		case clearableDevice:
+			if err := d.Clear(offset, uint32(n)); err != nil {
+				return err
+			}
+		case loadableDevice:
+			if err := d.Load(offset, make([]byte, n)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("can't load into the device at 0x%08x", m.Base)
+		}
+		cur += n
+	}
+	return nil
+}
+
+// Tick ticks every device
+func (b *Bus) Tick() {
+	for _, m := range b.mappings {
+		m.Device.Tick()
+	}
+}
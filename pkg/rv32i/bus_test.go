@@ -0,0 +1,149 @@
+package rv32i
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testDevice records the last access
+type testDevice struct {
+	offset uint32
+	size   uint32
+	data   uint32
+	ticks  int
+}
+
+func (d *testDevice) Read(offset uint32, size uint32) (uint32, error) {
+	d.offset, d.size = offset, size
+	return d.data, nil
+}
+
+func (d *testDevice) Write(offset uint32, size uint32, data uint32) error {
+	d.offset, d.size, d.data = offset, size, data
+	return nil
+}
+
+func (d *testDevice) Tick() {
+	d.ticks++
+}
+
+func Test_BusMap(t *testing.T) {
+	type TestData struct {
+		Base uint32
+		Size uint32
+		Ok   bool
+	}
+
+	b := NewBus()
+	for _, td := range []TestData{
+		{0x1000, 0x1000, true},
+		{0xfffff000, 0x1000, true},
+		{0x2000, 0x1000, true},
+		{0x0, 0x1001, false},
+		{0x2fff, 0x10, false},
+		{0x8000, 0, false},
+	} {
+		err := b.Map(td.Base, td.Size, NewRAM(td.Size))
+		if (err == nil) != td.Ok {
+			t.Errorf("0x%08x+0x%x: got %v, want ok:%v", td.Base, td.Size, err, td.Ok)
+		}
+	}
+
+	type MappedData struct {
+		Addr uint32
+		Size uint32
+		Want bool
+	}
+	for _, td := range []MappedData{
+		{0x1000, 4, true},
+		{0x0ffc, 4, false},
+		{0x1ffe, 4, true}, // adjacent mappings
+		{0x2ffe, 4, false},
+		{0xfffffffc, 4, true},
+		{0xfffffffe, 4, false},
+		{0x3000, 0, true},
+	} {
+		if got := b.IsMapped(td.Addr, td.Size); got != td.Want {
+			t.Errorf("IsMapped(0x%08x, %d) must be %v", td.Addr, td.Size, td.Want)
+		}
+	}
+
+	// a word across adjacent mappings
+	if err := b.Write(0x1ffe, 4, 0x11223344); err != nil {
+		t.Error(err)
+	}
+	if v, _ := b.Read(0x1ffe, 4); v != 0x11223344 {
+		t.Errorf("got 0x%08x", v)
+	}
+	if _, err := b.Read(0x2ffe, 4); err == nil {
+		t.Error("read across an unmapped address must fail")
+	}
+}
+
+func Test_BusDevice(t *testing.T) {
+	b := NewBus()
+	dev := &testDevice{}
+	b.Map(0x0, 0x1000, NewRAM(0x1000))
+	b.Map(0x10000000, 0x100, dev)
+
+	// routed with the offset in the device
+	b.Write(0x10000004, 2, 0xabcd)
+	if dev.offset != 4 || dev.size != 2 || dev.data != 0xabcd {
+		t.Errorf("wrong write %+v", dev)
+	}
+	if v, _ := b.Read(0x10000008, 4); v != 0xabcd || dev.offset != 8 || dev.size != 4 {
+		t.Errorf("wrong read 0x%x %+v", v, dev)
+	}
+
+	// bytes are accessed one by one
+	b.WriteBytes(0x10000010, []byte{1, 2})
+	if dev.offset != 0x11 || dev.size != 1 || dev.data != 2 {
+		t.Errorf("wrong write %+v", dev)
+	}
+
+	b.Tick()
+	b.Tick()
+	if dev.ticks != 2 {
+		t.Errorf("ticks must be 2, but was %d", dev.ticks)
+	}
+
+	// only RAM and ROM can be loaded
+	if err := b.Load(0x10000000, []byte{1}); err == nil {
+		t.Error("load into a device must fail")
+	}
+}
+
+func Test_ROM(t *testing.T) {
+	rom := NewROM(0x100)
+	e, err := NewEmulatorWithConfig(EmulatorConfig{
+		Memory:  []MemoryRegion{{0x80000000, 0x1000}},
+		Devices: []Mapping{{Base: 0x1000, Size: 0x100, Device: rom}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// programs are loaded into the lowest ROM or RAM
+	e.LoadString(`00001000 <boot>:
+    1000: 93 00 00 00   li      ra, 0
+    1004: 23 20 00 00   sw      zero, 0(zero)
+`)
+	if e.Cpu.PC != 0x1000 {
+		t.Errorf("PC must be 0x1000, but was 0x%08x", e.Cpu.PC)
+	}
+	got, _ := e.ReadBytes(0x1000, 4)
+	if !bytes.Equal(got, []byte{0x93, 0, 0, 0}) {
+		t.Errorf("got %x", got)
+	}
+
+	// the guest can't write ROM
+	e.Cpu.X[1] = 0x1000
+	loadCode(e, 0x80000000, GenCode(OpSw, 0, 0, 1))
+	e.Cpu.PC = 0x80000000
+	err = e.Step()
+	var trap *Trap
+	if !errors.As(err, &trap) || trap.Cause != CauseStoreAccessFault || trap.Tval != 0x1000 {
+		t.Errorf("sw to ROM must raise a store access fault, but got %v", err)
+	}
+}
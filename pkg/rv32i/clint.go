@@ -0,0 +1,158 @@
+package rv32i
+
+import (
+	"time"
+)
+
+// default CLINT mapping, same as SiFive and QEMU virt
+const (
+	CLINTBase = uint32(0x0200_0000)
+	CLINTSize = uint32(0x1_0000)
+)
+
+// CLINT registers
+const (
+	clintMsip     = uint32(0x0000) // 4 bytes per hart
+	clintMtimecmp = uint32(0x4000) // 8 bytes per hart
+	clintMtime    = uint32(0xbff8)
+)
+
+// TimeMode selects how mtime advances
+type TimeMode int
+
+const (
+	// TimeInstret advances mtime by 1 per instruction, so runs are deterministic
+	TimeInstret TimeMode = iota
+	// TimeWallClock advances mtime at TimebaseFreq in the host time
+	TimeWallClock
+)
+
+// TimebaseFreq is the mtime frequency in TimeWallClock mode
+const TimebaseFreq = 10_000_000
+
+// wallClockInterval is the number of ticks between updates of mip.MTIP in
+// TimeWallClock mode, as reading the host clock every instruction is slow
+const wallClockInterval = 256
+
+// CLINT is the core local interruptor which has msip, mtimecmp and mtime.
+// It sets mip.MSIP and mip.MTIP of each hart.
+type CLINT struct {
+	mode TimeMode
+
+	harts    []*Cpu
+	msip     []uint32
+	mtimecmp []uint64
+
+	mtime uint64    // mtime in TimeInstret mode, or at start in TimeWallClock mode
+	start time.Time // when mtime was set in TimeWallClock mode
+	ticks uint32
+}
+
+func NewCLINT(mode TimeMode, harts ...*Cpu) *CLINT {
+	c := &CLINT{
+		mode:     mode,
+		harts:    harts,
+		msip:     make([]uint32, len(harts)),
+		mtimecmp: make([]uint64, len(harts)),
+		start:    time.Now(),
+	}
+	// timer interrupts don't fire until mtimecmp is set
+	for i := range c.mtimecmp {
+		c.mtimecmp[i] = ^uint64(0)
+	}
+	return c
+}
+
+// Mtime returns the current mtime
+func (c *CLINT) Mtime() uint64 {
+	if c.mode == TimeWallClock {
+		return c.mtime + uint64(time.Since(c.start))/(uint64(time.Second)/TimebaseFreq)
+	}
+	return c.mtime
+}
+
+// SetMode changes how mtime advances from now
+func (c *CLINT) SetMode(mode TimeMode) {
+	mtime := c.Mtime()
+	c.mode = mode
+	c.SetMtime(mtime)
+}
+
+// SetMtime sets mtime
+func (c *CLINT) SetMtime(mtime uint64) {
+	c.mtime = mtime
+	c.start = time.Now()
+	c.update()
+}
+
+func (c *CLINT) Read(offset uint32, size uint32) (uint32, error) {
+	var data uint64
+	var base uint32
+
+	switch {
+	case offset < clintMsip+4*uint32(len(c.harts)):
+		base = offset &^ 3
+		data = uint64(c.msip[(offset-clintMsip)/4])
+	case offset >= clintMtimecmp && offset < clintMtimecmp+8*uint32(len(c.harts)):
+		base = offset &^ 7
+		data = c.mtimecmp[(offset-clintMtimecmp)/8]
+	case offset >= clintMtime && offset < clintMtime+8:
+		base = clintMtime
+		data = c.Mtime()
+	default:
+		return 0, nil
+	}
+	return uint32(data >> (8 * (offset - base))), nil
+}
+
+func (c *CLINT) Write(offset uint32, size uint32, data uint32) error {
+	switch {
+	case offset < clintMsip+4*uint32(len(c.harts)):
+		// only bit 0 is writable
+		if offset%4 == 0 {
+			c.msip[(offset-clintMsip)/4] = data & 1
+		}
+	case offset >= clintMtimecmp && offset < clintMtimecmp+8*uint32(len(c.harts)):
+		i := (offset - clintMtimecmp) / 8
+		c.mtimecmp[i] = setBytes(c.mtimecmp[i], offset%8, size, data)
+	case offset >= clintMtime && offset < clintMtime+8:
+		c.SetMtime(setBytes(c.Mtime(), offset-clintMtime, size, data))
+	}
+	c.update()
+	return nil
+}
+
+// setBytes replaces size bytes at the byte offset of u64
+func setBytes(u64 uint64, offset uint32, size uint32, data uint32) uint64 {
+	shift := 8 * offset
+	mask := uint64(1)<<(8*size) - 1
+	return u64&^(mask<<shift) | (uint64(data)&mask)<<shift
+}
+
+// Tick advances mtime and updates the interrupt pending bits
+func (c *CLINT) Tick() {
+	if c.mode == TimeInstret {
+		c.mtime++
+	} else {
+		c.ticks++
+		if c.ticks%wallClockInterval != 0 {
+			return
+		}
+	}
+	c.update()
+}
+
+// update sets mip.MSIP and mip.MTIP of every hart
+func (c *CLINT) update() {
+	mtime := c.Mtime()
+	for i, h := range c.harts {
+		mip := h.Csr.Mip &^ (MipMSIP | MipMTIP)
+		if c.msip[i] != 0 {
+			mip |= MipMSIP
+		}
+		if mtime >= c.mtimecmp[i] {
+			mip |= MipMTIP
+		}
+		h.Csr.Mip = mip
+	}
+}
@@ -0,0 +1,123 @@
+package rv32i
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_CLINTRegisters(t *testing.T) {
+	cpu := NewCpu()
+	c := NewCLINT(TimeInstret, cpu)
+
+	c.Write(clintMtimecmp, 4, 0x89abcdef)
+	c.Write(clintMtimecmp+4, 4, 0x01234567)
+	c.Write(clintMtimecmp+2, 1, 0xff)
+	lo, _ := c.Read(clintMtimecmp, 4)
+	hi, _ := c.Read(clintMtimecmp+4, 4)
+	if lo != 0x89ffcdef || hi != 0x01234567 {
+		t.Errorf("mtimecmp must be 0x0123456789ffcdef, but was 0x%08x%08x", hi, lo)
+	}
+
+	c.Write(clintMtime+4, 4, 1)
+	c.Write(clintMtime, 4, 2)
+	c.Tick()
+	lo, _ = c.Read(clintMtime, 4)
+	hi, _ = c.Read(clintMtime+4, 4)
+	if lo != 3 || hi != 1 {
+		t.Errorf("mtime must be 0x0000000100000003, but was 0x%08x%08x", hi, lo)
+	}
+
+	// msip
+	c.Write(clintMsip, 4, 0xffffffff)
+	if v, _ := c.Read(clintMsip, 4); v != 1 || cpu.Csr.Mip&MipMSIP == 0 {
+		t.Errorf("msip must be 1 and set mip.MSIP, but was %d, mip:0x%x", v, cpu.Csr.Mip)
+	}
+	c.Write(clintMsip, 4, 0)
+	if cpu.Csr.Mip&MipMSIP != 0 {
+		t.Error("mip.MSIP must be cleared")
+	}
+
+	// mtip
+	c.SetMtime(100)
+	c.Write(clintMtimecmp+4, 4, 0)
+	c.Write(clintMtimecmp, 4, 101)
+	if cpu.Csr.Mip&MipMTIP != 0 {
+		t.Error("mip.MTIP must not be set before mtimecmp")
+	}
+	c.Tick()
+	if cpu.Csr.Mip&MipMTIP == 0 {
+		t.Error("mip.MTIP must be set when mtime reaches mtimecmp")
+	}
+}
+
+func Test_TimerInterrupt(t *testing.T) {
+	type TestData struct {
+		Mtvec   uint32
+		Handler uint32
+	}
+
+	for _, td := range []TestData{
+		{0x100, 0x100},
+		// vectored
+		{0x101, 0x100 + 4*7},
+	} {
+		e := NewEmulator()
+		// 0: j 0
+		loadCode(e, 0, GenCode(OpJal, 0, 0, 0))
+		loadCode(e, td.Handler, GenCode(OpAddi, 0, 0, 0))
+		e.Cpu.Csr.Mtvec = td.Mtvec
+		e.Cpu.Csr.Mie = MipMTIP
+		e.Cpu.Csr.Mstatus |= MstatusMIE
+		e.WriteU32(CLINTBase+clintMtimecmp+4, 0)
+		e.WriteU32(CLINTBase+clintMtimecmp, 10)
+
+		for i := 0; i < 20 && e.Cpu.Csr.Mcause == 0; i++ {
+			e.Step()
+		}
+		f := &e.Cpu.Csr
+		if f.Mcause != uint32(CauseMachineTimerInterrupt) || f.Mepc != 0 {
+			t.Errorf("timer interrupt must be taken, but mcause:0x%08x, mepc:0x%08x", f.Mcause, f.Mepc)
+		}
+		// the handler was executed by the Step which took the interrupt
+		if e.Cpu.PC != td.Handler+4 {
+			t.Errorf("PC must be 0x%08x, but was 0x%08x", td.Handler+4, e.Cpu.PC)
+		}
+		if f.Mstatus&MstatusMIE != 0 || f.Mstatus&MstatusMPIE == 0 {
+			t.Errorf("wrong mstatus 0x%08x", f.Mstatus)
+		}
+		if e.Clint.Mtime() != 10 {
+			t.Errorf("mtime must be 10, but was %d", e.Clint.Mtime())
+		}
+	}
+
+	// not taken while mstatus.MIE is clear
+	e := NewEmulator()
+	loadCode(e, 0, GenCode(OpJal, 0, 0, 0))
+	e.Cpu.Csr.Mtvec = 0x100
+	e.Cpu.Csr.Mie = MipMTIP
+	e.WriteU32(CLINTBase+clintMtimecmp+4, 0)
+	e.WriteU32(CLINTBase+clintMtimecmp, 0)
+	e.Step()
+	if e.Cpu.Csr.Mip&MipMTIP == 0 || e.Cpu.PC != 0 {
+		t.Errorf("interrupt must be pending but not taken. mip:0x%x, PC:0x%08x", e.Cpu.Csr.Mip, e.Cpu.PC)
+	}
+}
+
+func Test_TimeCsr(t *testing.T) {
+	e := NewEmulator()
+	// csrr a0, time
+	loadCode(e, 0, GenCode(OpAddi, 0, 0, 0), GenCode(OpCsrrs, 10, int(CsrTime), 0))
+	e.Clint.SetMtime(1000)
+	e.Step()
+	e.Step()
+	if e.Cpu.X[10] != 1002 {
+		t.Errorf("time must be %d, but was %d", 1002, e.Cpu.X[10])
+	}
+
+	e.SetTimeMode(TimeWallClock)
+	before := e.Clint.Mtime()
+	time.Sleep(2 * time.Millisecond)
+	if d := e.Clint.Mtime() - before; d < 2*TimebaseFreq/1000 {
+		t.Errorf("mtime must advance %d in 2ms, but was %d", 2*TimebaseFreq/1000, d)
+	}
+}
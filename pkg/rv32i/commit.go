@@ -0,0 +1,162 @@
+package rv32i
+
+import "sort"
+
+// RegFile is the register file of a RegWrite
+type RegFile uint8
+
+const (
+	RegX   RegFile = iota // integer registers
+	RegF                  // floating point registers
+	RegCsr                // CSRs, Num is the address
+)
+
+// RegWrite is a register written by an instruction
+type RegWrite struct {
+	File  RegFile
+	Num   uint32
+	Value uint64 // NaN-boxed for single precision values of RegF
+}
+
+// MemAccess is a data access of an instruction. Addr is the virtual address.
+type MemAccess struct {
+	Addr  uint32
+	Size  uint32
+	Value uint32
+	Write bool
+}
+
+// Commit is the record of an instruction which retired without a trap.
+// Regs and Mem are in the order the instruction wrote and accessed them.
+type Commit struct {
+	PC    uint32
+	Instr uint32 // raw instruction, in the lower half if compressed
+	Priv  uint32 // privilege mode the instruction ran in
+	Regs  []RegWrite
+	Mem   []MemAccess
+}
+
+// Len returns the length of the instruction in bytes
+func (c *Commit) Len() uint32 {
+	if IsCompressed(c.Instr) {
+		return 2
+	}
+	return 4
+}
+
+// Disassembly returns the instruction in assembly
+func (c *Commit) Disassembly() string {
+	return Decode(c.Instr).GetCodeString()
+}
+
+// spikeOrder sorts the register writes in the order spike logs them,
+// which is by (number << 4 | file) with 4 for CSRs
+func (c *Commit) spikeOrder() []RegWrite {
+	key := func(r RegWrite) uint32 {
+		if r.File == RegCsr {
+			return r.Num<<4 | 4
+		}
+		return r.Num<<4 | uint32(r.File)
+	}
+	regs := append([]RegWrite(nil), c.Regs...)
+	sort.SliceStable(regs, func(i, j int) bool { return key(regs[i]) < key(regs[j]) })
+	return regs
+}
+
+// Tracer receives the Commit of every instruction retired while it's set to
+// Emulator.Tracer. The Commit is reused for the next instruction, so Trace
+// must copy what it keeps. An error of Trace stops the Step with it.
+type Tracer interface {
+	Trace(c *Commit) error
+}
+
+// TracerFunc is a function as a Tracer
+type TracerFunc func(c *Commit) error
+
+func (f TracerFunc) Trace(c *Commit) error {
+	return f(c)
+}
+
+// beginCommit starts the Commit of the instruction at PC if Emulator.Tracer
+// is set
+func (c *Cpu) beginCommit(instr uint32) {
+	c.tracing = c.Emu.Tracer != nil
+	if !c.tracing {
+		return
+	}
+	c.commit.PC = c.PC
+	c.commit.Instr = instr
+	c.commit.Priv = c.Priv
+	c.commit.Regs = c.commit.Regs[:0]
+	c.commit.Mem = c.commit.Mem[:0]
+}
+
+// endCommit adds x[rd] to the Commit and passes it to the Tracer. x[rd] is
+// logged even if the value doesn't change, like spike does.
+func (c *Cpu) endCommit(i *Instruction) error {
+	if !c.tracing {
+		return nil
+	}
+	c.tracing = false
+	if i.Rd != 0 && writesX(i.GetOpName()) {
+		c.commit.Regs = append(c.commit.Regs, RegWrite{File: RegX, Num: uint32(i.Rd), Value: uint64(c.X[i.Rd])})
+	}
+	return c.Emu.Tracer.Trace(&c.commit)
+}
+
+// traceF logs a write of f[r]
+func (c *Cpu) traceF(r uint8) {
+	if c.tracing {
+		c.commit.Regs = append(c.commit.Regs, RegWrite{File: RegF, Num: uint32(r), Value: c.F[r]})
+	}
+}
+
+// traceCsr logs a write of the CSR
+func (c *Cpu) traceCsr(addr uint32) {
+	if !c.tracing {
+		return
+	}
+	var v uint32
+	switch addr {
+	// read directly as they are not readable while mstatus.FS is off
+	case CsrFflags:
+		v = c.Csr.Fcsr & 0b11111
+	default:
+		v, _ = c.ReadCsr(addr)
+	}
+	c.commit.Regs = append(c.commit.Regs, RegWrite{File: RegCsr, Num: addr, Value: uint64(v)})
+}
+
+// accessed is called after the guest accessed memory at the virtual address
+func (c *Cpu) accessed(addr uint32, size uint32, data uint32, write bool) {
+	c.Emu.accessed(addr, size, write)
+	if c.tracing {
+		if size < 4 {
+			data &= 1<<(8*size) - 1
+		}
+		c.commit.Mem = append(c.commit.Mem, MemAccess{Addr: addr, Size: size, Value: data, Write: write})
+	}
+}
+
+// writesX returns true if the instruction writes x[rd]
+func writesX(op OpName) bool {
+	switch {
+	case op == OpLui, op == OpAuipc, op == OpJal, op == OpJalr:
+		return true
+	case op >= OpLb && op <= OpLhu:
+		return true
+	case op >= OpAddi && op <= OpAnd:
+		return true
+	case op >= OpCsrrw && op <= OpCsrrci:
+		return true
+	// M and A
+	case op >= OpMul && op <= OpAmomaxuW:
+		return true
+	}
+	switch op {
+	case OpFcvtWS, OpFcvtWuS, OpFmvXW, OpFeqS, OpFltS, OpFleS, OpFclassS,
+		OpFeqD, OpFltD, OpFleD, OpFclassD, OpFcvtWD, OpFcvtWuD:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+package rv32i
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CommitReader reads commits of a commit log
+type CommitReader interface {
+	// Next returns the next commit, or io.EOF at the end. The Commit may be
+	// reused by the next call.
+	Next() (*Commit, error)
+}
+
+// SpikeCommitWriter is a Tracer which writes the commits in the format of
+// spike --log-commits, e.g.
+//
+//	core   0: 3 0x80000100 (0x00200193) x3  0x00000002
+//	core   0: 3 0x80000104 (0x00e7a023) mem 0x80002000 0x00000005
+type SpikeCommitWriter struct {
+	w io.Writer
+	// Disassemble writes the line of spike -l before each commit
+	Disassemble bool
+	// FLEN is the width of the f registers, 64 by default as misa has D
+	FLEN int
+}
+
+func NewSpikeCommitWriter(w io.Writer) *SpikeCommitWriter {
+	return &SpikeCommitWriter{w: w, FLEN: 64}
+}
+
+func (s *SpikeCommitWriter) Trace(c *Commit) error {
+	instr := fmt.Sprintf("0x%08x", c.Instr)
+	if c.Len() == 2 {
+		instr = fmt.Sprintf("0x%04x", c.Instr&0xffff)
+	}
+	if s.Disassemble {
+		if _, err := fmt.Fprintf(s.w, "core   0: 0x%08x (%s) %s\n", c.PC, instr, c.Disassembly()); err != nil {
+			return err
+		}
+	}
+
+	line := fmt.Sprintf("core   0: %d 0x%08x (%s)", c.Priv, c.PC, instr)
+	for _, r := range c.spikeOrder() {
+		switch r.File {
+		case RegX:
+			line += fmt.Sprintf(" x%-2d 0x%08x", r.Num, r.Value)
+		case RegF:
+			if s.FLEN == 32 {
+				line += fmt.Sprintf(" f%-2d 0x%08x", r.Num, uint32(r.Value))
+			} else {
+				line += fmt.Sprintf(" f%-2d 0x%016x", r.Num, r.Value)
+			}
+		case RegCsr:
+			line += fmt.Sprintf(" c%d_%s 0x%08x", r.Num, CsrName(r.Num), r.Value)
+		}
+	}
+	// loads first, as spike does for AMOs
+	for _, m := range c.Mem {
+		if !m.Write {
+			line += fmt.Sprintf(" mem 0x%08x", m.Addr)
+		}
+	}
+	for _, m := range c.Mem {
+		if m.Write {
+			line += fmt.Sprintf(" mem 0x%08x 0x%0*x", m.Addr, m.Size*2, m.Value)
+		}
+	}
+	_, err := fmt.Fprintln(s.w, line)
+	return err
+}
+
+// binaryCommitMagic starts a binary commit log
+const binaryCommitMagic = "RV32CL\x00\x01"
+
+// BinaryCommitWriter is a Tracer which writes the commits in a compact
+// binary format after binaryCommitMagic. A commit is, in little endian,
+//
+//	u8  priv | compressed << 2
+//	u32 pc
+//	u16 or u32 instruction
+//	u8  number of register writes, and for each
+//	    u8 file, u16 number, and u32 value, or u64 for f registers
+//	u8  number of memory accesses, and for each
+//	    u8 size | write << 7, u32 address, and the value in size bytes
+type BinaryCommitWriter struct {
+	w      io.Writer
+	header bool
+	buf    []byte
+}
+
+func NewBinaryCommitWriter(w io.Writer) *BinaryCommitWriter {
+	return &BinaryCommitWriter{w: w}
+}
+
+func (b *BinaryCommitWriter) Trace(c *Commit) error {
+	if len(c.Regs) > 0xff || len(c.Mem) > 0xff {
+		return fmt.Errorf("too many writes in the commit at 0x%08x", c.PC)
+	}
+	buf := b.buf[:0]
+	if !b.header {
+		buf = append(buf, binaryCommitMagic...)
+		b.header = true
+	}
+
+	compressed := c.Len() == 2
+	flags := uint8(c.Priv & 0b11)
+	if compressed {
+		flags |= 1 << 2
+	}
+	buf = append(buf, flags)
+	buf = binary.LittleEndian.AppendUint32(buf, c.PC)
+	if compressed {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(c.Instr))
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, c.Instr)
+	}
+
+	buf = append(buf, uint8(len(c.Regs)))
+	for _, r := range c.Regs {
+		buf = append(buf, uint8(r.File))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(r.Num))
+		if r.File == RegF {
+			buf = binary.LittleEndian.AppendUint64(buf, r.Value)
+		} else {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(r.Value))
+		}
+	}
+
+	buf = append(buf, uint8(len(c.Mem)))
+	for _, m := range c.Mem {
+		size := uint8(m.Size)
+		if m.Write {
+			size |= 1 << 7
+		}
+		buf = append(buf, size)
+		buf = binary.LittleEndian.AppendUint32(buf, m.Addr)
+		for i := uint32(0); i < m.Size; i++ {
+			buf = append(buf, uint8(m.Value>>(8*i)))
+		}
+	}
+
+	b.buf = buf
+	_, err := b.w.Write(buf)
+	return err
+}
+
+// BinaryCommitReader reads the commits of BinaryCommitWriter
+type BinaryCommitReader struct {
+	r      *bufio.Reader
+	header bool
+	commit Commit
+}
+
+func NewBinaryCommitReader(r io.Reader) *BinaryCommitReader {
+	return &BinaryCommitReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next commit, or io.EOF at the end. The Commit is reused
+// by the next call.
+func (b *BinaryCommitReader) Next() (*Commit, error) {
+	if !b.header {
+		magic := make([]byte, len(binaryCommitMagic))
+		if _, err := io.ReadFull(b.r, magic); err != nil {
+			return nil, err
+		}
+		if string(magic) != binaryCommitMagic {
+			return nil, fmt.Errorf("not a binary commit log")
+		}
+		b.header = true
+	}
+
+	flags, err := b.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	c := &b.commit
+	c.Priv = uint32(flags & 0b11)
+	c.Regs = c.Regs[:0]
+	c.Mem = c.Mem[:0]
+	if c.PC, err = b.readUint(4); err != nil {
+		return nil, err
+	}
+	instrSize := 4
+	if flags&(1<<2) != 0 {
+		instrSize = 2
+	}
+	if c.Instr, err = b.readUint(instrSize); err != nil {
+		return nil, err
+	}
+
+	n, err := b.r.ReadByte()
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	for i := 0; i < int(n); i++ {
+		var r RegWrite
+		file, err := b.r.ReadByte()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		r.File = RegFile(file)
+		if r.Num, err = b.readUint(2); err != nil {
+			return nil, err
+		}
+		if r.File == RegF {
+			lo, err := b.readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			hi, err := b.readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			r.Value = uint64(hi)<<32 | uint64(lo)
+		} else {
+			v, err := b.readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			r.Value = uint64(v)
+		}
+		c.Regs = append(c.Regs, r)
+	}
+
+	if n, err = b.r.ReadByte(); err != nil {
+		return nil, unexpected(err)
+	}
+	for i := 0; i < int(n); i++ {
+		var m MemAccess
+		size, err := b.r.ReadByte()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		m.Write = size&(1<<7) != 0
+		m.Size = uint32(size &^ (1 << 7))
+		if m.Addr, err = b.readUint(4); err != nil {
+			return nil, err
+		}
+		if m.Value, err = b.readUint(int(m.Size)); err != nil {
+			return nil, err
+		}
+		c.Mem = append(c.Mem, m)
+	}
+	return c, nil
+}
+
+// readUint reads a little endian value of up to 4 bytes in a commit
+func (b *BinaryCommitReader) readUint(size int) (uint32, error) {
+	if size > 4 {
+		return 0, fmt.Errorf("invalid size %d", size)
+	}
+	var buf [4]byte
+	if _, err := io.ReadFull(b.r, buf[:size]); err != nil {
+		return 0, unexpected(err)
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+// unexpected turns io.EOF in the middle of a commit into io.ErrUnexpectedEOF
+func unexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// IsBinaryCommitLog returns true if data starts with the header of
+// BinaryCommitWriter
+func IsBinaryCommitLog(data []byte) bool {
+	return strings.HasPrefix(string(data), binaryCommitMagic)
+}
+
+// SpikeCommitReader reads the commits of spike --log-commits, and of RTL
+// simulators which write the same format. Lines other than commits, like the
+// ones of spike -l, are skipped. The size of loads is 0 as spike doesn't log
+// it.
+type SpikeCommitReader struct {
+	sc     *bufio.Scanner
+	line   int
+	commit Commit
+}
+
+func NewSpikeCommitReader(r io.Reader) *SpikeCommitReader {
+	return &SpikeCommitReader{sc: bufio.NewScanner(r)}
+}
+
+func (s *SpikeCommitReader) Next() (*Commit, error) {
+	for s.sc.Scan() {
+		s.line++
+		ok, err := s.parse(s.sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", s.line, err)
+		}
+		if ok {
+			return &s.commit, nil
+		}
+	}
+	if err := s.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parse parses a line, and returns false if it's not a commit
+func (s *SpikeCommitReader) parse(line string) (bool, error) {
+	// core   0: 3 0x80000000 (0x0380006f) x1  0x00000000 mem 0x80001000
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "core" || !strings.HasSuffix(fields[1], ":") {
+		return false, nil
+	}
+	priv, err := strconv.ParseUint(fields[2], 10, 2)
+	if err != nil {
+		// the disassembly of spike -l and exceptions
+		return false, nil
+	}
+	c := &s.commit
+	c.Priv = uint32(priv)
+	c.Regs = c.Regs[:0]
+	c.Mem = c.Mem[:0]
+	if c.PC, err = parseHexValue(fields[3]); err != nil {
+		return false, err
+	}
+	if len(fields) < 5 || !strings.HasPrefix(fields[4], "(") || !strings.HasSuffix(fields[4], ")") {
+		return false, fmt.Errorf("no instruction in %q", line)
+	}
+	if c.Instr, err = parseHexValue(fields[4][1 : len(fields[4])-1]); err != nil {
+		return false, err
+	}
+
+	rest := fields[5:]
+	for len(rest) > 0 {
+		name := rest[0]
+		if name == "mem" {
+			if len(rest) < 2 {
+				return false, fmt.Errorf("no address of mem in %q", line)
+			}
+			m := MemAccess{}
+			if m.Addr, err = parseHexValue(rest[1]); err != nil {
+				return false, err
+			}
+			rest = rest[2:]
+			// a store has the value, whose width is the size
+			if len(rest) > 0 && strings.HasPrefix(rest[0], "0x") {
+				m.Write = true
+				m.Size = uint32(len(rest[0])-2) / 2
+				if m.Value, err = parseHexValue(rest[0]); err != nil {
+					return false, err
+				}
+				rest = rest[1:]
+			}
+			c.Mem = append(c.Mem, m)
+			continue
+		}
+
+		if len(rest) < 2 {
+			return false, fmt.Errorf("no value of %s in %q", name, line)
+		}
+		r := RegWrite{}
+		num := name[1:]
+		switch name[0] {
+		case 'x':
+			r.File = RegX
+		case 'f':
+			r.File = RegF
+		case 'c':
+			// c768_mstatus
+			r.File = RegCsr
+			num, _, _ = strings.Cut(num, "_")
+		default:
+			return false, fmt.Errorf("unknown register %s in %q", name, line)
+		}
+		n, err := strconv.ParseUint(num, 10, 12)
+		if err != nil {
+			return false, fmt.Errorf("invalid register %s in %q", name, line)
+		}
+		r.Num = uint32(n)
+		v := rest[1]
+		if !strings.HasPrefix(v, "0x") {
+			return false, fmt.Errorf("invalid value %s in %q", v, line)
+		}
+		if r.Value, err = strconv.ParseUint(v[2:], 16, 64); err != nil {
+			return false, err
+		}
+		c.Regs = append(c.Regs, r)
+		rest = rest[2:]
+	}
+	return true, nil
+}
+
+// parseHexValue parses 0x and up to 8 hex digits
+func parseHexValue(s string) (uint32, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("%s is not a hex value", s)
+	}
+	v, err := strconv.ParseUint(s[2:], 16, 32)
+	return uint32(v), err
+}
@@ -0,0 +1,173 @@
+package rv32i
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newCommitEmulator has a load, a store, a CSR write and a compressed
+// instruction
+func newCommitEmulator() (*Emulator, []uint32) {
+	t0, t1, t2 := Regs["t0"], Regs["t1"], Regs["t2"]
+	codes := []uint32{
+		GenCode(OpAddi, t0, 0, 5),
+		GenCode(OpSw, t0, 0x100, 0),
+		GenCode(OpLb, t1, 0x100, 0),
+		GenCode(OpCsrrw, t2, int(CsrMscratch), t0),
+	}
+	e := NewEmulator()
+	loadCode(e, 0, codes...)
+	// c.li a0, 1 and sb t0, 0x101(zero)
+	sb := GenCode(OpSb, t0, 0x101, 0)
+	e.WriteU16(0x10, 0x4505)
+	e.WriteU32(0x12, sb)
+	return e, append(codes, 0x4505, sb)
+}
+
+func Test_SpikeCommitWriter(t *testing.T) {
+	e, codes := newCommitEmulator()
+	out := &bytes.Buffer{}
+	w := NewSpikeCommitWriter(out)
+	w.Disassemble = true
+	e.Tracer = w
+
+	r := e.RunWithOptions(context.Background(), RunOptions{MaxInstructions: 6})
+	if r.Kind != StopMaxInstructions {
+		t.Fatalf("run must stop after 6 instructions, but was %v", r)
+	}
+	want := fmt.Sprintf(`core   0: 0x00000000 (0x%08x) Addi t0, 5(zero)
+core   0: 3 0x00000000 (0x%08x) x5  0x00000005
+core   0: 0x00000004 (0x%08x) Sw t0, 256(zero)
+core   0: 3 0x00000004 (0x%08x) mem 0x00000100 0x00000005
+core   0: 0x00000008 (0x%08x) Lb t1, 256(zero)
+core   0: 3 0x00000008 (0x%08x) x6  0x00000005 mem 0x00000100
+core   0: 0x0000000c (0x%08x) Csrrw t2, mscratch, t0
+core   0: 3 0x0000000c (0x%08x) x7  0x00000000 c832_mscratch 0x00000005
+core   0: 0x00000010 (0x4505) c.li a0, 1
+core   0: 3 0x00000010 (0x4505) x10 0x00000001
+core   0: 0x00000012 (0x%08x) Sb t0, 257(zero)
+core   0: 3 0x00000012 (0x%08x) mem 0x00000101 0x05
+`, codes[0], codes[0], codes[1], codes[1], codes[2], codes[2], codes[3], codes[3], codes[5], codes[5])
+	if s := out.String(); s != want {
+		t.Errorf("commit log must be\n%s\nbut was\n%s", want, s)
+	}
+}
+
+func Test_BinaryCommitWriter(t *testing.T) {
+	e, _ := newCommitEmulator()
+	var commits []Commit
+	out := &bytes.Buffer{}
+	w := NewBinaryCommitWriter(out)
+	e.Tracer = TracerFunc(func(c *Commit) error {
+		copied := *c
+		copied.Regs = append([]RegWrite(nil), c.Regs...)
+		copied.Mem = append([]MemAccess(nil), c.Mem...)
+		commits = append(commits, copied)
+		return w.Trace(c)
+	})
+	// a single precision value is NaN-boxed
+	e.Cpu.F[1] = 0xffffffff_3f800000
+	loadCode(e, 0x16, GenCode(OpFaddS, 2, 1, 1))
+	e.RunWithOptions(context.Background(), RunOptions{MaxInstructions: 7})
+
+	r := NewBinaryCommitReader(bytes.NewReader(out.Bytes()))
+	for i := 0; ; i++ {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(commits) {
+				t.Errorf("%d commits must be read, but were %d", len(commits), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(commits) {
+			t.Fatalf("commit #%d must not exist", i)
+		}
+		// empty and nil slices are the same
+		if fmt.Sprintf("%+v", *c) != fmt.Sprintf("%+v", commits[i]) {
+			t.Errorf("commit #%d must be %+v, but was %+v", i, commits[i], *c)
+		}
+	}
+	if regs := commits[6].Regs; len(regs) == 0 || regs[0] != (RegWrite{File: RegF, Num: 2, Value: 0xffffffff_40000000}) {
+		t.Errorf("fadd.s must write f2, but was %+v", regs)
+	}
+
+	r = NewBinaryCommitReader(bytes.NewReader(out.Bytes()[:out.Len()-1]))
+	var err error
+	for err == nil {
+		_, err = r.Next()
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("a truncated log must be ErrUnexpectedEOF, but was %v", err)
+	}
+}
+
+func Test_TracerError(t *testing.T) {
+	e, _ := newCommitEmulator()
+	e.Tracer = TracerFunc(func(c *Commit) error {
+		return errors.New("full")
+	})
+	if err := e.Step(); err == nil || err.Error() != "full" {
+		t.Errorf("Step must return the error of the tracer, but was %v", err)
+	}
+}
+
+func Test_SpikeCommitReader(t *testing.T) {
+	e, _ := newCommitEmulator()
+	out := &bytes.Buffer{}
+	w := NewSpikeCommitWriter(out)
+	w.Disassemble = true
+	var commits []string
+	e.Tracer = TracerFunc(func(c *Commit) error {
+		commits = append(commits, fmt.Sprintf("%+v", *c))
+		return w.Trace(c)
+	})
+	e.RunWithOptions(context.Background(), RunOptions{MaxInstructions: 6})
+	out.WriteString("core   0: exception trap_breakpoint, epc 0x00000018\n")
+
+	r := NewSpikeCommitReader(bytes.NewReader(out.Bytes()))
+	for i := 0; ; i++ {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(commits) {
+				t.Errorf("%d commits must be read, but were %d", len(commits), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(commits) {
+			t.Fatalf("commit #%d must not exist", i)
+		}
+		// loads have no size, and registers are in the order of spike
+		want := commits[i]
+		switch i {
+		case 2:
+			want = "{PC:8 Instr:268436227 Priv:3 Regs:[{File:0 Num:6 Value:5}] Mem:[{Addr:256 Size:0 Value:0 Write:false}]}"
+		case 3:
+			want = "{PC:12 Instr:872584179 Priv:3 Regs:[{File:0 Num:7 Value:0} {File:2 Num:832 Value:5}] Mem:[]}"
+		}
+		if got := fmt.Sprintf("%+v", *c); got != want {
+			t.Errorf("commit #%d must be %s, but was %s", i, want, got)
+		}
+	}
+
+	for _, line := range []string{
+		"core   0: 3 0x00000000",
+		"core   0: 3 0x00000000 (0x00000013) x1",
+		"core   0: 3 0x00000000 (0x00000013) v1 0x0",
+		"core   0: 3 0x00000000 (0x00000023) mem",
+	} {
+		if _, err := NewSpikeCommitReader(strings.NewReader(line)).Next(); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("%q must be an error, but was %v", line, err)
+		}
+	}
+}
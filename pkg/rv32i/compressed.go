@@ -0,0 +1,248 @@
+package rv32i
+
+import (
+	"fmt"
+)
+
+// IsCompressed returns true if the lowest 2 bits of the instruction are not 0b11
+func IsCompressed(instr uint32) bool {
+	return instr&0b11 != 0b11
+}
+
+// Decode decodes a 32-bit instruction or a 16-bit compressed instruction
+// in the lower half of instr
+func Decode(instr uint32) *Instruction {
+	if IsCompressed(instr) {
+		return NewCompressedInstruction(uint16(instr))
+	}
+	return NewInstruction(instr)
+}
+
+// NewCompressedInstruction expands a RV32C instruction into the equivalent
+// 32-bit instruction. Illegal or reserved encodings become OpInvalid.
+func NewCompressedInstruction(c uint16) *Instruction {
+	code, _ := expandCompressed(c)
+	instance := NewInstruction(code)
+	instance.C = c
+	return instance
+}
+
+// ExpandCompressed returns the 32-bit instruction of a RV32C instruction.
+// It returns false for illegal or reserved encodings.
+func ExpandCompressed(c uint16) (uint32, bool) {
+	code, name := expandCompressed(c)
+	return code, name != ""
+}
+
+// compressed register number rd', rs1' and rs2' (x8-x15)
+func cReg(c uint16, lsb int) int {
+	return 8 + int(c>>lsb&0b111)
+}
+
+func cBits(c uint16, hi int, lo int) uint32 {
+	return uint32(c>>lo) & (1<<(hi-lo+1) - 1)
+}
+
+func encodeI(opcode uint32, funct3 uint32, rd int, rs1 int, imm uint32) uint32 {
+	return imm<<20 | uint32(rs1)<<15 | funct3<<12 | uint32(rd)<<7 | opcode
+}
+
+func encodeS(opcode uint32, funct3 uint32, rs2 int, rs1 int, imm uint32) uint32 {
+	return (imm>>5)<<25 | uint32(rs2)<<20 | uint32(rs1)<<15 | funct3<<12 | (imm&0b11111)<<7 | opcode
+}
+
+// expandCompressed returns the 32-bit instruction and the compressed
+// mnemonic. The mnemonic is empty if c is illegal.
+func expandCompressed(c uint16) (uint32, string) {
+	funct3 := cBits(c, 15, 13)
+	rd := int(cBits(c, 11, 7))
+	rs2 := int(cBits(c, 6, 2))
+	// CI format 6 bit signed immediate
+	imm6 := int(int32(SignExtension(cBits(c, 12, 12)<<5|cBits(c, 6, 2), 5)))
+
+	switch c & 0b11 {
+	case 0b00:
+		rdp := cReg(c, 2)
+		rs1p := cReg(c, 7)
+		// offsets of c.lw/c.sw and c.fld/c.fsd
+		offW := cBits(c, 12, 10)<<3 | cBits(c, 6, 6)<<2 | cBits(c, 5, 5)<<6
+		offD := cBits(c, 12, 10)<<3 | cBits(c, 6, 5)<<6
+		switch funct3 {
+		case 0b000:
+			nzuimm := cBits(c, 12, 11)<<4 | cBits(c, 10, 7)<<6 | cBits(c, 6, 6)<<2 | cBits(c, 5, 5)<<3
+			if nzuimm == 0 {
+				return 0, ""
+			}
+			return GenCode(OpAddi, rdp, 2, int(nzuimm)), "c.addi4spn"
+		case 0b001:
+			return encodeI(0b0000111, 0b011, rdp, rs1p, offD), "c.fld"
+		case 0b010:
+			return GenCode(OpLw, rdp, int(offW), rs1p), "c.lw"
+		case 0b011:
+			return encodeI(0b0000111, 0b010, rdp, rs1p, offW), "c.flw"
+		case 0b101:
+			return encodeS(0b0100111, 0b011, rdp, rs1p, offD), "c.fsd"
+		case 0b110:
+			return GenCode(OpSw, rdp, int(offW), rs1p), "c.sw"
+		case 0b111:
+			return encodeS(0b0100111, 0b010, rdp, rs1p, offW), "c.fsw"
+		default:
+			return 0, ""
+		}
+	case 0b01:
+		rdp := cReg(c, 7)
+		rs2p := cReg(c, 2)
+		// c.j/c.jal offset[11|4|9:8|10|6|7|3:1|5]
+		offJ := int(int32(SignExtension(cBits(c, 12, 12)<<11|cBits(c, 11, 11)<<4|cBits(c, 10, 9)<<8|cBits(c, 8, 8)<<10|
+			cBits(c, 7, 7)<<6|cBits(c, 6, 6)<<7|cBits(c, 5, 3)<<1|cBits(c, 2, 2)<<5, 11)))
+		// c.beqz/c.bnez offset[8|4:3] [7:6|2:1|5]
+		offB := int(int32(SignExtension(cBits(c, 12, 12)<<8|cBits(c, 11, 10)<<3|cBits(c, 6, 5)<<6|cBits(c, 4, 3)<<1|cBits(c, 2, 2)<<5, 8)))
+		switch funct3 {
+		case 0b000:
+			if rd == 0 {
+				return GenCode(OpAddi, 0, 0, 0), "c.nop"
+			}
+			return GenCode(OpAddi, rd, rd, imm6), "c.addi"
+		case 0b001:
+			return GenCode(OpJal, 1, offJ, 0), "c.jal"
+		case 0b010:
+			return GenCode(OpAddi, rd, 0, imm6), "c.li"
+		case 0b011:
+			if rd == 2 {
+				nzimm := int(int32(SignExtension(cBits(c, 12, 12)<<9|cBits(c, 6, 6)<<4|cBits(c, 5, 5)<<6|cBits(c, 4, 3)<<7|cBits(c, 2, 2)<<5, 9)))
+				if nzimm == 0 {
+					return 0, ""
+				}
+				return GenCode(OpAddi, 2, 2, nzimm), "c.addi16sp"
+			}
+			if imm6 == 0 {
+				return 0, ""
+			}
+			return GenCode(OpLui, rd, imm6&0xfffff, 0), "c.lui"
+		case 0b100:
+			shamt := int(cBits(c, 6, 2))
+			switch cBits(c, 11, 10) {
+			case 0b00:
+				// shamt[5] must be 0 in RV32
+				if cBits(c, 12, 12) != 0 {
+					return 0, ""
+				}
+				return GenCode(OpSrli, rdp, rdp, shamt), "c.srli"
+			case 0b01:
+				if cBits(c, 12, 12) != 0 {
+					return 0, ""
+				}
+				return GenCode(OpSrai, rdp, rdp, shamt), "c.srai"
+			case 0b10:
+				return GenCode(OpAndi, rdp, rdp, imm6), "c.andi"
+			default:
+				// c.subw and c.addw are RV64 only
+				if cBits(c, 12, 12) != 0 {
+					return 0, ""
+				}
+				switch cBits(c, 6, 5) {
+				case 0b00:
+					return GenCode(OpSub, rdp, rdp, rs2p), "c.sub"
+				case 0b01:
+					return GenCode(OpXor, rdp, rdp, rs2p), "c.xor"
+				case 0b10:
+					return GenCode(OpOr, rdp, rdp, rs2p), "c.or"
+				default:
+					return GenCode(OpAnd, rdp, rdp, rs2p), "c.and"
+				}
+			}
+		case 0b101:
+			return GenCode(OpJal, 0, offJ, 0), "c.j"
+		case 0b110:
+			return GenCode(OpBeq, rdp, 0, offB), "c.beqz"
+		default:
+			return GenCode(OpBne, rdp, 0, offB), "c.bnez"
+		}
+	case 0b10:
+		// offsets of c.lwsp/c.swsp and c.fldsp/c.fsdsp
+		offLW := cBits(c, 12, 12)<<5 | cBits(c, 6, 4)<<2 | cBits(c, 3, 2)<<6
+		offLD := cBits(c, 12, 12)<<5 | cBits(c, 6, 5)<<3 | cBits(c, 4, 2)<<6
+		offSW := cBits(c, 12, 9)<<2 | cBits(c, 8, 7)<<6
+		offSD := cBits(c, 12, 10)<<3 | cBits(c, 9, 7)<<6
+		switch funct3 {
+		case 0b000:
+			if cBits(c, 12, 12) != 0 {
+				return 0, ""
+			}
+			return GenCode(OpSlli, rd, rd, rs2), "c.slli"
+		case 0b001:
+			return encodeI(0b0000111, 0b011, rd, 2, offLD), "c.fldsp"
+		case 0b010:
+			if rd == 0 {
+				return 0, ""
+			}
+			return GenCode(OpLw, rd, int(offLW), 2), "c.lwsp"
+		case 0b011:
+			return encodeI(0b0000111, 0b010, rd, 2, offLW), "c.flwsp"
+		case 0b100:
+			if cBits(c, 12, 12) == 0 {
+				if rs2 != 0 {
+					return GenCode(OpAdd, rd, 0, rs2), "c.mv"
+				}
+				if rd == 0 {
+					return 0, ""
+				}
+				return GenCode(OpJalr, 0, 0, rd), "c.jr"
+			}
+			if rs2 != 0 {
+				return GenCode(OpAdd, rd, rd, rs2), "c.add"
+			}
+			if rd == 0 {
+				return GenCode(OpEbreak, 0, 0, 0), "c.ebreak"
+			}
+			return GenCode(OpJalr, 1, 0, rd), "c.jalr"
+		case 0b101:
+			return encodeS(0b0100111, 0b011, rs2, 2, offSD), "c.fsdsp"
+		case 0b110:
+			return GenCode(OpSw, rs2, int(offSW), 2), "c.swsp"
+		default:
+			return encodeS(0b0100111, 0b010, rs2, 2, offSW), "c.fswsp"
+		}
+	default:
+		return 0, ""
+	}
+}
+
+// getCompressedCodeString disassembles the compressed instruction with
+// the operands of the compressed form
+func (i *Instruction) getCompressedCodeString() string {
+	_, name := expandCompressed(i.C)
+	imm := InterpretSingnedUint32(i.Imm)
+
+	switch name {
+	case "":
+		return fmt.Sprintf("Invalid compressed instruction 0x%04x", i.C)
+	case "c.nop", "c.ebreak":
+		return name
+	case "c.addi4spn":
+		return fmt.Sprintf("%s %s, %s, %d", name, RegName(i.Rd), RegName(i.Rs1), imm)
+	case "c.lw", "c.lwsp":
+		return fmt.Sprintf("%s %s, %d(%s)", name, RegName(i.Rd), imm, RegName(i.Rs1))
+	case "c.flw", "c.flwsp", "c.fld", "c.fldsp":
+		return fmt.Sprintf("%s %s, %d(%s)", name, FRegName(i.Rd), imm, RegName(i.Rs1))
+	case "c.sw", "c.swsp":
+		return fmt.Sprintf("%s %s, %d(%s)", name, RegName(i.Rs2), imm, RegName(i.Rs1))
+	case "c.fsw", "c.fswsp", "c.fsd", "c.fsdsp":
+		return fmt.Sprintf("%s %s, %d(%s)", name, FRegName(i.Rs2), imm, RegName(i.Rs1))
+	case "c.addi", "c.li", "c.andi", "c.addi16sp":
+		return fmt.Sprintf("%s %s, %d", name, RegName(i.Rd), imm)
+	case "c.lui":
+		return fmt.Sprintf("%s %s, %d", name, RegName(i.Rd), i.Imm>>12)
+	case "c.slli", "c.srli", "c.srai":
+		return fmt.Sprintf("%s %s, %d", name, RegName(i.Rd), i.Rs2)
+	case "c.sub", "c.xor", "c.or", "c.and", "c.mv", "c.add":
+		return fmt.Sprintf("%s %s, %s", name, RegName(i.Rd), RegName(i.Rs2))
+	case "c.jr", "c.jalr":
+		return fmt.Sprintf("%s %s", name, RegName(i.Rs1))
+	case "c.j", "c.jal":
+		return fmt.Sprintf("%s PC+0x%x", name, imm)
+	default:
+		// c.beqz, c.bnez
+		return fmt.Sprintf("%s %s, %d", name, RegName(i.Rs1), imm)
+	}
+}
@@ -0,0 +1,132 @@
+package rv32i
+
+import "testing"
+
+func Test_ExpandCompressed(t *testing.T) {
+	type TestData struct {
+		C    uint16
+		Want uint32
+	}
+
+	// made by llvm-mc -triple=riscv32 -mattr=+c,+f,+d -show-encoding -riscv-no-aliases
+	for _, td := range []TestData{
+		{0x0808, 0x01010513}, // c.addi4spn a0, sp, 16
+		{0x424c, 0x00462583}, // c.lw a1, 4(a2)
+		{0xdcf4, 0x06d4ae23}, // c.sw a3, 124(s1)
+		{0x6508, 0x00852507}, // c.flw fa0, 8(a0)
+		{0x3dec, 0x0f85b587}, // c.fld fa1, 248(a1)
+		{0xe028, 0x04a42027}, // c.fsw fa0, 64(s0)
+		{0xa790, 0x00c7b427}, // c.fsd fa2, 8(a5)
+		{0x0001, 0x00000013}, // c.nop
+		{0x1575, 0xffd50513}, // c.addi a0, -3
+		{0x3fed, 0xffbff0ef}, // c.jal -6
+		{0x42fd, 0x01f00293}, // c.li t0, 31
+		{0x7139, 0xfc010113}, // c.addi16sp sp, -64
+		{0x77fd, 0xfffff7b7}, // c.lui a5, 0xfffff
+		{0x678d, 0x000037b7}, // c.lui a5, 3
+		{0x817d, 0x01f55513}, // c.srli a0, 31
+		{0x8585, 0x4015d593}, // c.srai a1, 1
+		{0x9a01, 0xfe067613}, // c.andi a2, -32
+		{0x8d0d, 0x40b50533}, // c.sub a0, a1
+		{0x8c25, 0x00944433}, // c.xor s0, s1
+		{0x8f5d, 0x00f76733}, // c.or a4, a5
+		{0x8e75, 0x00d67633}, // c.and a2, a3
+		{0xaffd, 0x7fe0006f}, // c.j 2046
+		{0xd101, 0xf00500e3}, // c.beqz a0, -256
+		{0xecfd, 0x0e049f63}, // c.bnez s1, 254
+		{0x0346, 0x01131313}, // c.slli t1, 17
+		{0x357e, 0x1f813507}, // c.fldsp fa0, 504(sp)
+		{0x50fe, 0x0fc12083}, // c.lwsp ra, 252(sp)
+		{0x6432, 0x00c12407}, // c.flwsp fs0, 12(sp)
+		{0x8082, 0x00008067}, // c.jr ra
+		{0x851e, 0x00700533}, // c.mv a0, t2
+		{0x9002, 0x00100073}, // c.ebreak
+		{0x9282, 0x000280e7}, // c.jalr t0
+		{0x912a, 0x00a10133}, // c.add sp, a0
+		{0xa42e, 0x00b13427}, // c.fsdsp fa1, 8(sp)
+		{0xdf86, 0x0e112e23}, // c.swsp ra, 252(sp)
+		{0xe226, 0x00912227}, // c.fswsp fs1, 4(sp)
+	} {
+		got, ok := ExpandCompressed(td.C)
+		if !ok || got != td.Want {
+			t.Errorf("ExpandCompressed(0x%04x) got:0x%08x, %v, want:0x%08x", td.C, got, ok, td.Want)
+		}
+	}
+
+	// illegal or reserved
+	for _, c := range []uint16{
+		0x0000, // all zero
+		0x8000, // reserved in quadrant 0
+		0x6101, // c.addi16sp with 0
+		0x6181, // c.lui with 0
+		0x9001, // c.srli with shamt[5]
+		0x9c01, // c.subw
+		0x4002, // c.lwsp with rd == x0
+		0x8002, // c.jr with rs1 == x0
+	} {
+		if _, ok := ExpandCompressed(c); ok {
+			t.Errorf("0x%04x must be illegal", c)
+		}
+		if op := NewCompressedInstruction(c).GetOpName(); op != OpInvalid {
+			t.Errorf("0x%04x must be OpInvalid, but was %v", c, op)
+		}
+	}
+}
+
+func Test_CompressedCodeString(t *testing.T) {
+	type TestData struct {
+		C    uint16
+		Want string
+	}
+
+	for _, td := range []TestData{
+		{0x0808, "c.addi4spn a0, sp, 16"},
+		{0x424c, "c.lw a1, 4(a2)"},
+		{0xdcf4, "c.sw a3, 124(s1)"},
+		{0x0001, "c.nop"},
+		{0x1575, "c.addi a0, -3"},
+		{0x678d, "c.lui a5, 3"},
+		{0x817d, "c.srli a0, 31"},
+		{0x8d0d, "c.sub a0, a1"},
+		{0xd101, "c.beqz a0, -256"},
+		{0x50fe, "c.lwsp ra, 252(sp)"},
+		{0x8082, "c.jr ra"},
+		{0x851e, "c.mv a0, t2"},
+		{0x9002, "c.ebreak"},
+		{0xdf86, "c.swsp ra, 252(sp)"},
+	} {
+		i := NewCompressedInstruction(td.C)
+		if got := i.GetCodeString(); got != td.Want {
+			t.Errorf("GetCodeString(0x%04x) got:%s, want:%s", td.C, got, td.Want)
+		}
+		if i.Len() != 2 {
+			t.Errorf("Len(0x%04x) must be 2", td.C)
+		}
+	}
+}
+
+func Test_StepCompressed(t *testing.T) {
+	e := NewEmulator()
+	// 0: c.li a0, 5
+	// 2: c.jal 6       -> 8
+	// 4: addi a1, a0, 1 (4 byte)
+	// 8: c.addi a0, 1
+	// a: c.jr ra       -> 4
+	mem := []uint8{0x15, 0x45, 0x19, 0x20}
+	mem = append(mem, u32sToBytes(GenCode(OpAddi, 11, 10, 1))...)
+	mem = append(mem, 0x05, 0x05, 0x82, 0x80)
+	e.WriteBytes(0, mem)
+
+	e.StepUntil(0x8)
+	if e.Cpu.X[10] != 5 || e.Cpu.X[1] != 4 {
+		t.Errorf("Wrong a0:%d, ra:0x%08x", e.Cpu.X[10], e.Cpu.X[1])
+	}
+	e.StepUntil(0x4)
+	if e.Cpu.X[10] != 6 {
+		t.Errorf("Wrong a0:%d", e.Cpu.X[10])
+	}
+	e.Step()
+	if e.Cpu.X[11] != 7 || e.Cpu.PC != 8 {
+		t.Errorf("Wrong a1:%d, pc:0x%08x", e.Cpu.X[11], e.Cpu.PC)
+	}
+}
@@ -1,8 +1,7 @@
 package rv32i
 
 import (
-	"errors"
-	"fmt"
+	"math"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -130,65 +129,247 @@ func RegName(i uint8) string {
 }
 
 type Cpu struct {
-	X   []uint32 // registers
-	PC  uint32   // program counter
-	Emu *Emulator
+	X    []uint32 // registers
+	F    []uint64 // floating point registers
+	PC   uint32   // program counter
+	Priv uint32   // privilege level, PrivU, PrivS or PrivM
+	Emu  *Emulator
+	Csr  CsrFile
+	tlb  TLB
+	raw  uint32 // instruction being executed
+	err  error  // set by Execute to stop the current Step
+
+	commit  Commit // of the instruction being executed
+	tracing bool   // commit is being recorded
 }
 
 func NewCpu() *Cpu {
-	return &Cpu{
-		X:   make([]uint32, 32),
-		PC:  0,
-		Emu: nil,
+	cpu := &Cpu{
+		X:    make([]uint32, 32),
+		F:    make([]uint64, 32),
+		PC:   0,
+		Priv: PrivM,
+		Emu:  nil,
 	}
+	cpu.Csr.Reset()
+	return cpu
 }
 
 func (c *Cpu) Reset() {
 	c.X = make([]uint32, 32)
+	c.F = make([]uint64, 32)
 	c.PC = 0
+	c.Priv = PrivM
+	c.Csr.Reset()
+	c.tlb = TLB{}
+	c.err = nil
 }
 
 func (c *Cpu) Step() error {
 	var err error
 	var u32instr uint32
 
+	c.Csr.Cycle++
+	c.Emu.Bus.Tick()
+	if h := c.Emu.Htif; h != nil {
+		if err = h.poll(c.Emu.Bus); err != nil {
+			return err
+		}
+	}
+
+	// interrupts are taken between instructions
+	if cause, ok := c.pendingInterrupt(); ok {
+		tracef("interrupt: %v", cause)
+		c.handleError(&Trap{Cause: cause, PC: c.PC})
+	}
+
 	// fetch
 	u32instr, err = c.Fetch()
 	if err != nil {
-		return err
+		return c.handleError(err)
 	}
-	trace("PC: 0x%08x, u32instr: %08x", c.PC, u32instr)
+	tracef("PC: 0x%08x (%v), u32instr: %08x", c.PC, symbolAddr{c.symbols(), c.PC}, u32instr)
 
 	// decode
-	instr := NewInstruction(u32instr)
+	instr := Decode(u32instr)
 	trace("instr: %+v", instr)
 
 	// execute
+	c.raw = u32instr
+	c.beginCommit(u32instr)
 	incrementPC := c.Execute(instr)
+	if c.err != nil {
+		err = c.err
+		c.err = nil
+		c.tracing = false
+		return c.handleError(err)
+	}
+	c.Csr.Instret++
 
 	// increment PC if it's not jump
 	if incrementPC {
-		c.PC += 4
+		c.PC += instr.Len()
 	}
 
-	return nil
+	return c.endCommit(instr)
 }
 
 func (c *Cpu) DumpRegisters() {
+	st := c.symbols()
+
 	log.Info("* Registers")
 	for i := 0; i < len(c.X); i++ {
-		log.Infof("x%d = %d, 0x%08x", i, c.X[i], c.X[i])
+		// return address
+		if _, ok := st.Find(c.X[i]); ok && i == Regs["ra"] {
+			log.Infof("x%d = %d, 0x%08x <%s>", i, c.X[i], c.X[i], st.Format(c.X[i]))
+		} else {
+			log.Infof("x%d = %d, 0x%08x", i, c.X[i], c.X[i])
+		}
+	}
+	if _, ok := st.Find(c.PC); ok {
+		log.Infof("pc = 0x%08x <%s>", c.PC, st.Format(c.PC))
+	} else {
+		log.Infof("pc = 0x%08x", c.PC)
+	}
+}
+
+func (c *Cpu) symbols() *SymbolTable {
+	if c.Emu == nil {
+		return nil
 	}
-	log.Infof("pc = 0x%08x", c.PC)
+	return c.Emu.Symbols
 }
 
+// Fetch returns a 32-bit instruction, or a 16-bit compressed instruction
+// in the lower half
 func (c *Cpu) Fetch() (uint32, error) {
-	if c.PC > MaxMemory {
-		return 0, errors.New("PC overflow")
+	lo, err := c.fetch(c.PC)
+	if err != nil {
+		return 0, err
+	}
+	if IsCompressed(uint32(lo)) {
+		return uint32(lo), nil
+	}
+
+	// the upper half can be on the next page
+	hi, err := c.fetch(c.PC + 2)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(hi)<<16 | uint32(lo), nil
+}
+
+// fetch reads 2 bytes of an instruction at the virtual address addr
+func (c *Cpu) fetch(addr uint32) (uint16, error) {
+	pa, err := c.translate(addr, accessFetch)
+	if err != nil {
+		return 0, err
+	}
+	if !c.pmpCheck(pa, 2, c.Priv, PmpX) {
+		return 0, c.accessFault(addr, accessFetch)
+	}
+	u16, err := c.Emu.ReadU16(pa)
+	if err != nil {
+		return 0, c.accessFault(addr, accessFetch)
+	}
+	return u16, nil
+}
+
+// load reads size bytes for load instructions.
+// It raises an exception and returns false on failure.
+func (c *Cpu) load(addr uint32, size uint32) (uint32, bool) {
+	if addr%size != 0 {
+		c.raise(CauseLoadAddressMisaligned, addr)
+		return 0, false
+	}
+	pa, err := c.translate(addr, accessLoad)
+	if err != nil {
+		c.err = err
+		return 0, false
+	}
+	return c.read(pa, addr, size, accessLoad)
+}
+
+// read reads size bytes at the physical address pa. It raises an access
+// fault at the virtual address addr and returns false on failure.
+func (c *Cpu) read(pa uint32, addr uint32, size uint32, access accessType) (uint32, bool) {
+	var data uint32
+	var err error
+
+	if !c.pmpCheck(pa, size, c.dataPriv(), PmpR) {
+		c.err = c.accessFault(addr, access)
+		return 0, false
+	}
+	switch size {
+	case 1:
+		var u8 uint8
+		u8, err = c.Emu.ReadU8(pa)
+		data = uint32(u8)
+	case 2:
+		var u16 uint16
+		u16, err = c.Emu.ReadU16(pa)
+		data = uint32(u16)
+	default:
+		data, err = c.Emu.ReadU32(pa)
+	}
+	if err != nil {
+		c.err = c.accessFault(addr, access)
+		return 0, false
+	}
+	c.accessed(addr, size, data, false)
+	return data, true
+}
+
+// store writes size bytes for store instructions.
+// It raises an exception and returns false on failure.
+func (c *Cpu) store(addr uint32, size uint32, data uint32) bool {
+	if addr%size != 0 {
+		c.raise(CauseStoreAddressMisaligned, addr)
+		return false
+	}
+	pa, err := c.translate(addr, accessStore)
+	if err != nil {
+		c.err = err
+		return false
+	}
+	return c.write(pa, addr, size, data)
+}
+
+// write writes size bytes at the physical address pa. It raises an access
+// fault at the virtual address addr and returns false on failure.
+func (c *Cpu) write(pa uint32, addr uint32, size uint32, data uint32) bool {
+	var err error
+
+	if !c.pmpCheck(pa, size, c.dataPriv(), PmpW) {
+		c.raise(CauseStoreAccessFault, addr)
+		return false
+	}
+	switch size {
+	case 1:
+		err = c.Emu.WriteU8(pa, uint8(data))
+	case 2:
+		err = c.Emu.WriteU16(pa, uint16(data))
+	default:
+		err = c.Emu.WriteU32(pa, data)
+	}
+	if err != nil {
+		c.raise(CauseStoreAccessFault, addr)
+		return false
 	}
-	i := c.Emu.ReadU32(c.PC)
+	c.accessed(addr, size, data, true)
+	return true
+}
 
-	return i, nil
+// jump sets PC to target. It raises an exception if target is misaligned.
+// Instructions are 2-byte aligned with RV32C.
+func (c *Cpu) jump(target uint32) bool {
+	if target%2 != 0 {
+		c.raise(CauseInstructionAddressMisaligned, target)
+		return false
+	}
+	c.PC = target
+	return true
 }
 
 func (c *Cpu) Execute(i *Instruction) bool {
@@ -208,16 +389,20 @@ func (c *Cpu) Execute(i *Instruction) bool {
 			c.X[i.Rd] = c.PC + i.Imm
 		}
 	case OpJal:
-		t := c.PC + 4
-		c.PC += i.Imm
+		t := c.PC + i.Len()
+		if !c.jump(c.PC + i.Imm) {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = t
 		}
 		trace("jal: PC=%x, X[%d]=%x", c.PC, i.Rd, t)
 		incrementPC = false
 	case OpJalr:
-		t := c.PC + 4
-		c.PC = (c.X[i.Rs1] + i.Imm) & 0xffffffe
+		t := c.PC + i.Len()
+		if !c.jump((c.X[i.Rs1] + i.Imm) &^ 1) {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = t
 		}
@@ -226,13 +411,13 @@ func (c *Cpu) Execute(i *Instruction) bool {
 	case OpBeq:
 		trace("beq: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if c.X[i.Rs1] == c.X[i.Rs2] {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpBne:
 		trace("bne: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if c.X[i.Rs1] != c.X[i.Rs2] {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpBlt:
@@ -241,7 +426,7 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		b := int32(c.X[i.Rs2])
 		trace("blt: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if a < b {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpBge:
@@ -250,28 +435,31 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		b := int32(c.X[i.Rs2])
 		trace("bge: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if a >= b {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpBltu:
 		// unsigned comparison
 		trace("bltu: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if c.X[i.Rs1] < c.X[i.Rs2] {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpBgeu:
 		// unsigned comparison
 		trace("bgeu: Rs1:%x, Rs2:%x", i.Rs1, i.Rs2)
 		if c.X[i.Rs1] >= c.X[i.Rs2] {
-			c.PC += i.Imm
+			c.jump(c.PC + i.Imm)
 			incrementPC = false
 		}
 	case OpLb:
 		// sign extension
 		addr := c.X[i.Rs1] + i.Imm
 		trace("lb: read %x -> X[%d]", addr, i.Rd)
-		data := uint32(c.Emu.ReadU8(addr))
+		data, ok := c.load(addr, 1)
+		if !ok {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = SignExtension(data, 7)
 		}
@@ -279,7 +467,10 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		// sign extension
 		addr := c.X[i.Rs1] + i.Imm
 		trace("lh: read %x -> X[%d]", addr, i.Rd)
-		data := uint32(c.Emu.ReadU16(addr))
+		data, ok := c.load(addr, 2)
+		if !ok {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = SignExtension(data, 15)
 		}
@@ -287,7 +478,10 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		// no extension
 		addr := c.X[i.Rs1] + i.Imm
 		trace("lw: read %x -> X[%d]", addr, i.Rd)
-		data := c.Emu.ReadU32(addr)
+		data, ok := c.load(addr, 4)
+		if !ok {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = data
 		}
@@ -295,7 +489,10 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		// zero extension
 		addr := c.X[i.Rs1] + i.Imm
 		trace("lbu: read %x -> X[%d]", addr, i.Rd)
-		data := uint32(c.Emu.ReadU8(addr))
+		data, ok := c.load(addr, 1)
+		if !ok {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = data
 		}
@@ -303,7 +500,10 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		// zero extension
 		addr := c.X[i.Rs1] + i.Imm
 		trace("lhu: read %x -> X[%d]", addr, i.Rd)
-		data := uint32(c.Emu.ReadU16(addr))
+		data, ok := c.load(addr, 2)
+		if !ok {
+			break
+		}
 		if i.Rd > 0 {
 			c.X[i.Rd] = data
 		}
@@ -312,19 +512,19 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		addr := c.X[i.Rs1] + i.Imm
 		data := uint8(c.X[i.Rs2] & 0xFF)
 		trace("sb: write %x at %x", data, addr)
-		c.Emu.WriteU8(addr, data)
+		c.store(addr, 1, uint32(data))
 	case OpSh:
 		// no extension
 		addr := c.X[i.Rs1] + i.Imm
 		data := uint16(c.X[i.Rs2] & 0xFFFF)
 		trace("sh: write %x at %x", data, addr)
-		c.Emu.WriteU16(addr, data)
+		c.store(addr, 2, uint32(data))
 	case OpSw:
 		// no extension
 		addr := c.X[i.Rs1] + i.Imm
 		data := c.X[i.Rs2]
 		trace("sw: write %x at %x", data, addr)
-		c.Emu.WriteU32(addr, data)
+		c.store(addr, 4, data)
 	case OpAddi:
 		trace("addi: rs1:%x + imm:%x -> rd:%x", i.Rs1, i.Imm, i.Rd)
 		if i.Rd > 0 {
@@ -333,22 +533,22 @@ func (c *Cpu) Execute(i *Instruction) bool {
 	case OpSlti:
 		// signed comparison
 		trace("slti: rs1:%x, imm:%x, rd:%x", i.Rs1, i.Imm, i.Rd)
-		if int32(c.X[i.Rs1]) < int32(i.Imm) {
-			if i.Rd > 0 {
+		if i.Rd > 0 {
+			if int32(c.X[i.Rs1]) < int32(i.Imm) {
 				c.X[i.Rd] = 1
+			} else {
+				c.X[i.Rd] = 0
 			}
-		} else {
-			c.X[i.Rd] = 0
 		}
 	case OpSltiu:
 		// unsigned comparison
 		trace("sltiu: rs1:%x, imm:%x, rd:%x", i.Rs1, i.Imm, i.Rd)
-		if c.X[i.Rs1] < i.Imm {
-			if i.Rd > 0 {
+		if i.Rd > 0 {
+			if c.X[i.Rs1] < i.Imm {
 				c.X[i.Rd] = 1
+			} else {
+				c.X[i.Rd] = 0
 			}
-		} else {
-			c.X[i.Rd] = 0
 		}
 	case OpXori:
 		trace("xori: rs1:%x, imm:%x, rd:%x", i.Rs1, i.Imm, i.Rd)
@@ -398,27 +598,28 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		// logical shift
 		trace("sll: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
 		if i.Rd > 0 {
-			c.X[i.Rd] = c.X[i.Rs1] << c.X[i.Rs2]
+			shamt := 0b11111 & c.X[i.Rs2]
+			c.X[i.Rd] = c.X[i.Rs1] << shamt
 		}
 	case OpSlt:
 		// signed comparison
 		trace("slt: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
-		if int32(c.X[i.Rs1]) < int32(c.X[i.Rs2]) {
-			if i.Rd > 0 {
+		if i.Rd > 0 {
+			if int32(c.X[i.Rs1]) < int32(c.X[i.Rs2]) {
 				c.X[i.Rd] = 1
+			} else {
+				c.X[i.Rd] = 0
 			}
-		} else {
-			c.X[i.Rd] = 0
 		}
 	case OpSltu:
 		// unsigned comparison
 		trace("sltu: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
-		if c.X[i.Rs1] < c.X[i.Rs2] {
-			if i.Rd > 0 {
+		if i.Rd > 0 {
+			if c.X[i.Rs1] < c.X[i.Rs2] {
 				c.X[i.Rd] = 1
+			} else {
+				c.X[i.Rd] = 0
 			}
-		} else {
-			c.X[i.Rd] = 0
 		}
 	case OpXor:
 		trace("xor: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
@@ -450,28 +651,134 @@ func (c *Cpu) Execute(i *Instruction) bool {
 		if i.Rd > 0 {
 			c.X[i.Rd] = c.X[i.Rs1] & c.X[i.Rs2]
 		}
-	case OpFence:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpFenceI:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
+	case OpMul:
+		trace("mul: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		if i.Rd > 0 {
+			c.X[i.Rd] = c.X[i.Rs1] * c.X[i.Rs2]
+		}
+	case OpMulh:
+		// signed x signed
+		trace("mulh: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		if i.Rd > 0 {
+			c.X[i.Rd] = uint32(uint64(int64(int32(c.X[i.Rs1]))*int64(int32(c.X[i.Rs2]))) >> 32)
+		}
+	case OpMulhsu:
+		// signed x unsigned
+		trace("mulhsu: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		if i.Rd > 0 {
+			c.X[i.Rd] = uint32(uint64(int64(int32(c.X[i.Rs1]))*int64(c.X[i.Rs2])) >> 32)
+		}
+	case OpMulhu:
+		// unsigned x unsigned
+		trace("mulhu: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		if i.Rd > 0 {
+			c.X[i.Rd] = uint32(uint64(c.X[i.Rs1]) * uint64(c.X[i.Rs2]) >> 32)
+		}
+	case OpDiv:
+		// signed, x/0 = -1, MinInt32/-1 = MinInt32
+		trace("div: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		a := int32(c.X[i.Rs1])
+		b := int32(c.X[i.Rs2])
+		data := uint32(0xffffffff)
+		if b == -1 && a == math.MinInt32 {
+			data = uint32(a)
+		} else if b != 0 {
+			data = uint32(a / b)
+		}
+		if i.Rd > 0 {
+			c.X[i.Rd] = data
+		}
+	case OpDivu:
+		// unsigned, x/0 = 0xffffffff
+		trace("divu: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		data := uint32(0xffffffff)
+		if c.X[i.Rs2] != 0 {
+			data = c.X[i.Rs1] / c.X[i.Rs2]
+		}
+		if i.Rd > 0 {
+			c.X[i.Rd] = data
+		}
+	case OpRem:
+		// signed, x%0 = x, MinInt32%-1 = 0
+		trace("rem: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		a := int32(c.X[i.Rs1])
+		b := int32(c.X[i.Rs2])
+		data := uint32(a)
+		if b == -1 {
+			data = 0
+		} else if b != 0 {
+			data = uint32(a % b)
+		}
+		if i.Rd > 0 {
+			c.X[i.Rd] = data
+		}
+	case OpRemu:
+		// unsigned, x%0 = x
+		trace("remu: rs1:%x, rs2:%x, rd:%x", i.Rs1, i.Rs2, i.Rd)
+		data := c.X[i.Rs1]
+		if c.X[i.Rs2] != 0 {
+			data = c.X[i.Rs1] % c.X[i.Rs2]
+		}
+		if i.Rd > 0 {
+			c.X[i.Rd] = data
+		}
+	case OpLrW, OpScW, OpAmoswapW, OpAmoaddW, OpAmoxorW, OpAmoandW, OpAmoorW, OpAmominW, OpAmomaxW, OpAmominuW, OpAmomaxuW:
+		trace("%s: rs1:%x, rs2:%x, rd:%x", op, i.Rs1, i.Rs2, i.Rd)
+		c.executeAtomic(op, i)
+	case OpFlw, OpFsw, OpFmaddS, OpFmsubS, OpFnmsubS, OpFnmaddS, OpFaddS, OpFsubS, OpFmulS,
+		OpFdivS, OpFsqrtS, OpFsgnjS, OpFsgnjnS, OpFsgnjxS, OpFminS, OpFmaxS, OpFcvtWS, OpFcvtWuS,
+		OpFmvXW, OpFeqS, OpFltS, OpFleS, OpFclassS, OpFcvtSW, OpFcvtSWu, OpFmvWX,
+		OpFld, OpFsd, OpFmaddD, OpFmsubD, OpFnmsubD, OpFnmaddD, OpFaddD, OpFsubD, OpFmulD,
+		OpFdivD, OpFsqrtD, OpFsgnjD, OpFsgnjnD, OpFsgnjxD, OpFminD, OpFmaxD, OpFcvtSD, OpFcvtDS,
+		OpFeqD, OpFltD, OpFleD, OpFclassD, OpFcvtWD, OpFcvtWuD, OpFcvtDW, OpFcvtDWu:
+		trace("%s: rs1:%x, rs2:%x, rd:%x, funct3:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Funct3, i.Imm)
+		c.executeFloat(op, i)
+	case OpFence, OpFenceI:
+		// nop: a single hart sees its memory accesses in order, and
+		// instructions are fetched from memory every time
+		trace("%s", op)
 	case OpEcall:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
+		cause := CauseEnvironmentCallFromU + TrapCause(c.Priv)
+		// the host handles it only if the guest can't take the trap
+		if c.Emu.Syscalls != nil && c.trapVector(cause) == 0 {
+			c.err = c.Emu.Syscalls.Dispatch(c)
+			break
+		}
+		c.raise(cause, 0)
 	case OpEbreak:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrw:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrs:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrc:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrwi:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrsi:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
-	case OpCsrrci:
-		log.Warnf("Op %v is not implemented yet. rs1:%x, rs2:%x, rd:%x, imm:%x", op, i.Rs1, i.Rs2, i.Rd, i.Imm)
+		c.raise(CauseBreakpoint, c.PC)
+	case OpCsrrw, OpCsrrs, OpCsrrc, OpCsrrwi, OpCsrrsi, OpCsrrci:
+		trace("%s: rd:%x, csr:%s, rs1:%x", op, i.Rd, CsrName(i.Csr()), i.Rs1)
+		c.executeCsr(op, i)
+	case OpMret:
+		trace("mret: PC=%x", c.Csr.Mepc)
+		if !c.mret() {
+			c.raise(CauseIllegalInstruction, c.raw)
+			break
+		}
+		incrementPC = false
+	case OpSret:
+		trace("sret: PC=%x", c.Csr.Sepc)
+		if !c.sret() {
+			c.raise(CauseIllegalInstruction, c.raw)
+			break
+		}
+		incrementPC = false
+	case OpSfenceVma:
+		trace("sfence.vma: rs1:%x, rs2:%x", i.Rs1, i.Rs2)
+		if !c.sfenceVma(i) {
+			c.raise(CauseIllegalInstruction, c.raw)
+		}
+	case OpWfi:
+		// a pending interrupt is taken before the next instruction, so wfi
+		// can be a nop. mstatus.TW makes it illegal below M-mode.
+		trace("wfi")
+		if c.Priv < PrivM && c.Csr.Mstatus&MstatusTW != 0 {
+			c.raise(CauseIllegalInstruction, c.raw)
+		}
 	default:
-		panic(fmt.Sprintf("Op: %s invalid", op))
+		trace("illegal instruction: %08x", c.raw)
+		c.raise(CauseIllegalInstruction, c.raw)
 	}
 	return incrementPC
 }
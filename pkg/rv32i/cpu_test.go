@@ -8,12 +8,7 @@ func Test_Execute(t *testing.T) {
 	var instr *Instruction
 	var inc bool
 
-	cpu := NewCpu()
-	emu := Emulator{
-		Cpu:    cpu,
-		Memory: make([]uint8, MaxMemory),
-	}
-	cpu.Emu = &emu
+	cpu := NewEmulator().Cpu
 
 	// Lui --------------------
 	cpu.Reset()
@@ -383,10 +378,9 @@ func Test_Execute(t *testing.T) {
 
 	// Lb --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	// TODO: use cpu.WriteU32
-	cpu.Emu.Memory[42] = 3
-	cpu.Emu.Memory[43] = 1
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 3)
+	cpu.Emu.WriteU8(43, 1)
 	code = GenCode(OpLb, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -397,9 +391,9 @@ func Test_Execute(t *testing.T) {
 
 	cpu.Reset()
 	cpu.X[1] = 100
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[142] = 4
-	cpu.Emu.Memory[143] = 1
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(142, 4)
+	cpu.Emu.WriteU8(143, 1)
 	code = GenCode(OpLb, 10, 42, 1) // x10 <- 42(x1)
 	instr = NewInstruction(code)
 
@@ -409,9 +403,9 @@ func Test_Execute(t *testing.T) {
 	}
 
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 0xff
-	cpu.Emu.Memory[43] = 1
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 0xff)
+	cpu.Emu.WriteU8(43, 1)
 	code = GenCode(OpLb, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -422,10 +416,10 @@ func Test_Execute(t *testing.T) {
 
 	// Lh --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 3
-	cpu.Emu.Memory[43] = 1
-	cpu.Emu.Memory[44] = 1
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 3)
+	cpu.Emu.WriteU8(43, 1)
+	cpu.Emu.WriteU8(44, 1)
 	code = GenCode(OpLh, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -435,9 +429,9 @@ func Test_Execute(t *testing.T) {
 	}
 
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 0xff
-	cpu.Emu.Memory[43] = 0xff
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 0xff)
+	cpu.Emu.WriteU8(43, 0xff)
 	code = GenCode(OpLh, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -448,13 +442,13 @@ func Test_Execute(t *testing.T) {
 
 	// Lw --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
+	cpu.Emu.newBus()
 	cpu.X[1] = 100
-	cpu.Emu.Memory[142] = 3
-	cpu.Emu.Memory[143] = 1
-	cpu.Emu.Memory[144] = 1
-	cpu.Emu.Memory[145] = 1
-	code = GenCode(OpLw, 10, 42, 1) // x10 <- 42(x1)
+	cpu.Emu.WriteU8(140, 3)
+	cpu.Emu.WriteU8(141, 1)
+	cpu.Emu.WriteU8(142, 1)
+	cpu.Emu.WriteU8(143, 1)
+	code = GenCode(OpLw, 10, 40, 1) // x10 <- 40(x1)
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
@@ -463,12 +457,12 @@ func Test_Execute(t *testing.T) {
 	}
 
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 0xff
-	cpu.Emu.Memory[43] = 0xff
-	cpu.Emu.Memory[44] = 0xff
-	cpu.Emu.Memory[45] = 0xff
-	code = GenCode(OpLw, 10, 42, 0) // x10 <- 42(x0)
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(40, 0xff)
+	cpu.Emu.WriteU8(41, 0xff)
+	cpu.Emu.WriteU8(42, 0xff)
+	cpu.Emu.WriteU8(43, 0xff)
+	code = GenCode(OpLw, 10, 40, 0) // x10 <- 40(x0)
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
@@ -478,9 +472,9 @@ func Test_Execute(t *testing.T) {
 
 	// Lbu --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 0xff
-	cpu.Emu.Memory[43] = 0
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 0xff)
+	cpu.Emu.WriteU8(43, 0)
 	code = GenCode(OpLbu, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -491,10 +485,10 @@ func Test_Execute(t *testing.T) {
 
 	// Lhu --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
-	cpu.Emu.Memory[42] = 0xff
-	cpu.Emu.Memory[43] = 0xff
-	cpu.Emu.Memory[44] = 0
+	cpu.Emu.newBus()
+	cpu.Emu.WriteU8(42, 0xff)
+	cpu.Emu.WriteU8(43, 0xff)
+	cpu.Emu.WriteU8(44, 0)
 	code = GenCode(OpLhu, 10, 42, 0) // x10 <- 42(x0)
 	instr = NewInstruction(code)
 
@@ -505,40 +499,39 @@ func Test_Execute(t *testing.T) {
 
 	// Sb --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
+	cpu.Emu.newBus()
 	cpu.X[10] = 0x11223344
 	code = GenCode(OpSb, 10, 42, 0) // 42(x0) <- x10
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
-	// TODO: use cpu.ReadU32
-	got = uint32(cpu.Emu.Memory[42]) | (uint32(cpu.Emu.Memory[43]) << 8) | (uint32(cpu.Emu.Memory[44]) << 16) | (uint32(cpu.Emu.Memory[45]) << 24)
+	got, _ = cpu.Emu.ReadU32(42)
 	if got != 0x44 {
 		t.Errorf("Wrong memory 0x%08x", got)
 	}
 
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
+	cpu.Emu.newBus()
 	cpu.X[1] = 100
 	cpu.X[10] = 0x11223344
 	code = GenCode(OpSb, 10, 42, 1) // 42(x1) <- x10
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
-	got = uint32(cpu.Emu.Memory[142]) | (uint32(cpu.Emu.Memory[143]) << 8) | (uint32(cpu.Emu.Memory[144]) << 16) | (uint32(cpu.Emu.Memory[145]) << 24)
+	got, _ = cpu.Emu.ReadU32(142)
 	if got != 0x44 {
 		t.Errorf("Wrong memory 0x%08x", got)
 	}
 
 	// Sh --------------------
 	cpu.Reset()
-	cpu.Emu.Memory = make([]uint8, MaxMemory)
+	cpu.Emu.newBus()
 	cpu.X[10] = 0x11223344
 	code = GenCode(OpSh, 10, 42, 0) // 42(x0) <- x10
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
-	got = uint32(cpu.Emu.Memory[42]) | (uint32(cpu.Emu.Memory[43]) << 8) | (uint32(cpu.Emu.Memory[44]) << 16) | (uint32(cpu.Emu.Memory[45]) << 24)
+	got, _ = cpu.Emu.ReadU32(42)
 	if got != 0x3344 {
 		t.Errorf("Wrong memory 0x%08x", got)
 	}
@@ -546,11 +539,11 @@ func Test_Execute(t *testing.T) {
 	// Sw --------------------
 	cpu.Reset()
 	cpu.X[10] = 0x11223344
-	code = GenCode(OpSw, 10, 42, 0) // 42(x0) <- x10
+	code = GenCode(OpSw, 10, 40, 0) // 40(x0) <- x10
 	instr = NewInstruction(code)
 
 	inc = cpu.Execute(instr)
-	got = uint32(cpu.Emu.Memory[42]) | (uint32(cpu.Emu.Memory[43]) << 8) | (uint32(cpu.Emu.Memory[44]) << 16) | (uint32(cpu.Emu.Memory[45]) << 24)
+	got, _ = cpu.Emu.ReadU32(40)
 	if got != 0x11223344 {
 		t.Errorf("Wrong memory 0x%08x", got)
 	}
@@ -991,3 +984,52 @@ func Test_Execute(t *testing.T) {
 		t.Errorf("Wrong X1, 0b%032b", cpu.X[1])
 	}
 }
+
+func Test_ExecuteM(t *testing.T) {
+	type TestData struct {
+		Op   OpName
+		Rs1  uint32
+		Rs2  uint32
+		Want uint32
+	}
+
+	cpu := NewCpu()
+	cpu.Emu = NewEmulator()
+
+	for _, td := range []TestData{
+		{OpMul, 7, 6, 42},
+		{OpMul, 0xffffffff, 3, 0xfffffffd},
+		{OpMulh, 0x80000000, 0x80000000, 0x40000000},
+		{OpMulh, 0xffffffff, 0xffffffff, 0},
+		{OpMulh, 0xffffffff, 1, 0xffffffff},
+		{OpMulhsu, 0xffffffff, 0xffffffff, 0xffffffff},
+		{OpMulhsu, 0x80000000, 0xffffffff, 0x80000000},
+		{OpMulhu, 0xffffffff, 0xffffffff, 0xfffffffe},
+		{OpMulhu, 0x80000000, 2, 1},
+		{OpDiv, 20, 0xfffffffa, 0xfffffffd},
+		{OpDiv, 0xffffffec, 6, 0xfffffffd},
+		{OpDiv, 20, 0, 0xffffffff},
+		{OpDiv, 0x80000000, 0xffffffff, 0x80000000},
+		{OpDivu, 20, 6, 3},
+		{OpDivu, 0xffffffec, 0, 0xffffffff},
+		{OpRem, 20, 0xfffffffa, 2},
+		{OpRem, 0xffffffec, 6, 0xfffffffe},
+		{OpRem, 20, 0, 20},
+		{OpRem, 0x80000000, 0xffffffff, 0},
+		{OpRemu, 20, 6, 2},
+		{OpRemu, 0xffffffec, 0, 0xffffffec},
+	} {
+		cpu.Reset()
+		cpu.X[3] = td.Rs1
+		cpu.X[4] = td.Rs2
+		instr := NewInstruction(GenCode(td.Op, 1, 3, 4))
+		if instr.GetOpName() != td.Op {
+			t.Errorf("Wrong decode %v, want %v", instr.GetOpName(), td.Op)
+		}
+
+		cpu.Execute(instr)
+		if cpu.X[1] != td.Want {
+			t.Errorf("%v 0x%08x, 0x%08x: got 0x%08x, want 0x%08x", td.Op, td.Rs1, td.Rs2, cpu.X[1], td.Want)
+		}
+	}
+}
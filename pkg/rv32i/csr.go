@@ -0,0 +1,546 @@
+package rv32i
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CSR addresses
+const (
+	CsrFflags     = uint32(0x001)
+	CsrFrm        = uint32(0x002)
+	CsrFcsr       = uint32(0x003)
+	CsrCycle      = uint32(0xc00)
+	CsrTime       = uint32(0xc01)
+	CsrInstret    = uint32(0xc02)
+	CsrCycleh     = uint32(0xc80)
+	CsrTimeh      = uint32(0xc81)
+	CsrInstreth   = uint32(0xc82)
+	CsrMvendorid  = uint32(0xf11)
+	CsrMarchid    = uint32(0xf12)
+	CsrMimpid     = uint32(0xf13)
+	CsrMhartid    = uint32(0xf14)
+	CsrSstatus    = uint32(0x100)
+	CsrSie        = uint32(0x104)
+	CsrStvec      = uint32(0x105)
+	CsrScounteren = uint32(0x106)
+	CsrSscratch   = uint32(0x140)
+	CsrSepc       = uint32(0x141)
+	CsrScause     = uint32(0x142)
+	CsrStval      = uint32(0x143)
+	CsrSip        = uint32(0x144)
+	CsrSatp       = uint32(0x180)
+	CsrMstatus    = uint32(0x300)
+	CsrMisa       = uint32(0x301)
+	CsrMedeleg    = uint32(0x302)
+	CsrMideleg    = uint32(0x303)
+	CsrMie        = uint32(0x304)
+	CsrMtvec      = uint32(0x305)
+	CsrMcounteren = uint32(0x306)
+	CsrMscratch   = uint32(0x340)
+	CsrMepc       = uint32(0x341)
+	CsrMcause     = uint32(0x342)
+	CsrMtval      = uint32(0x343)
+	CsrMip        = uint32(0x344)
+	CsrTselect    = uint32(0x7a0)
+	CsrTdata1     = uint32(0x7a1)
+	CsrTdata2     = uint32(0x7a2)
+	CsrTdata3     = uint32(0x7a3)
+	CsrMcycle     = uint32(0xb00)
+	CsrMinstret   = uint32(0xb02)
+	CsrMcycleh    = uint32(0xb80)
+	CsrMinstreth  = uint32(0xb82)
+)
+
+var csrNames = map[uint32]string{
+	CsrFflags:     "fflags",
+	CsrFrm:        "frm",
+	CsrFcsr:       "fcsr",
+	CsrCycle:      "cycle",
+	CsrTime:       "time",
+	CsrInstret:    "instret",
+	CsrCycleh:     "cycleh",
+	CsrTimeh:      "timeh",
+	CsrInstreth:   "instreth",
+	CsrMvendorid:  "mvendorid",
+	CsrMarchid:    "marchid",
+	CsrMimpid:     "mimpid",
+	CsrMhartid:    "mhartid",
+	CsrSstatus:    "sstatus",
+	CsrSie:        "sie",
+	CsrStvec:      "stvec",
+	CsrScounteren: "scounteren",
+	CsrSscratch:   "sscratch",
+	CsrSepc:       "sepc",
+	CsrScause:     "scause",
+	CsrStval:      "stval",
+	CsrSip:        "sip",
+	CsrSatp:       "satp",
+	CsrMstatus:    "mstatus",
+	CsrMisa:       "misa",
+	CsrMedeleg:    "medeleg",
+	CsrMideleg:    "mideleg",
+	CsrMie:        "mie",
+	CsrMtvec:      "mtvec",
+	CsrMcounteren: "mcounteren",
+	CsrMscratch:   "mscratch",
+	CsrMepc:       "mepc",
+	CsrMcause:     "mcause",
+	CsrMtval:      "mtval",
+	CsrMip:        "mip",
+	CsrTselect:    "tselect",
+	CsrTdata1:     "tdata1",
+	CsrTdata2:     "tdata2",
+	CsrTdata3:     "tdata3",
+	CsrMcycle:     "mcycle",
+	CsrMinstret:   "minstret",
+	CsrMcycleh:    "mcycleh",
+	CsrMinstreth:  "minstreth",
+}
+
+// CsrName returns the ABI name of the CSR, or its address if unknown
+func CsrName(addr uint32) string {
+	if name, ok := csrNames[addr]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%03x", addr)
+}
+
+// CsrAddrs returns the addresses of the known CSRs in ascending order
+func CsrAddrs() []uint32 {
+	addrs := make([]uint32, 0, len(csrNames))
+	for addr := range csrNames {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// mstatus fields
+const (
+	MstatusSIE  = uint32(1 << 1)
+	MstatusMIE  = uint32(1 << 3)
+	MstatusSPIE = uint32(1 << 5)
+	MstatusMPIE = uint32(1 << 7)
+	MstatusSPP  = uint32(1 << 8)
+	MstatusMPP  = uint32(0b11 << 11)
+	MstatusFS   = uint32(0b11 << 13)
+	MstatusMPRV = uint32(1 << 17)
+	MstatusSUM  = uint32(1 << 18)
+	MstatusMXR  = uint32(1 << 19)
+	MstatusTVM  = uint32(1 << 20)
+	MstatusTW   = uint32(1 << 21)
+	MstatusTSR  = uint32(1 << 22)
+	MstatusSD   = uint32(1 << 31)
+)
+
+// mstatus.FS states
+const (
+	FsOff     = uint32(0b00 << 13)
+	FsInitial = uint32(0b01 << 13)
+	FsClean   = uint32(0b10 << 13)
+	FsDirty   = uint32(0b11 << 13)
+)
+
+// mie/mip bits
+const (
+	MipSSIP = uint32(1 << 1)
+	MipMSIP = uint32(1 << 3)
+	MipSTIP = uint32(1 << 5)
+	MipMTIP = uint32(1 << 7)
+	MipSEIP = uint32(1 << 9)
+	MipMEIP = uint32(1 << 11)
+)
+
+// privilege levels
+const (
+	PrivU = uint32(0)
+	PrivS = uint32(1)
+	PrivM = uint32(3)
+)
+
+// mcounteren/scounteren bits
+const (
+	CounterenCY = uint32(1 << 0)
+	CounterenTM = uint32(1 << 1)
+	CounterenIR = uint32(1 << 2)
+)
+
+const (
+	misaMXL32 = uint32(1 << 30)
+	misaI     = uint32(1 << ('I' - 'A'))
+	misaM     = uint32(1 << ('M' - 'A'))
+	misaC     = uint32(1 << ('C' - 'A'))
+	misaA     = uint32(1 << ('A' - 'A'))
+	misaF     = uint32(1 << ('F' - 'A'))
+	misaD     = uint32(1 << ('D' - 'A'))
+	misaS     = uint32(1 << ('S' - 'A'))
+	misaU     = uint32(1 << ('U' - 'A'))
+
+	// WARL masks of the writable fields
+	mstatusMask = MstatusSIE | MstatusMIE | MstatusSPIE | MstatusMPIE | MstatusSPP | MstatusMPP | MstatusFS |
+		MstatusMPRV | MstatusSUM | MstatusMXR | MstatusTVM | MstatusTW | MstatusTSR
+	sstatusMask = MstatusSIE | MstatusSPIE | MstatusSPP | MstatusFS | MstatusSUM | MstatusMXR
+	mieMask     = MipSSIP | MipMSIP | MipSTIP | MipMTIP | MipSEIP | MipMEIP
+	// M-mode bits of mip are set by the interrupt sources, not by software.
+	// SSIP and STIP can be set by M-mode to pass interrupts to S-mode.
+	mipMask = MipSSIP | MipSTIP
+	sipMask = MipSSIP
+	// ecall from M-mode can't be delegated
+	medelegMask = uint32(0xffff) &^ (1 << CauseEnvironmentCallFromM)
+	midelegMask = MipSSIP | MipSTIP | MipSEIP
+	// satp MODE (Bare or Sv32), ASID and PPN
+	satpMask      = uint32(0xffffffff)
+	counterenMask = CounterenCY | CounterenTM | CounterenIR
+)
+
+// CsrFile has the machine and supervisor CSRs and fcsr.
+// sstatus, sie and sip are views of mstatus, mie and mip.
+// Registers are accessed by csr* instructions through Cpu.ReadCsr and
+// Cpu.WriteCsr which apply WARL masks.
+type CsrFile struct {
+	Mstatus    uint32
+	Misa       uint32
+	Medeleg    uint32
+	Mideleg    uint32
+	Mie        uint32
+	Mip        uint32
+	Mtvec      uint32 // traps to M-mode are returned from Step if 0
+	Mcounteren uint32
+	Mscratch   uint32
+	Mepc       uint32
+	Mcause     uint32
+	Mtval      uint32
+	Mhartid    uint32
+	Stvec      uint32 // traps to S-mode are returned from Step if 0
+	Scounteren uint32
+	Sscratch   uint32
+	Sepc       uint32
+	Scause     uint32
+	Stval      uint32
+	Satp       uint32
+	Pmpcfg     [PmpEntries]uint8
+	Pmpaddr    [PmpEntries]uint32
+	Fcsr       uint32 // frm and fflags
+	Cycle      uint64
+	Instret    uint64
+}
+
+func (f *CsrFile) Reset() {
+	*f = CsrFile{
+		// FP is enabled from the start
+		Mstatus: PrivM<<11 | FsInitial,
+		Misa:    misaMXL32 | misaI | misaM | misaA | misaF | misaD | misaC | misaS | misaU,
+		Mhartid: f.Mhartid,
+	}
+}
+
+// ReadCsr returns the CSR value. It returns false if the CSR doesn't exist.
+func (c *Cpu) ReadCsr(addr uint32) (uint32, bool) {
+	f := &c.Csr
+	switch addr {
+	case CsrFflags, CsrFrm, CsrFcsr:
+		if !c.isFpEnabled() {
+			return 0, false
+		}
+		switch addr {
+		case CsrFflags:
+			return f.Fcsr & 0b11111, true
+		case CsrFrm:
+			return f.Fcsr >> 5, true
+		default:
+			return f.Fcsr, true
+		}
+	case CsrCycle, CsrMcycle:
+		return uint32(f.Cycle), true
+	case CsrCycleh, CsrMcycleh:
+		return uint32(f.Cycle >> 32), true
+	case CsrTime:
+		return uint32(c.time()), true
+	case CsrTimeh:
+		return uint32(c.time() >> 32), true
+	case CsrInstret, CsrMinstret:
+		return uint32(f.Instret), true
+	case CsrInstreth, CsrMinstreth:
+		return uint32(f.Instret >> 32), true
+	case CsrMvendorid, CsrMarchid, CsrMimpid:
+		return 0, true
+	case CsrMhartid:
+		return f.Mhartid, true
+	case CsrSstatus:
+		return c.mstatus() & (sstatusMask | MstatusSD), true
+	case CsrSie:
+		return f.Mie & f.Mideleg, true
+	case CsrStvec:
+		return f.Stvec, true
+	case CsrScounteren:
+		return f.Scounteren, true
+	case CsrSscratch:
+		return f.Sscratch, true
+	case CsrSepc:
+		return f.Sepc, true
+	case CsrScause:
+		return f.Scause, true
+	case CsrStval:
+		return f.Stval, true
+	case CsrSip:
+		return f.Mip & f.Mideleg, true
+	case CsrSatp:
+		return f.Satp, true
+	case CsrMstatus:
+		return c.mstatus(), true
+	case CsrMisa:
+		return f.Misa, true
+	case CsrMedeleg:
+		return f.Medeleg, true
+	case CsrMideleg:
+		return f.Mideleg, true
+	case CsrMie:
+		return f.Mie, true
+	case CsrMtvec:
+		return f.Mtvec, true
+	case CsrMcounteren:
+		return f.Mcounteren, true
+	case CsrMscratch:
+		return f.Mscratch, true
+	case CsrMepc:
+		return f.Mepc, true
+	case CsrMcause:
+		return f.Mcause, true
+	case CsrMtval:
+		return f.Mtval, true
+	case CsrMip:
+		return f.Mip, true
+	case CsrTselect, CsrTdata1, CsrTdata2, CsrTdata3:
+		// there are no triggers, which tdata1.type 0 tells
+		return 0, true
+	default:
+		return c.readPmp(addr)
+	}
+}
+
+// mstatus returns mstatus with SD
+func (c *Cpu) mstatus() uint32 {
+	if c.Csr.Mstatus&MstatusFS == FsDirty {
+		return c.Csr.Mstatus | MstatusSD
+	}
+	return c.Csr.Mstatus
+}
+
+// time returns mtime of the CLINT, or cycles if there is no CLINT
+func (c *Cpu) time() uint64 {
+	if c.Emu != nil && c.Emu.Clint != nil {
+		return c.Emu.Clint.Mtime()
+	}
+	return c.Csr.Cycle
+}
+
+// WriteCsr writes the writable bits of the CSR. It returns false if the CSR
+// doesn't exist or is read-only.
+func (c *Cpu) WriteCsr(addr uint32, data uint32) bool {
+	f := &c.Csr
+
+	// csr[11:10] == 0b11 is read-only
+	if addr>>10 == 0b11 {
+		return false
+	}
+
+	switch addr {
+	case CsrFflags, CsrFrm, CsrFcsr:
+		if !c.isFpEnabled() {
+			return false
+		}
+		switch addr {
+		case CsrFflags:
+			f.Fcsr = f.Fcsr&^0b11111 | data&0b11111
+		case CsrFrm:
+			f.Fcsr = f.Fcsr&0b11111 | (data&0b111)<<5
+		default:
+			f.Fcsr = data & 0xff
+		}
+		c.setFpDirty()
+	case CsrMcycle:
+		f.Cycle = f.Cycle&0xffffffff_00000000 | uint64(data)
+	case CsrMcycleh:
+		f.Cycle = f.Cycle&0xffffffff | uint64(data)<<32
+	case CsrMinstret:
+		f.Instret = f.Instret&0xffffffff_00000000 | uint64(data)
+	case CsrMinstreth:
+		f.Instret = f.Instret&0xffffffff | uint64(data)<<32
+	case CsrSstatus:
+		c.writeMstatus(f.Mstatus&^sstatusMask | data&sstatusMask)
+	case CsrSie:
+		f.Mie = f.Mie&^f.Mideleg | data&f.Mideleg&mieMask
+	case CsrStvec:
+		f.Stvec = data &^ 0b10
+	case CsrScounteren:
+		f.Scounteren = data & counterenMask
+	case CsrSscratch:
+		f.Sscratch = data
+	case CsrSepc:
+		f.Sepc = data &^ 0b1
+	case CsrScause:
+		f.Scause = data
+	case CsrStval:
+		f.Stval = data
+	case CsrSip:
+		mask := f.Mideleg & sipMask
+		f.Mip = f.Mip&^mask | data&mask
+	case CsrSatp:
+		f.Satp = data & satpMask
+	case CsrMstatus:
+		c.writeMstatus(f.Mstatus&^mstatusMask | data&mstatusMask)
+	case CsrMisa:
+		// extensions can't be disabled
+	case CsrMedeleg:
+		f.Medeleg = data & medelegMask
+	case CsrMideleg:
+		f.Mideleg = data & midelegMask
+	case CsrMie:
+		f.Mie = data & mieMask
+	case CsrMtvec:
+		// only direct (0) and vectored (1) modes are legal
+		f.Mtvec = data &^ 0b10
+	case CsrMcounteren:
+		f.Mcounteren = data & counterenMask
+	case CsrMscratch:
+		f.Mscratch = data
+	case CsrMepc:
+		f.Mepc = data &^ 0b1
+	case CsrMcause:
+		f.Mcause = data
+	case CsrMtval:
+		f.Mtval = data
+	case CsrMip:
+		f.Mip = f.Mip&^mipMask | data&mipMask
+	case CsrTselect, CsrTdata1, CsrTdata2, CsrTdata3:
+		// there are no triggers
+	default:
+		return c.writePmp(addr, data)
+	}
+	return true
+}
+
+// writeMstatus writes mstatus. MPP keeps the previous mode if the new one is
+// not supported.
+func (c *Cpu) writeMstatus(data uint32) {
+	if (data&MstatusMPP)>>11 == 2 {
+		data = data&^MstatusMPP | c.Csr.Mstatus&MstatusMPP
+	}
+	c.Csr.Mstatus = data
+}
+
+// csrAccessible returns false if the current privilege level can't access
+// the CSR
+func (c *Cpu) csrAccessible(addr uint32) bool {
+	// csr[9:8] is the lowest privilege level
+	if c.Priv < (addr>>8)&0b11 {
+		return false
+	}
+	if addr == CsrSatp && c.Priv == PrivS && c.Csr.Mstatus&MstatusTVM != 0 {
+		return false
+	}
+
+	// counters in S/U-mode are enabled by mcounteren and scounteren
+	var bit uint32
+	switch addr {
+	case CsrCycle, CsrCycleh:
+		bit = CounterenCY
+	case CsrTime, CsrTimeh:
+		bit = CounterenTM
+	case CsrInstret, CsrInstreth:
+		bit = CounterenIR
+	default:
+		return true
+	}
+	if c.Priv < PrivM && c.Csr.Mcounteren&bit == 0 {
+		return false
+	}
+	if c.Priv < PrivS && c.Csr.Scounteren&bit == 0 {
+		return false
+	}
+	return true
+}
+
+// executeCsr runs csrrw, csrrs, csrrc and their immediate variants
+func (c *Cpu) executeCsr(op OpName, i *Instruction) {
+	addr := i.Csr()
+
+	// the immediate variants use rs1 as a 5 bit unsigned immediate
+	src := uint32(i.Rs1)
+	if op == OpCsrrw || op == OpCsrrs || op == OpCsrrc {
+		src = c.X[i.Rs1]
+	}
+
+	old, ok := c.ReadCsr(addr)
+	if !ok || !c.csrAccessible(addr) {
+		c.raise(CauseIllegalInstruction, c.raw)
+		return
+	}
+
+	// csrrs/csrrc with rs1 == x0 don't write, so they can read read-only CSRs
+	write := op == OpCsrrw || op == OpCsrrwi || i.Rs1 != 0
+
+	if write {
+		data := src
+		switch op {
+		case OpCsrrs, OpCsrrsi:
+			data = old | src
+		case OpCsrrc, OpCsrrci:
+			data = old &^ src
+		}
+		trace("csr: %s <- %x", CsrName(addr), data)
+		if !c.WriteCsr(addr, data) {
+			c.raise(CauseIllegalInstruction, c.raw)
+			return
+		}
+		c.traceCsr(addr)
+	}
+
+	if i.Rd > 0 {
+		c.X[i.Rd] = old
+	}
+}
+
+// mret returns from the machine-mode trap handler.
+// It returns false if mret is illegal in the current mode.
+func (c *Cpu) mret() bool {
+	f := &c.Csr
+	if c.Priv < PrivM {
+		return false
+	}
+	if f.Mstatus&MstatusMPIE != 0 {
+		f.Mstatus |= MstatusMIE
+	} else {
+		f.Mstatus &^= MstatusMIE
+	}
+	f.Mstatus |= MstatusMPIE
+	c.Priv = (f.Mstatus & MstatusMPP) >> 11
+	f.Mstatus &^= MstatusMPP
+	if c.Priv != PrivM {
+		f.Mstatus &^= MstatusMPRV
+	}
+	c.PC = f.Mepc
+	c.traceCsr(CsrMstatus)
+	return true
+}
+
+// sret returns from the supervisor-mode trap handler.
+// It returns false if sret is illegal in the current mode.
+func (c *Cpu) sret() bool {
+	f := &c.Csr
+	if c.Priv < PrivS || c.Priv == PrivS && f.Mstatus&MstatusTSR != 0 {
+		return false
+	}
+	if f.Mstatus&MstatusSPIE != 0 {
+		f.Mstatus |= MstatusSIE
+	} else {
+		f.Mstatus &^= MstatusSIE
+	}
+	f.Mstatus |= MstatusSPIE
+	c.Priv = (f.Mstatus & MstatusSPP) >> 8
+	f.Mstatus &^= MstatusSPP | MstatusMPRV
+	c.PC = f.Sepc
+	c.traceCsr(CsrMstatus)
+	return true
+}
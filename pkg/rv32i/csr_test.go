@@ -0,0 +1,158 @@
+package rv32i
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Csr(t *testing.T) {
+	cpu := NewCpu()
+	cpu.Emu = NewEmulator()
+
+	exec := func(code uint32) {
+		cpu.raw = code
+		cpu.Execute(NewInstruction(code))
+	}
+
+	// csrrw
+	cpu.X[10] = 0x1234
+	exec(GenCode(OpCsrrw, 11, int(CsrMscratch), 10))
+	if cpu.Csr.Mscratch != 0x1234 || cpu.X[11] != 0 {
+		t.Errorf("Wrong mscratch 0x%08x, X11 0x%08x", cpu.Csr.Mscratch, cpu.X[11])
+	}
+
+	// csrrs
+	cpu.X[10] = 0xff0000
+	exec(GenCode(OpCsrrs, 11, int(CsrMscratch), 10))
+	if cpu.Csr.Mscratch != 0xff1234 || cpu.X[11] != 0x1234 {
+		t.Errorf("Wrong mscratch 0x%08x, X11 0x%08x", cpu.Csr.Mscratch, cpu.X[11])
+	}
+
+	// csrrc
+	cpu.X[10] = 0xf00000
+	exec(GenCode(OpCsrrc, 11, int(CsrMscratch), 10))
+	if cpu.Csr.Mscratch != 0x0f1234 || cpu.X[11] != 0xff1234 {
+		t.Errorf("Wrong mscratch 0x%08x, X11 0x%08x", cpu.Csr.Mscratch, cpu.X[11])
+	}
+
+	// immediates
+	exec(GenCode(OpCsrrwi, 0, int(CsrMscratch), 0b10101))
+	exec(GenCode(OpCsrrsi, 0, int(CsrMscratch), 0b01000))
+	exec(GenCode(OpCsrrci, 11, int(CsrMscratch), 0b00001))
+	if cpu.Csr.Mscratch != 0b11100 || cpu.X[11] != 0b11101 {
+		t.Errorf("Wrong mscratch 0x%08x, X11 0x%08x", cpu.Csr.Mscratch, cpu.X[11])
+	}
+
+	// WARL
+	type TestData struct {
+		Csr   uint32
+		Write uint32
+		Want  uint32
+	}
+	for _, td := range []TestData{
+		{CsrMstatus, 0xffffffff, mstatusMask | MstatusSD},
+		// MPP=2 is reserved
+		{CsrMstatus, 0x1000, MstatusMPP},
+		{CsrMstatus, 0x800, PrivS << 11},
+		{CsrMisa, 0, misaMXL32 | misaI | misaM | misaA | misaF | misaD | misaC | misaS | misaU},
+		{CsrMie, 0xffffffff, MipSSIP | MipMSIP | MipSTIP | MipMTIP | MipSEIP | MipMEIP},
+		{CsrMip, 0xffffffff, MipSSIP | MipSTIP},
+		{CsrMideleg, 0xffffffff, MipSSIP | MipSTIP | MipSEIP},
+		{CsrMedeleg, 0xffffffff, 0xffff &^ (1 << CauseEnvironmentCallFromM)},
+		{CsrSstatus, 0xffffffff, MstatusSIE | MstatusSPIE | MstatusSPP | MstatusFS | MstatusSUM | MstatusMXR | MstatusSD},
+		{CsrSie, 0, 0},
+		{CsrSip, 0, MipSTIP},
+		{CsrStvec, 0x203, 0x201},
+		{CsrSepc, 0x203, 0x202},
+		{CsrMtvec, 0x103, 0x101},
+		{CsrMepc, 0x103, 0x102},
+		{CsrMcause, 0x80000007, 0x80000007},
+		// no triggers
+		{CsrTselect, 1, 0},
+		{CsrTdata1, 0xffffffff, 0},
+	} {
+		cpu.X[10] = td.Write
+		exec(GenCode(OpCsrrw, 11, int(td.Csr), 10))
+		exec(GenCode(OpCsrrs, 11, int(td.Csr), 0))
+		if cpu.X[11] != td.Want {
+			t.Errorf("%s: got 0x%08x, want 0x%08x", CsrName(td.Csr), cpu.X[11], td.Want)
+		}
+	}
+
+	// csrr of a read-only CSR doesn't write
+	cpu.err = nil
+	exec(GenCode(OpCsrrs, 11, int(CsrMhartid), 0))
+	if cpu.err != nil {
+		t.Errorf("csrr mhartid failed: %v", cpu.err)
+	}
+
+	// illegal
+	for _, code := range []uint32{
+		GenCode(OpCsrrw, 0, int(CsrMhartid), 10),
+		GenCode(OpCsrrsi, 11, int(CsrCycle), 1),
+		GenCode(OpCsrrs, 11, 0x7ff, 0),
+	} {
+		cpu.err = nil
+		exec(code)
+		var trap *Trap
+		if !errors.As(cpu.err, &trap) || trap.Cause != CauseIllegalInstruction || trap.Tval != code {
+			t.Errorf("0x%08x must raise IllegalInstruction, but was %v", code, cpu.err)
+		}
+	}
+}
+
+func Test_CsrCounters(t *testing.T) {
+	e := NewEmulator()
+	loadCode(e, 0,
+		GenCode(OpAddi, 1, 0, 1),
+		GenCode(OpAddi, 1, 0, 1),
+		GenCode(OpCsrrs, 10, int(CsrInstret), 0),
+		GenCode(OpCsrrs, 11, int(CsrCycle), 0),
+		GenCode(OpCsrrs, 12, int(CsrInstreth), 0),
+	)
+	e.StepUntil(4 * 5)
+	if e.Cpu.X[10] != 2 || e.Cpu.X[11] != 4 || e.Cpu.X[12] != 0 {
+		t.Errorf("Wrong counters instret:%d, cycle:%d, instreth:%d", e.Cpu.X[10], e.Cpu.X[11], e.Cpu.X[12])
+	}
+	if e.Cpu.Csr.Instret != 5 {
+		t.Errorf("instret must be %d, but was %d", 5, e.Cpu.Csr.Instret)
+	}
+}
+
+func Test_Mret(t *testing.T) {
+	e := NewEmulator()
+	loadCode(e, 0,
+		// mtvec <- handler, mstatus.MIE <- 1
+		GenCode(OpAddi, 5, 0, 0x100),
+		GenCode(OpCsrrw, 0, int(CsrMtvec), 5),
+		GenCode(OpCsrrsi, 0, int(CsrMstatus), int(MstatusMIE)),
+		GenCode(OpEbreak, 0, 0, 0),
+		GenCode(OpAddi, 10, 0, 1),
+	)
+	// handler: skip ebreak and return
+	loadCode(e, 0x100,
+		GenCode(OpCsrrs, 6, int(CsrMepc), 0),
+		GenCode(OpAddi, 6, 6, 4),
+		GenCode(OpCsrrw, 0, int(CsrMepc), 6),
+		GenCode(OpMret, 0, 0, 0),
+	)
+
+	e.StepUntil(0x100)
+	if e.Cpu.Csr.Mcause != uint32(CauseBreakpoint) || e.Cpu.Csr.Mepc != 12 {
+		t.Errorf("Wrong mcause:%d, mepc:0x%08x", e.Cpu.Csr.Mcause, e.Cpu.Csr.Mepc)
+	}
+	if e.Cpu.Csr.Mstatus&MstatusMIE != 0 || e.Cpu.Csr.Mstatus&MstatusMPIE == 0 {
+		t.Errorf("Wrong mstatus in the handler 0x%08x", e.Cpu.Csr.Mstatus)
+	}
+
+	err := e.StepUntil(4 * 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Cpu.X[10] != 1 {
+		t.Errorf("X10 must be %d, but was %d", 1, e.Cpu.X[10])
+	}
+	if e.Cpu.Csr.Mstatus&MstatusMIE == 0 {
+		t.Errorf("MIE must be restored 0x%08x", e.Cpu.Csr.Mstatus)
+	}
+}
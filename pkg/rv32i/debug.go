@@ -7,3 +7,7 @@ import log "github.com/sirupsen/logrus"
 func trace(args ...interface{}) {
 	log.Trace(args...)
 }
+
+func tracef(format string, args ...interface{}) {
+	log.Tracef(format, args...)
+}
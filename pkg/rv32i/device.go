@@ -0,0 +1,92 @@
+package rv32i
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Device is a memory mapped device on the Bus.
+// offset is relative to the base address the device is mapped at, and size is
+// 1, 2 or 4. Values are little endian.
+type Device interface {
+	Read(offset uint32, size uint32) (uint32, error)
+	Write(offset uint32, size uint32, data uint32) error
+	// Tick is called once per instruction
+	Tick()
+}
+
+// bytesDevice is a Device which can copy bytes in bulk
+type bytesDevice interface {
+	ReadBytes(offset uint32, data []byte) error
+	WriteBytes(offset uint32, data []byte) error
+}
+
+// loadableDevice is a Device which programs can be loaded into even if the
+// guest can't write it
+type loadableDevice interface {
+	Load(offset uint32, data []byte) error
+}
+
+// clearableDevice is a Device which can zero a range without writing every
+// byte
+type clearableDevice interface {
+	Clear(offset uint32, size uint32) error
+}
+
+var errReadOnly = errors.New("read-only device")
+
+// ROM is a read-only memory device. Programs can be loaded into it by the
+// loader, but guest stores raise access faults.
+type ROM struct {
+	data []byte
+}
+
+func NewROM(size uint32) *ROM {
+	return &ROM{
+		data: make([]byte, size),
+	}
+}
+
+func (r *ROM) inRange(offset uint32, size uint32) error {
+	if uint64(offset)+uint64(size) > uint64(len(r.data)) {
+		return fmt.Errorf("0x%08x-0x%08x is out of ROM", offset, uint64(offset)+uint64(size))
+	}
+	return nil
+}
+
+func (r *ROM) Read(offset uint32, size uint32) (uint32, error) {
+	if err := r.inRange(offset, size); err != nil {
+		return 0, err
+	}
+	var data uint32
+	for i := uint32(0); i < size; i++ {
+		data |= uint32(r.data[offset+i]) << (8 * i)
+	}
+	return data, nil
+}
+
+func (r *ROM) Write(offset uint32, size uint32, data uint32) error {
+	return errReadOnly
+}
+
+func (r *ROM) Tick() {}
+
+func (r *ROM) ReadBytes(offset uint32, data []byte) error {
+	if err := r.inRange(offset, uint32(len(data))); err != nil {
+		return err
+	}
+	copy(data, r.data[offset:])
+	return nil
+}
+
+func (r *ROM) WriteBytes(offset uint32, data []byte) error {
+	return errReadOnly
+}
+
+func (r *ROM) Load(offset uint32, data []byte) error {
+	if err := r.inRange(offset, uint32(len(data))); err != nil {
+		return err
+	}
+	copy(r.data[offset:], data)
+	return nil
+}
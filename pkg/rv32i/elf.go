@@ -0,0 +1,139 @@
+package rv32i
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var elfMagic = []byte{0x7f, 'E', 'L', 'F'}
+
+// IsELF returns true if the file starts with the ELF magic number
+func (l *Loader) IsELF(filePath string) bool {
+	fp, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer fp.Close()
+
+	magic := make([]byte, len(elfMagic))
+	if _, err = io.ReadFull(fp, magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, elfMagic)
+}
+
+// LoadELFAt loads every PT_LOAD segment of an ELF32 little-endian RISC-V
+// executable at its physical address and returns the entry point
+func (l *Loader) LoadELFAt(filePath string, bus *Bus) (uint32, error) {
+	f, err := elf.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return l.loadELF(f, bus)
+}
+
+func (l *Loader) loadELF(f *elf.File, bus *Bus) (uint32, error) {
+	if err := checkELFHeader(f); err != nil {
+		return 0, err
+	}
+
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD || p.Memsz == 0 {
+			continue
+		}
+		if p.Filesz > p.Memsz {
+			return 0, fmt.Errorf("ELF segment at 0x%08x has filesz 0x%x larger than memsz 0x%x", p.Paddr, p.Filesz, p.Memsz)
+		}
+		if p.Memsz >= 1<<32 || p.Paddr+p.Memsz > 1<<32 || !bus.IsMapped(uint32(p.Paddr), uint32(p.Memsz)) {
+			return 0, fmt.Errorf("ELF segment 0x%08x-0x%08x is not in mapped memory", p.Paddr, p.Paddr+p.Memsz)
+		}
+
+		start := uint32(p.Paddr)
+		memEnd := start + uint32(p.Memsz)
+
+		data := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(data, 0); err != nil && err != io.EOF {
+			return 0, err
+		}
+		if err := bus.Load(start, data); err != nil {
+			return 0, err
+		}
+		// the rest of the file part is BSS, which is cleared without
+		// allocating pages of RAM
+		if err := bus.Clear(start+uint32(p.Filesz), uint32(p.Memsz-p.Filesz)); err != nil {
+			return 0, err
+		}
+		trace("ELF: loaded segment 0x%08x-0x%08x (filesz 0x%x)", start, memEnd, p.Filesz)
+	}
+
+	return uint32(f.Entry), nil
+}
+
+// ReadELFSymbols reads the .symtab/.strtab of an ELF32 RISC-V executable
+func (l *Loader) ReadELFSymbols(filePath string) (*SymbolTable, error) {
+	f, err := elf.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := checkELFHeader(f); err != nil {
+		return nil, err
+	}
+	return l.readELFSymbols(f)
+}
+
+func (l *Loader) readELFSymbols(f *elf.File) (*SymbolTable, error) {
+	st := NewSymbolTable()
+
+	syms, err := f.Symbols()
+	if err == elf.ErrNoSymbols {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sym := range syms {
+		typ := elf.ST_TYPE(sym.Info)
+		if sym.Name == "" || sym.Section == elf.SHN_UNDEF || typ == elf.STT_SECTION || typ == elf.STT_FILE {
+			continue
+		}
+		// assembler local labels and mapping symbols
+		if strings.HasPrefix(sym.Name, ".L") || strings.HasPrefix(sym.Name, "$") {
+			continue
+		}
+		st.Add(Symbol{
+			Name: sym.Name,
+			Addr: uint32(sym.Value),
+			Size: uint32(sym.Size),
+			Func: typ == elf.STT_FUNC,
+		})
+	}
+	trace("ELF: read symbols: ", st.Len())
+
+	return st, nil
+}
+
+func checkELFHeader(f *elf.File) error {
+	if f.Class != elf.ELFCLASS32 {
+		return fmt.Errorf("ELF class %v not supported, only ELFCLASS32 is", f.Class)
+	}
+	if f.Data != elf.ELFDATA2LSB {
+		return fmt.Errorf("ELF data encoding %v not supported, only little-endian is", f.Data)
+	}
+	if f.Machine != elf.EM_RISCV {
+		return fmt.Errorf("ELF machine %v is not RISC-V", f.Machine)
+	}
+	if f.Type != elf.ET_EXEC {
+		return errors.New("ELF file is not an executable")
+	}
+	return nil
+}
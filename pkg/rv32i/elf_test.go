@@ -0,0 +1,280 @@
+package rv32i
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testSegment struct {
+	Paddr uint32
+	Data  []byte
+	Memsz uint32
+}
+
+type testSymbol struct {
+	Name string
+	Addr uint32
+	Size uint32
+	Type elf.SymType
+}
+
+type testELF struct {
+	Machine  elf.Machine
+	Entry    uint32
+	Segments []testSegment
+	Symbols  []testSymbol
+}
+
+// build makes an ELF32 little-endian executable image
+func (te *testELF) build() []byte {
+	const ehsize = 52
+	const phentsize = 32
+
+	machine := te.Machine
+	if machine == 0 {
+		machine = elf.EM_RISCV
+	}
+
+	buf := new(bytes.Buffer)
+	phoff := uint32(ehsize)
+	dataOff := phoff + uint32(len(te.Segments))*phentsize
+
+	// ELF header
+	ident := [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS32), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)}
+	buf.Write(ident[:])
+	binary.Write(buf, binary.LittleEndian, header32WithoutIdent{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     te.Entry,
+		Phoff:     phoff,
+		Ehsize:    ehsize,
+		Phentsize: phentsize,
+		Phnum:     uint16(len(te.Segments)),
+		Shentsize: 40,
+	})
+
+	// program headers
+	off := dataOff
+	for _, seg := range te.Segments {
+		memsz := seg.Memsz
+		if memsz == 0 {
+			memsz = uint32(len(seg.Data))
+		}
+		binary.Write(buf, binary.LittleEndian, elf.Prog32{
+			Type:   uint32(elf.PT_LOAD),
+			Off:    off,
+			Vaddr:  seg.Paddr,
+			Paddr:  seg.Paddr,
+			Filesz: uint32(len(seg.Data)),
+			Memsz:  memsz,
+			Flags:  uint32(elf.PF_R | elf.PF_W | elf.PF_X),
+			Align:  4,
+		})
+		off += uint32(len(seg.Data))
+	}
+
+	// segment data
+	for _, seg := range te.Segments {
+		buf.Write(seg.Data)
+	}
+
+	if len(te.Symbols) > 0 {
+		te.writeSymbols(buf)
+	}
+
+	return buf.Bytes()
+}
+
+// writeSymbols appends .symtab, .strtab and .shstrtab and their section headers
+func (te *testELF) writeSymbols(buf *bytes.Buffer) {
+	const shentsize = 40
+
+	strtab := []byte{0}
+	symtab := new(bytes.Buffer)
+	binary.Write(symtab, binary.LittleEndian, elf.Sym32{})
+	for _, sym := range te.Symbols {
+		binary.Write(symtab, binary.LittleEndian, elf.Sym32{
+			Name:  uint32(len(strtab)),
+			Value: sym.Addr,
+			Size:  sym.Size,
+			Info:  elf.ST_INFO(elf.STB_GLOBAL, sym.Type),
+			Shndx: uint16(elf.SHN_ABS),
+		})
+		strtab = append(strtab, []byte(sym.Name)...)
+		strtab = append(strtab, 0)
+	}
+	shstrtab := []byte("\x00.symtab\x00.strtab\x00.shstrtab\x00")
+
+	symtabOff := uint32(buf.Len())
+	buf.Write(symtab.Bytes())
+	strtabOff := uint32(buf.Len())
+	buf.Write(strtab)
+	shstrtabOff := uint32(buf.Len())
+	buf.Write(shstrtab)
+	shoff := uint32(buf.Len())
+
+	binary.Write(buf, binary.LittleEndian, elf.Section32{})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 1, Type: uint32(elf.SHT_SYMTAB), Off: symtabOff, Size: uint32(symtab.Len()),
+		Link: 2, Info: 1, Addralign: 4, Entsize: elf.Sym32Size,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 9, Type: uint32(elf.SHT_STRTAB), Off: strtabOff, Size: uint32(len(strtab)), Addralign: 1,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 17, Type: uint32(elf.SHT_STRTAB), Off: shstrtabOff, Size: uint32(len(shstrtab)), Addralign: 1,
+	})
+
+	// patch e_shoff, e_shentsize, e_shnum and e_shstrndx in the ELF header
+	b := buf.Bytes()
+	binary.LittleEndian.PutUint32(b[32:], shoff)
+	binary.LittleEndian.PutUint16(b[46:], shentsize)
+	binary.LittleEndian.PutUint16(b[48:], 4)
+	binary.LittleEndian.PutUint16(b[50:], 3)
+}
+
+func (te *testELF) write(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "test.elf")
+	if err := os.WriteFile(path, te.build(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+type header32WithoutIdent struct {
+	Type      uint16
+	Machine   uint16
+	Version   uint32
+	Entry     uint32
+	Phoff     uint32
+	Shoff     uint32
+	Flags     uint32
+	Ehsize    uint16
+	Phentsize uint16
+	Phnum     uint16
+	Shentsize uint16
+	Shnum     uint16
+	Shstrndx  uint16
+}
+
+func u32sToBytes(u32s ...uint32) []byte {
+	b := make([]byte, len(u32s)*4)
+	for idx, u32 := range u32s {
+		binary.LittleEndian.PutUint32(b[idx*4:], u32)
+	}
+	return b
+}
+
+func Test_IsELF(t *testing.T) {
+	te := testELF{Entry: 0}
+	path := te.write(t)
+
+	loader := NewLoader()
+	if !loader.IsELF(path) {
+		t.Errorf("%s must be detected as ELF", path)
+	}
+	if loader.IsELF("../../data/sample-binary-003.bin") {
+		t.Error("sample-binary-003.bin must not be detected as ELF")
+	}
+}
+
+func Test_LoadELF(t *testing.T) {
+	// li a0, 42
+	// lui a1, 1
+	// sw a0, 0(a1)
+	code := u32sToBytes(0x02a00513, 0x000015b7, 0x00a5a023)
+	te := testELF{
+		Entry: 0x100,
+		Segments: []testSegment{
+			{Paddr: 0x100, Data: code},
+			// .data followed by .bss
+			{Paddr: 0x800, Data: []byte{1, 2, 3, 4}, Memsz: 0x10},
+			// a large BSS
+			{Paddr: RAMBase, Data: []byte{1, 2, 3, 4}, Memsz: 0x4000000},
+		},
+	}
+	path := te.write(t)
+
+	e := NewEmulator()
+	// garbage which must be cleared as BSS
+	for i := uint32(0x804); i < 0x810; i++ {
+		e.WriteU8(i, 0xaa)
+	}
+	e.WriteU32(RAMBase+0x2ffc, 0xaaaaaaaa)
+	err := e.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Cpu.PC != 0x100 {
+		t.Errorf("PC must be 0x%08x, but was 0x%08x", 0x100, e.Cpu.PC)
+	}
+	got, _ := e.ReadBytes(0x100, uint32(len(code)))
+	if !bytes.Equal(got, code) {
+		t.Errorf("code must be %x, but was %x", code, got)
+	}
+	want := []byte{1, 2, 3, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	got, _ = e.ReadBytes(0x800, uint32(len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("data must be %x, but was %x", want, got)
+	}
+
+	// the BSS reads as 0 without allocating pages
+	if u32, _ := e.ReadU32(RAMBase + 0x2ffc); u32 != 0 {
+		t.Errorf("BSS must be 0, but was 0x%08x", u32)
+	}
+	if ram := e.Bus.find(RAMBase, 1).Device.(*RAM); len(ram.pages) != 1 {
+		t.Errorf("only the page of the data must be allocated, but were %d", len(ram.pages))
+	}
+
+	e.StepUntil(0x10c)
+	if e.Cpu.X[10] != 42 {
+		t.Errorf("a0 must be %d, but was %d", 42, e.Cpu.X[10])
+	}
+	if u8, _ := e.ReadU8(0x1000); u8 != 42 {
+		t.Errorf("0x1000 must be %d, but was %d", 42, u8)
+	}
+}
+
+func Test_LoadELFErrors(t *testing.T) {
+	loader := NewLoader()
+	bus := NewEmulator().Bus
+
+	// x86-64
+	te := testELF{Machine: elf.EM_X86_64}
+	_, err := loader.LoadELFAt(te.write(t), bus)
+	if err == nil || !strings.Contains(err.Error(), "not RISC-V") {
+		t.Errorf("non RISC-V ELF must be rejected, but got %v", err)
+	}
+
+	// does not fit
+	te = testELF{Segments: []testSegment{{Paddr: MaxMemory - 4, Data: make([]byte, 8)}}}
+	_, err = loader.LoadELFAt(te.write(t), bus)
+	if err == nil {
+		t.Error("a segment out of memory must be rejected")
+	}
+
+	// ELF64
+	hdr := make([]byte, 64)
+	copy(hdr, []byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)})
+	binary.LittleEndian.PutUint16(hdr[16:], uint16(elf.ET_EXEC))
+	binary.LittleEndian.PutUint16(hdr[18:], uint16(elf.EM_RISCV))
+	binary.LittleEndian.PutUint32(hdr[20:], uint32(elf.EV_CURRENT))
+	binary.LittleEndian.PutUint16(hdr[52:], 64)
+	binary.LittleEndian.PutUint16(hdr[54:], 56)
+	binary.LittleEndian.PutUint16(hdr[58:], 64)
+	path := filepath.Join(t.TempDir(), "test64.elf")
+	if err := os.WriteFile(path, hdr, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = loader.LoadELFAt(path, bus)
+	if err == nil || !strings.Contains(err.Error(), "ELFCLASS64") {
+		t.Errorf("64-bit ELF must be rejected, but got %v", err)
+	}
+}
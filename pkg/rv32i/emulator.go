@@ -1,37 +1,191 @@
 package rv32i
 
-const MaxMemory = uint32(0x10_000)
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// default RAM regions
+const (
+	MaxMemory = uint32(0x10_000)    // at 0 for programs linked at 0
+	RAMBase   = uint32(0x8000_0000) // where most of the toolchains link programs
+	RAMSize   = uint32(0x0800_0000)
+)
+
+// EmulatorConfig declares the guest physical address space
+type EmulatorConfig struct {
+	Memory   []MemoryRegion // RAM
+	Devices  []Mapping      // ROM and peripherals
+	CLINT    bool           // maps a CLINT at CLINTBase
+	PLIC     bool           // maps a PLIC at PLICBase
+	TimeMode TimeMode       // how mtime of the CLINT advances
+	Console  io.Writer      // console of HTIF, discarded if nil
+}
+
+// DefaultEmulatorConfig maps MaxMemory bytes at 0, RAMSize bytes at RAMBase,
+// a CLINT and a PLIC
+func DefaultEmulatorConfig() EmulatorConfig {
+	return EmulatorConfig{
+		Memory: []MemoryRegion{
+			{Base: 0, Size: MaxMemory},
+			{Base: RAMBase, Size: RAMSize},
+		},
+		CLINT: true,
+		PLIC:  true,
+	}
+}
+
+// MemoryHook is called after a load, store or AMO of the guest accessed
+// memory. addr is the virtual address.
+type MemoryHook func(addr uint32, size uint32, write bool)
 
 type Emulator struct {
-	Cpu    *Cpu
-	Memory []uint8
+	Cpu          *Cpu
+	Config       EmulatorConfig
+	Bus          *Bus
+	Clint        *CLINT // nil if EmulatorConfig.CLINT is false
+	Plic         *PLIC  // nil if EmulatorConfig.PLIC is false
+	Symbols      *SymbolTable
+	Syscalls     *Syscalls  // ecall is emulated by the host if set
+	Htif         *HTIF      // set by Load if the ELF has tohost
+	MemoryHook   MemoryHook // called on data accesses of the guest if set
+	Tracer       Tracer     // receives the Commit of each instruction if set
+	Reservations *Reservations
+
+	breakpoints breakpoints
 }
 
 func NewEmulator() *Emulator {
+	emu, err := NewEmulatorWithConfig(DefaultEmulatorConfig())
+	if err != nil {
+		panic(err)
+	}
+	return emu
+}
+
+// NewEmulatorWithConfig returns an error if the mappings are invalid
+func NewEmulatorWithConfig(cfg EmulatorConfig) (*Emulator, error) {
 	cpu := NewCpu()
 
 	emu := Emulator{
-		Cpu:    cpu,
-		Memory: make([]uint8, MaxMemory),
+		Cpu:          cpu,
+		Config:       cfg,
+		Symbols:      NewSymbolTable(),
+		Reservations: NewReservations(),
 	}
 	cpu.Emu = &emu
 
-	return &emu
+	if err := emu.newBus(); err != nil {
+		return nil, err
+	}
+	return &emu, nil
+}
+
+// newBus maps new RAM, the CLINT, the PLIC and the devices of the config.
+// Devices with an IRQ are connected to the PLIC.
+func (e *Emulator) newBus() error {
+	cfg := e.Config
+	if len(cfg.Memory)+len(cfg.Devices) == 0 {
+		return errors.New("nothing is mapped")
+	}
+	bus := NewBus()
+	e.Clint = nil
+	if cfg.CLINT {
+		e.Clint = NewCLINT(cfg.TimeMode, e.Cpu)
+		if err := bus.Map(CLINTBase, CLINTSize, e.Clint); err != nil {
+			return err
+		}
+	}
+	e.Plic = nil
+	if cfg.PLIC {
+		e.Plic = NewPLIC(e.Cpu)
+		if err := bus.Map(PLICBase, PLICSize, e.Plic); err != nil {
+			return err
+		}
+	}
+	for _, r := range cfg.Memory {
+		if err := bus.Map(r.Base, r.Size, NewRAM(r.Size)); err != nil {
+			return err
+		}
+	}
+	for _, m := range cfg.Devices {
+		if err := bus.Map(m.Base, m.Size, m.Device); err != nil {
+			return err
+		}
+		if m.IRQ == 0 {
+			continue
+		}
+		src, ok := m.Device.(InterruptSource)
+		if e.Plic == nil || !ok || m.IRQ >= plicSources {
+			return fmt.Errorf("device at 0x%08x can't be connected to PLIC source %d", m.Base, m.IRQ)
+		}
+		e.Plic.Connect(m.IRQ, src)
+	}
+	e.Bus = bus
+	return nil
+}
+
+// SetTimeMode selects how mtime advances
+func (e *Emulator) SetTimeMode(mode TimeMode) {
+	e.Config.TimeMode = mode
+	if e.Clint != nil {
+		e.Clint.SetMode(mode)
+	}
 }
 
+// Reset clears RAM, the CLINT and the PLIC. Devices keep their states.
 func (e *Emulator) Reset() {
 	e.Cpu.Reset()
-	e.Memory = make([]uint8, MaxMemory)
+	// the mappings were validated by NewEmulatorWithConfig
+	e.newBus()
+	e.Symbols = NewSymbolTable()
+	e.Htif = nil
+	e.Reservations = NewReservations()
 }
 
+// loadBase returns the lowest address programs can be loaded at
+func (e *Emulator) loadBase() uint32 {
+	for _, m := range e.Bus.Mappings() {
+		if _, ok := m.Device.(loadableDevice); ok {
+			return m.Base
+		}
+	}
+	return 0
+}
+
+// Load loads an ELF at its physical addresses, or a text dump or a raw binary
+// at the lowest RAM or ROM address
 func (e *Emulator) Load(filePath string) error {
 	loader := NewLoader()
-	return loader.LoadAt(filePath, &e.Memory, MaxMemory)
+	if loader.IsELF(filePath) {
+		entry, err := loader.LoadELFAt(filePath, e.Bus)
+		if err != nil {
+			return err
+		}
+		e.Cpu.PC = entry
+
+		e.Symbols, err = loader.ReadELFSymbols(filePath)
+		if err != nil {
+			return err
+		}
+		// riscv-tests report the result through tohost
+		if tohost, ok := e.Symbols.Lookup("tohost"); ok {
+			fromhost, _ := e.Symbols.Lookup("fromhost")
+			e.Htif = NewHTIF(tohost, fromhost, e.Config.Console)
+		}
+		return nil
+	}
+	base := e.loadBase()
+	e.Cpu.PC = base
+	return loader.LoadAt(filePath, e.Bus, base)
 }
 
 func (e *Emulator) LoadString(data string) error {
 	loader := NewLoader()
-	return loader.LoadStringAt(data, &e.Memory, MaxMemory)
+	base := e.loadBase()
+	e.Cpu.PC = base
+	return loader.LoadStringAt(data, e.Bus, base)
 }
 
 func (e *Emulator) Step() error {
@@ -47,8 +201,6 @@ func (e *Emulator) Run() error {
 			return err
 		}
 	}
-
-	return nil
 }
 
 func (e *Emulator) StepUntil(PC uint32) error {
@@ -66,45 +218,109 @@ func (e *Emulator) StepUntil(PC uint32) error {
 	return nil
 }
 
+// Stats has the counters of the emulator
+type Stats struct {
+	Cycles    uint64
+	Instret   uint64
+	TLBHits   uint64
+	TLBMisses uint64
+}
+
+func (e *Emulator) Stats() Stats {
+	return Stats{
+		Cycles:    e.Cpu.Csr.Cycle,
+		Instret:   e.Cpu.Csr.Instret,
+		TLBHits:   e.Cpu.tlb.Hits,
+		TLBMisses: e.Cpu.tlb.Misses,
+	}
+}
+
 func (e *Emulator) Dump() {
 	e.Cpu.DumpRegisters()
 }
 
-func (e *Emulator) WriteU8(addr uint32, data uint8) {
-	e.Memory[addr] = data
+// written is called after the guest memory is written
+func (e *Emulator) written(addr uint32, size uint32) {
+	e.Reservations.Invalidate(addr, size)
+	if e.Htif != nil {
+		e.Htif.watch(addr, size)
+	}
 }
 
-func (e *Emulator) WriteU16(addr uint32, data uint16) {
-	e.Memory[addr] = uint8(data & 0x00FF)
-	addr++
-	e.Memory[addr] = uint8((data & 0xFF00) >> 8)
-	addr++
+func (e *Emulator) WriteU8(addr uint32, data uint8) error {
+	if err := e.Bus.Write(addr, 1, uint32(data)); err != nil {
+		return err
+	}
+	e.written(addr, 1)
+	return nil
 }
 
-func (e *Emulator) WriteU32(addr uint32, data uint32) {
-	e.Memory[addr] = uint8(data & 0x000000FF)
-	addr++
-	e.Memory[addr] = uint8((data & 0x0000FF00) >> 8)
-	addr++
-	e.Memory[addr] = uint8((data & 0x00FF0000) >> 16)
-	addr++
-	e.Memory[addr] = uint8((data & 0xFF000000) >> 24)
+func (e *Emulator) WriteU16(addr uint32, data uint16) error {
+	if err := e.Bus.Write(addr, 2, uint32(data)); err != nil {
+		return err
+	}
+	e.written(addr, 2)
+	return nil
+}
+
+func (e *Emulator) WriteU32(addr uint32, data uint32) error {
+	if err := e.Bus.Write(addr, 4, data); err != nil {
+		return err
+	}
+	e.written(addr, 4)
+	return nil
+}
+
+// ReadBytes reads size bytes at addr. The range is checked before the
+// buffer is allocated, as the size can come from the guest.
+func (e *Emulator) ReadBytes(addr uint32, size uint32) ([]byte, error) {
+	if !e.Bus.IsMapped(addr, size) {
+		return nil, unmapped(addr, size)
+	}
+	data := make([]byte, size)
+	if err := e.Bus.ReadBytes(addr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
-func (e *Emulator) ReadU8(addr uint32) uint8 {
-	return e.Memory[addr]
+func (e *Emulator) WriteBytes(addr uint32, data []byte) error {
+	if err := e.Bus.WriteBytes(addr, data); err != nil {
+		return err
+	}
+	e.written(addr, uint32(len(data)))
+	return nil
 }
 
-func (e *Emulator) ReadU16(addr uint32) uint16 {
-	var data uint16
-	data = uint16(e.Memory[addr]) | uint16(e.Memory[addr+1])<<8
+// ReadCString reads a NUL terminated string up to maxLen bytes
+func (e *Emulator) ReadCString(addr uint32, maxLen uint32) (string, error) {
+	var s []byte
+	for i := uint32(0); i < maxLen; i++ {
+		if uint64(addr)+uint64(i) > 0xffffffff {
+			return "", fmt.Errorf("0x%08x is out of memory", uint64(addr)+uint64(i))
+		}
+		ch, err := e.ReadU8(addr + i)
+		if err != nil {
+			return "", err
+		}
+		if ch == 0 {
+			return string(s), nil
+		}
+		s = append(s, ch)
+	}
+	return "", fmt.Errorf("string at 0x%08x is longer than %d", addr, maxLen)
+}
 
-	return data
+func (e *Emulator) ReadU8(addr uint32) (uint8, error) {
+	data, err := e.Bus.Read(addr, 1)
+	return uint8(data), err
 }
 
-func (e *Emulator) ReadU32(addr uint32) uint32 {
-	var data uint32
-	data = uint32(e.Memory[addr]) | uint32(e.Memory[addr+1])<<8 | uint32(e.Memory[addr+2])<<16 | uint32(e.Memory[addr+3])<<24
+func (e *Emulator) ReadU16(addr uint32) (uint16, error) {
+	data, err := e.Bus.Read(addr, 2)
+	return uint16(data), err
+}
 
-	return data
+func (e *Emulator) ReadU32(addr uint32) (uint32, error) {
+	return e.Bus.Read(addr, 4)
 }
@@ -85,7 +85,7 @@ func Test_Step3(t *testing.T) {
 	// 'ra' must be the return address of the callee (<boot>)
 	// which is 0x1c (next IP of 18: ef 00 40 04  ▸jal▸0x5c <riscv32_boot>
 	wantu8 = uint8(0x1c)
-	gotu8 = e.Memory[0x4ffc]
+	gotu8, _ = e.ReadU8(0x4ffc)
 	if gotu8 != wantu8 {
 		t.Errorf("0x4ffc must be 0x%02x, but was 0x%02x", wantu8, gotu8)
 	}
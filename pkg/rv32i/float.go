@@ -0,0 +1,773 @@
+package rv32i
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// FP Register ABIName Description
+// -----------------------------------------------
+// f0–7        ft0–7   Temporaries
+// f8–9        fs0–1   Saved registers
+// f10–11      fa0–1   Arguments/return values
+// f12–17      fa2–7   Arguments
+// f18–27      fs2–11  Saved registers
+// f28–31      ft8–11  Temporaries
+
+var FRegs = map[string]int{}
+
+var FRegsR = map[uint8]string{}
+
+func init() {
+	abi := []string{
+		"ft0", "ft1", "ft2", "ft3", "ft4", "ft5", "ft6", "ft7",
+		"fs0", "fs1", "fa0", "fa1", "fa2", "fa3", "fa4", "fa5",
+		"fa6", "fa7", "fs2", "fs3", "fs4", "fs5", "fs6", "fs7",
+		"fs8", "fs9", "fs10", "fs11", "ft8", "ft9", "ft10", "ft11",
+	}
+	for idx, name := range abi {
+		FRegs[name] = idx
+		FRegs[fmt.Sprintf("f%d", idx)] = idx
+		FRegsR[uint8(idx)] = name
+	}
+}
+
+func FRegName(i uint8) string {
+	return FRegsR[i]
+}
+
+// fflags
+const (
+	FflagNX = uint32(1 << 0) // inexact
+	FflagUF = uint32(1 << 1) // underflow
+	FflagOF = uint32(1 << 2) // overflow
+	FflagDZ = uint32(1 << 3) // divide by zero
+	FflagNV = uint32(1 << 4) // invalid operation
+)
+
+// rounding modes of frm and the rm field
+const (
+	RmRNE = uint32(0b000)
+	RmRTZ = uint32(0b001)
+	RmRDN = uint32(0b010)
+	RmRUP = uint32(0b011)
+	RmRMM = uint32(0b100)
+	RmDYN = uint32(0b111)
+)
+
+var rmNames = []string{"rne", "rtz", "rdn", "rup", "rmm", "", "", "dyn"}
+
+// RmName returns the assembler name of the rounding mode
+func RmName(rm uint32) string {
+	return rmNames[rm&0b111]
+}
+
+var rmModes = []big.RoundingMode{big.ToNearestEven, big.ToZero, big.ToNegativeInf, big.ToPositiveInf, big.ToNearestAway}
+
+// fpFormat is an IEEE 754 binary format. Single precision values are kept
+// in the lower half of uint64.
+type fpFormat struct {
+	prec int    // significand bits including the hidden bit
+	emin int    // exponent of the smallest normal number
+	emax int    // exponent of the largest finite number
+	bits int    // storage width
+	qnan uint64 // canonical NaN
+}
+
+var (
+	fpSingle = &fpFormat{prec: 24, emin: -126, emax: 127, bits: 32, qnan: 0x7fc00000}
+	fpDouble = &fpFormat{prec: 53, emin: -1022, emax: 1023, bits: 64, qnan: 0x7ff80000_00000000}
+)
+
+func (f *fpFormat) decode(bits uint64) float64 {
+	if f.bits == 32 {
+		return float64(math.Float32frombits(uint32(bits)))
+	}
+	return math.Float64frombits(bits)
+}
+
+// encode returns the bits of v. v must be representable in the format.
+func (f *fpFormat) encode(v float64) uint64 {
+	if math.IsNaN(v) {
+		return f.qnan
+	}
+	if f.bits == 32 {
+		return uint64(math.Float32bits(float32(v)))
+	}
+	return math.Float64bits(v)
+}
+
+func (f *fpFormat) signBit() uint64 {
+	return 1 << (f.bits - 1)
+}
+
+func (f *fpFormat) isNaN(bits uint64) bool {
+	return math.IsNaN(f.decode(bits))
+}
+
+// isSNaN returns true for signaling NaNs whose quiet bit is 0
+func (f *fpFormat) isSNaN(bits uint64) bool {
+	return f.isNaN(bits) && bits&(1<<(f.prec-2)) == 0
+}
+
+// nanFlags returns NV if any of the operands is a signaling NaN
+func (f *fpFormat) nanFlags(operands ...uint64) uint32 {
+	for _, bits := range operands {
+		if f.isSNaN(bits) {
+			return FflagNV
+		}
+	}
+	return 0
+}
+
+// zero returns a signed zero
+func (f *fpFormat) zero(neg bool) uint64 {
+	if neg {
+		return f.signBit()
+	}
+	return 0
+}
+
+func (f *fpFormat) inf(neg bool) uint64 {
+	return f.encode(math.Inf(1)) | f.zero(neg)
+}
+
+// fpNumber is an exact finite value (-1)^neg * m * 2^e
+type fpNumber struct {
+	neg bool
+	m   *big.Int
+	e   int
+}
+
+func unpack(v float64) fpNumber {
+	frac, exp := math.Frexp(math.Abs(v))
+	return fpNumber{
+		neg: math.Signbit(v),
+		m:   new(big.Int).SetUint64(uint64(frac * (1 << 53))),
+		e:   exp - 53,
+	}
+}
+
+// add returns x + y exactly
+func (x fpNumber) add(y fpNumber) fpNumber {
+	e := x.e
+	if y.e < e {
+		e = y.e
+	}
+	mx := new(big.Int).Lsh(x.m, uint(x.e-e))
+	my := new(big.Int).Lsh(y.m, uint(y.e-e))
+	if x.neg {
+		mx.Neg(mx)
+	}
+	if y.neg {
+		my.Neg(my)
+	}
+	m := mx.Add(mx, my)
+	return fpNumber{neg: m.Sign() < 0, m: m.Abs(m), e: e}
+}
+
+// mul returns x * y exactly
+func (x fpNumber) mul(y fpNumber) fpNumber {
+	return fpNumber{neg: x.neg != y.neg, m: new(big.Int).Mul(x.m, y.m), e: x.e + y.e}
+}
+
+// round rounds x to the format. inexact tells that the true value is
+// slightly larger than x, and x must have 2 or more extra bits in that case.
+func (f *fpFormat) round(x fpNumber, inexact bool, rm uint32) (uint64, uint32) {
+	mode := rmModes[rm]
+	m, e := x.m, x.e
+	if inexact {
+		// sticky bit
+		m = new(big.Int).Lsh(m, 1)
+		m.SetBit(m, 0, 1)
+		e--
+	}
+	v := new(big.Float).SetInt(m)
+	v.SetMantExp(v, e)
+	if x.neg {
+		v.Neg(v)
+	}
+
+	// round to prec bits with an unbounded exponent
+	r := new(big.Float).SetMode(mode).SetPrec(uint(f.prec)).Set(v)
+	exp := r.MantExp(nil) - 1
+
+	if exp > f.emax {
+		flags := FflagOF | FflagNX
+		toInf := mode == big.ToNearestEven || mode == big.ToNearestAway ||
+			(mode == big.ToPositiveInf && !x.neg) || (mode == big.ToNegativeInf && x.neg)
+		if toInf {
+			return f.inf(x.neg), flags
+		}
+		// largest finite number
+		return f.inf(x.neg) - 1, flags
+	}
+
+	if exp >= f.emin {
+		var flags uint32
+		if r.Acc() != big.Exact || inexact {
+			flags |= FflagNX
+		}
+		val, _ := r.Float64()
+		return f.encode(val), flags
+	}
+
+	// tiny after rounding, round to a multiple of the smallest subnormal
+	scale := f.emin - f.prec + 1
+	s := new(big.Float).SetMantExp(v, -scale)
+	n, exact := roundInt(s, mode)
+	var flags uint32
+	if !exact || inexact {
+		flags |= FflagUF | FflagNX
+	}
+	val, _ := n.SetMantExp(n, scale).Float64()
+	if val == 0 {
+		return f.zero(x.neg), flags
+	}
+	return f.encode(val), flags
+}
+
+// roundInt rounds v to an integer. It returns false if v isn't an integer.
+func roundInt(v *big.Float, mode big.RoundingMode) (*big.Float, bool) {
+	if v.IsInt() {
+		return v, true
+	}
+
+	exp := v.MantExp(nil)
+	if exp > 0 {
+		return new(big.Float).SetMode(mode).SetPrec(uint(exp)).Set(v), false
+	}
+
+	// 0 < |v| < 1
+	neg := v.Signbit()
+	cmp := new(big.Float).Abs(v).Cmp(big.NewFloat(0.5))
+	one := false
+	switch mode {
+	case big.ToNearestEven:
+		one = cmp > 0
+	case big.ToNearestAway:
+		one = cmp >= 0
+	case big.ToNegativeInf:
+		one = neg
+	case big.ToPositiveInf:
+		one = !neg
+	}
+	r := new(big.Float)
+	if one {
+		r.SetInt64(1)
+	}
+	if neg {
+		r.Neg(r)
+	}
+	return r, false
+}
+
+// addZero returns the sign of the exact zero sum
+func addZero(rm uint32) bool {
+	return rm == RmRDN
+}
+
+func (f *fpFormat) add(a, b uint64, rm uint32) (uint64, uint32) {
+	x, y := f.decode(a), f.decode(b)
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return f.qnan, f.nanFlags(a, b)
+	case math.IsInf(x, 0) && math.IsInf(y, 0) && math.Signbit(x) != math.Signbit(y):
+		return f.qnan, FflagNV
+	case math.IsInf(x, 0):
+		return a, 0
+	case math.IsInf(y, 0):
+		return b, 0
+	case x == 0 && y == 0:
+		if math.Signbit(x) == math.Signbit(y) {
+			return a, 0
+		}
+		return f.zero(addZero(rm)), 0
+	case x == 0:
+		return b, 0
+	case y == 0:
+		return a, 0
+	}
+
+	sum := unpack(x).add(unpack(y))
+	if sum.m.Sign() == 0 {
+		return f.zero(addZero(rm)), 0
+	}
+	return f.round(sum, false, rm)
+}
+
+func (f *fpFormat) sub(a, b uint64, rm uint32) (uint64, uint32) {
+	return f.add(a, b^f.signBit(), rm)
+}
+
+func (f *fpFormat) mul(a, b uint64, rm uint32) (uint64, uint32) {
+	x, y := f.decode(a), f.decode(b)
+	neg := math.Signbit(x) != math.Signbit(y)
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return f.qnan, f.nanFlags(a, b)
+	case math.IsInf(x, 0) && y == 0, x == 0 && math.IsInf(y, 0):
+		return f.qnan, FflagNV
+	case math.IsInf(x, 0) || math.IsInf(y, 0):
+		return f.inf(neg), 0
+	case x == 0 || y == 0:
+		return f.zero(neg), 0
+	}
+	return f.round(unpack(x).mul(unpack(y)), false, rm)
+}
+
+func (f *fpFormat) div(a, b uint64, rm uint32) (uint64, uint32) {
+	x, y := f.decode(a), f.decode(b)
+	neg := math.Signbit(x) != math.Signbit(y)
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return f.qnan, f.nanFlags(a, b)
+	case math.IsInf(x, 0) && math.IsInf(y, 0), x == 0 && y == 0:
+		return f.qnan, FflagNV
+	case math.IsInf(x, 0):
+		return f.inf(neg), 0
+	case y == 0:
+		return f.inf(neg), FflagDZ
+	case x == 0 || math.IsInf(y, 0):
+		return f.zero(neg), 0
+	}
+
+	// quotient with enough extra bits for rounding
+	nx, ny := unpack(x), unpack(y)
+	shift := 2 * (f.prec + 3)
+	q, r := new(big.Int).QuoRem(new(big.Int).Lsh(nx.m, uint(shift)), ny.m, new(big.Int))
+	return f.round(fpNumber{neg: neg, m: q, e: nx.e - ny.e - shift}, r.Sign() != 0, rm)
+}
+
+func (f *fpFormat) sqrt(a uint64, rm uint32) (uint64, uint32) {
+	x := f.decode(a)
+	switch {
+	case math.IsNaN(x):
+		return f.qnan, f.nanFlags(a)
+	case x == 0:
+		return a, 0
+	case math.Signbit(x):
+		return f.qnan, FflagNV
+	case math.IsInf(x, 0):
+		return a, 0
+	}
+
+	n := unpack(x)
+	if n.e%2 != 0 {
+		n.m.Lsh(n.m, 1)
+		n.e--
+	}
+	shift := 2 * (f.prec + 3)
+	m := new(big.Int).Lsh(n.m, uint(shift))
+	s := new(big.Int).Sqrt(m)
+	inexact := new(big.Int).Mul(s, s).Cmp(m) != 0
+	return f.round(fpNumber{m: s, e: (n.e - shift) / 2}, inexact, rm)
+}
+
+// fma returns (-1)^negProd * a * b + (-1)^negC * c with a single rounding
+func (f *fpFormat) fma(a, b, c uint64, negProd, negC bool, rm uint32) (uint64, uint32) {
+	x, y, z := f.decode(a), f.decode(b), f.decode(c)
+	prodNeg := (math.Signbit(x) != math.Signbit(y)) != negProd
+	zNeg := math.Signbit(z) != negC
+	prodInf := math.IsInf(x, 0) || math.IsInf(y, 0)
+
+	switch {
+	case math.IsInf(x, 0) && y == 0, x == 0 && math.IsInf(y, 0):
+		// invalid even if c is a quiet NaN
+		return f.qnan, FflagNV
+	case math.IsNaN(x) || math.IsNaN(y) || math.IsNaN(z):
+		return f.qnan, f.nanFlags(a, b, c)
+	case prodInf && math.IsInf(z, 0) && prodNeg != zNeg:
+		return f.qnan, FflagNV
+	case prodInf:
+		return f.inf(prodNeg), 0
+	case math.IsInf(z, 0):
+		return f.inf(zNeg), 0
+	case x == 0 || y == 0:
+		if z == 0 {
+			if prodNeg == zNeg {
+				return f.zero(zNeg), 0
+			}
+			return f.zero(addZero(rm)), 0
+		}
+		return f.encode(z) ^ f.zero(negC), 0
+	case z == 0:
+		return f.round(unpack(x).mul(unpack(y)).negate(negProd), false, rm)
+	}
+
+	nz := unpack(z)
+	nz.neg = zNeg
+	sum := unpack(x).mul(unpack(y)).negate(negProd).add(nz)
+	if sum.m.Sign() == 0 {
+		return f.zero(addZero(rm)), 0
+	}
+	return f.round(sum, false, rm)
+}
+
+func (x fpNumber) negate(neg bool) fpNumber {
+	x.neg = x.neg != neg
+	return x
+}
+
+// minMax returns min(a, b), or max(a, b) if max is true
+func (f *fpFormat) minMax(a, b uint64, max bool) (uint64, uint32) {
+	x, y := f.decode(a), f.decode(b)
+	flags := f.nanFlags(a, b)
+	switch {
+	case math.IsNaN(x) && math.IsNaN(y):
+		return f.qnan, flags
+	case math.IsNaN(x):
+		return b, flags
+	case math.IsNaN(y):
+		return a, flags
+	}
+	// -0 is smaller than +0
+	less := x < y || (x == y && math.Signbit(x) && !math.Signbit(y))
+	if less != max {
+		return a, 0
+	}
+	return b, 0
+}
+
+// compare returns feq, flt or fle of a and b
+func (f *fpFormat) compare(op OpName, a, b uint64) (uint32, uint32) {
+	x, y := f.decode(a), f.decode(b)
+	if math.IsNaN(x) || math.IsNaN(y) {
+		if op == OpFeqS || op == OpFeqD {
+			return 0, f.nanFlags(a, b)
+		}
+		return 0, FflagNV
+	}
+
+	var result bool
+	switch op {
+	case OpFeqS, OpFeqD:
+		result = x == y
+	case OpFltS, OpFltD:
+		result = x < y
+	default:
+		result = x <= y
+	}
+	if result {
+		return 1, 0
+	}
+	return 0, 0
+}
+
+// class returns the fclass mask
+func (f *fpFormat) class(a uint64) uint32 {
+	x := f.decode(a)
+	neg := math.Signbit(x)
+	smallest := math.Ldexp(1, f.emin)
+
+	var bit uint
+	switch {
+	case f.isSNaN(a):
+		return 1 << 8
+	case math.IsNaN(x):
+		return 1 << 9
+	case math.IsInf(x, 0):
+		bit = 0
+	case x == 0:
+		bit = 3
+	case math.Abs(x) < smallest:
+		bit = 2
+	default:
+		bit = 1
+	}
+	if !neg {
+		bit = 7 - bit
+	}
+	return 1 << bit
+}
+
+// toInt converts a to a 32-bit integer. Out of range values are saturated
+// and raise NV.
+func (f *fpFormat) toInt(a uint64, unsigned bool, rm uint32) (uint32, uint32) {
+	minV, maxV := big.NewFloat(math.MinInt32), big.NewFloat(math.MaxInt32)
+	minR, maxR := uint32(math.MaxInt32+1), uint32(math.MaxInt32)
+	if unsigned {
+		minV, maxV = big.NewFloat(0), big.NewFloat(math.MaxUint32)
+		minR, maxR = 0, math.MaxUint32
+	}
+
+	x := f.decode(a)
+	switch {
+	case math.IsNaN(x):
+		return maxR, FflagNV
+	case math.IsInf(x, 1):
+		return maxR, FflagNV
+	case math.IsInf(x, -1):
+		return minR, FflagNV
+	}
+
+	n, exact := roundInt(big.NewFloat(x), rmModes[rm])
+	if n.Cmp(minV) < 0 {
+		return minR, FflagNV
+	}
+	if n.Cmp(maxV) > 0 {
+		return maxR, FflagNV
+	}
+
+	var flags uint32
+	if !exact {
+		flags = FflagNX
+	}
+	i, _ := n.Int64()
+	return uint32(i), flags
+}
+
+// fromInt converts a 32-bit integer
+func (f *fpFormat) fromInt(i uint32, unsigned bool, rm uint32) (uint64, uint32) {
+	v := int64(i)
+	if !unsigned {
+		v = int64(int32(i))
+	}
+	if v == 0 {
+		return 0, 0
+	}
+	n := fpNumber{neg: v < 0, m: big.NewInt(v), e: 0}
+	n.m.Abs(n.m)
+	return f.round(n, false, rm)
+}
+
+// convert converts a in the format from to f
+func (f *fpFormat) convert(from *fpFormat, a uint64, rm uint32) (uint64, uint32) {
+	x := from.decode(a)
+	switch {
+	case math.IsNaN(x):
+		return f.qnan, from.nanFlags(a)
+	case math.IsInf(x, 0) || x == 0:
+		return f.encode(x), 0
+	}
+	return f.round(unpack(x), false, rm)
+}
+
+// sgnj returns the sign injection of fsgnj, fsgnjn and fsgnjx
+func (f *fpFormat) sgnj(a, b uint64, funct3 uint8) uint64 {
+	sign := f.signBit()
+	switch funct3 {
+	case 0b000:
+		return a&^sign | b&sign
+	case 0b001:
+		return a&^sign | ^b&sign
+	default:
+		return a ^ b&sign
+	}
+}
+
+// isFpEnabled returns false if mstatus.FS is off
+func (c *Cpu) isFpEnabled() bool {
+	return c.Csr.Mstatus&MstatusFS != 0
+}
+
+// setFpDirty marks the FP state dirty
+func (c *Cpu) setFpDirty() {
+	if c.Csr.Mstatus&MstatusFS != MstatusFS {
+		c.Csr.Mstatus |= MstatusFS
+		c.traceCsr(CsrMstatus)
+	}
+}
+
+// readF returns the FP register. Single precision values which are not
+// NaN-boxed are read as the canonical NaN.
+func (c *Cpu) readF(f *fpFormat, r uint8) uint64 {
+	if f == fpDouble {
+		return c.F[r]
+	}
+	if c.F[r]>>32 != 0xffffffff {
+		return f.qnan
+	}
+	return c.F[r] & 0xffffffff
+}
+
+// writeF writes the FP register with NaN-boxing for single precision
+func (c *Cpu) writeF(f *fpFormat, r uint8, data uint64) {
+	if f == fpSingle {
+		data = 0xffffffff_00000000 | data&0xffffffff
+	}
+	c.F[r] = data
+	c.traceF(r)
+	c.setFpDirty()
+}
+
+func (c *Cpu) setFflags(flags uint32) {
+	if flags != 0 {
+		c.Csr.Fcsr |= flags
+		c.traceCsr(CsrFflags)
+		c.setFpDirty()
+	}
+}
+
+// roundingMode returns the rounding mode of the instruction. It returns
+// false for the reserved modes.
+func (c *Cpu) roundingMode(i *Instruction) (uint32, bool) {
+	rm := uint32(i.Funct3)
+	if rm == RmDYN {
+		rm = c.Csr.Fcsr >> 5 & 0b111
+	}
+	return rm, rm <= RmRMM
+}
+
+// executeFloat runs RV32F and RV32D instructions
+func (c *Cpu) executeFloat(op OpName, i *Instruction) {
+	if !c.isFpEnabled() {
+		c.raise(CauseIllegalInstruction, c.raw)
+		return
+	}
+
+	f := fpSingle
+	if op >= OpFld {
+		f = fpDouble
+	}
+
+	rm, ok := c.roundingMode(i)
+	if !ok {
+		if usesRm(op) {
+			c.raise(CauseIllegalInstruction, c.raw)
+			return
+		}
+		// exact operations ignore rm
+		rm = RmRNE
+	}
+
+	var data uint64
+	var flags uint32
+	a, b := c.readF(f, i.Rs1), c.readF(f, i.Rs2)
+
+	switch op {
+	case OpFlw:
+		v, ok := c.load(c.X[i.Rs1]+i.Imm, 4)
+		if ok {
+			c.writeF(f, i.Rd, uint64(v))
+		}
+		return
+	case OpFld:
+		addr := c.X[i.Rs1] + i.Imm
+		if addr%8 != 0 {
+			c.raise(CauseLoadAddressMisaligned, addr)
+			return
+		}
+		lo, ok := c.load(addr, 4)
+		if !ok {
+			return
+		}
+		hi, ok := c.load(addr+4, 4)
+		if ok {
+			c.writeF(f, i.Rd, uint64(hi)<<32|uint64(lo))
+		}
+		return
+	case OpFsw:
+		c.store(c.X[i.Rs1]+i.Imm, 4, uint32(c.F[i.Rs2]))
+		return
+	case OpFsd:
+		addr := c.X[i.Rs1] + i.Imm
+		if addr%8 != 0 {
+			c.raise(CauseStoreAddressMisaligned, addr)
+			return
+		}
+		if c.store(addr, 4, uint32(c.F[i.Rs2])) {
+			c.store(addr+4, 4, uint32(c.F[i.Rs2]>>32))
+		}
+		return
+	case OpFmaddS, OpFmaddD:
+		data, flags = f.fma(a, b, c.readF(f, i.Rs3()), false, false, rm)
+	case OpFmsubS, OpFmsubD:
+		data, flags = f.fma(a, b, c.readF(f, i.Rs3()), false, true, rm)
+	case OpFnmsubS, OpFnmsubD:
+		data, flags = f.fma(a, b, c.readF(f, i.Rs3()), true, false, rm)
+	case OpFnmaddS, OpFnmaddD:
+		data, flags = f.fma(a, b, c.readF(f, i.Rs3()), true, true, rm)
+	case OpFaddS, OpFaddD:
+		data, flags = f.add(a, b, rm)
+	case OpFsubS, OpFsubD:
+		data, flags = f.sub(a, b, rm)
+	case OpFmulS, OpFmulD:
+		data, flags = f.mul(a, b, rm)
+	case OpFdivS, OpFdivD:
+		data, flags = f.div(a, b, rm)
+	case OpFsqrtS, OpFsqrtD:
+		data, flags = f.sqrt(a, rm)
+	case OpFsgnjS, OpFsgnjnS, OpFsgnjxS, OpFsgnjD, OpFsgnjnD, OpFsgnjxD:
+		data = f.sgnj(a, b, i.Funct3)
+	case OpFminS, OpFminD:
+		data, flags = f.minMax(a, b, false)
+	case OpFmaxS, OpFmaxD:
+		data, flags = f.minMax(a, b, true)
+	case OpFcvtSD:
+		data, flags = fpSingle.convert(fpDouble, c.readF(fpDouble, i.Rs1), rm)
+		f = fpSingle
+	case OpFcvtDS:
+		data, flags = fpDouble.convert(fpSingle, c.readF(fpSingle, i.Rs1), rm)
+	case OpFcvtSW, OpFcvtSWu, OpFcvtDW, OpFcvtDWu:
+		data, flags = f.fromInt(c.X[i.Rs1], op == OpFcvtSWu || op == OpFcvtDWu, rm)
+	case OpFmvWX:
+		data = uint64(c.X[i.Rs1])
+	default:
+		// the results are written to x registers
+		var x uint32
+		switch op {
+		case OpFcvtWS, OpFcvtWuS, OpFcvtWD, OpFcvtWuD:
+			x, flags = f.toInt(a, op == OpFcvtWuS || op == OpFcvtWuD, rm)
+		case OpFmvXW:
+			x = uint32(c.F[i.Rs1])
+		case OpFclassS, OpFclassD:
+			x = f.class(a)
+		default:
+			// feq, flt, fle
+			x, flags = f.compare(op, a, b)
+		}
+		c.setFflags(flags)
+		if i.Rd > 0 {
+			c.X[i.Rd] = x
+		}
+		return
+	}
+
+	c.setFflags(flags)
+	c.writeF(f, i.Rd, data)
+}
+
+// usesRm returns true if the instruction rounds with the rm field
+func usesRm(op OpName) bool {
+	switch op {
+	case OpFmaddS, OpFmsubS, OpFnmsubS, OpFnmaddS, OpFaddS, OpFsubS, OpFmulS, OpFdivS, OpFsqrtS,
+		OpFcvtWS, OpFcvtWuS, OpFcvtSW, OpFcvtSWu,
+		OpFmaddD, OpFmsubD, OpFnmsubD, OpFnmaddD, OpFaddD, OpFsubD, OpFmulD, OpFdivD, OpFsqrtD,
+		OpFcvtSD, OpFcvtWD, OpFcvtWuD:
+		return true
+	default:
+		return false
+	}
+}
+
+// getFPCodeString disassembles RV32F and RV32D instructions. The rounding
+// mode is shown unless it's dynamic.
+func (i *Instruction) getFPCodeString() string {
+	op := i.GetOpName()
+	name := op.Mnemonic()
+	rm := ""
+	if usesRm(op) && uint32(i.Funct3) != RmDYN {
+		rm = ", " + RmName(uint32(i.Funct3))
+	}
+
+	switch op {
+	case OpFlw, OpFld:
+		return fmt.Sprintf("%s %s, %d(%s)", name, FRegName(i.Rd), InterpretSingnedUint32(i.Imm), RegName(i.Rs1))
+	case OpFsw, OpFsd:
+		return fmt.Sprintf("%s %s, %d(%s)", name, FRegName(i.Rs2), InterpretSingnedUint32(i.Imm), RegName(i.Rs1))
+	case OpFmaddS, OpFmsubS, OpFnmsubS, OpFnmaddS, OpFmaddD, OpFmsubD, OpFnmsubD, OpFnmaddD:
+		return fmt.Sprintf("%s %s, %s, %s, %s%s", name, FRegName(i.Rd), FRegName(i.Rs1), FRegName(i.Rs2), FRegName(i.Rs3()), rm)
+	case OpFsqrtS, OpFsqrtD, OpFcvtSD, OpFcvtDS:
+		return fmt.Sprintf("%s %s, %s%s", name, FRegName(i.Rd), FRegName(i.Rs1), rm)
+	case OpFcvtWS, OpFcvtWuS, OpFcvtWD, OpFcvtWuD, OpFmvXW, OpFclassS, OpFclassD:
+		return fmt.Sprintf("%s %s, %s%s", name, RegName(i.Rd), FRegName(i.Rs1), rm)
+	case OpFcvtSW, OpFcvtSWu, OpFcvtDW, OpFcvtDWu, OpFmvWX:
+		return fmt.Sprintf("%s %s, %s%s", name, FRegName(i.Rd), RegName(i.Rs1), rm)
+	case OpFeqS, OpFltS, OpFleS, OpFeqD, OpFltD, OpFleD:
+		return fmt.Sprintf("%s %s, %s, %s", name, RegName(i.Rd), FRegName(i.Rs1), FRegName(i.Rs2))
+	default:
+		return fmt.Sprintf("%s %s, %s, %s%s", name, FRegName(i.Rd), FRegName(i.Rs1), FRegName(i.Rs2), rm)
+	}
+}
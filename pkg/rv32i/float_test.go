@@ -0,0 +1,219 @@
+package rv32i
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// s returns a NaN-boxed single precision value
+func s(f float32) uint64 {
+	return 0xffffffff_00000000 | uint64(math.Float32bits(f))
+}
+
+func d(f float64) uint64 {
+	return math.Float64bits(f)
+}
+
+// withRm replaces the rm field of the instruction
+func withRm(code uint32, rm uint32) uint32 {
+	return code&^(0b111<<12) | rm<<12
+}
+
+func Test_ExecuteFP(t *testing.T) {
+	type TestData struct {
+		Name  string
+		Code  uint32 // rd: 10, rs1: 11, rs2: 12, rs3: 13
+		F     [3]uint64
+		X     uint32 // x11
+		WantF uint64
+		WantX uint32
+		ToX   bool // the result is written to x10
+		Flags uint32
+	}
+
+	maxS := float32(math.MaxFloat32)
+	minSubS := math.Float32frombits(1)
+	qnanS := uint64(0xffffffff_7fc00000)
+	snanS := uint64(0xffffffff_7f800001)
+	qnanD := uint64(0x7ff80000_00000000)
+
+	for _, td := range []TestData{
+		{Name: "fadd.s", Code: GenCode(OpFaddS, 10, 11, 12), F: [3]uint64{s(1), s(2)}, WantF: s(3)},
+		{Name: "fadd.s inexact", Code: withRm(GenCode(OpFaddS, 10, 11, 12), RmRNE), F: [3]uint64{s(1), s(0x1p-30)}, WantF: s(1), Flags: FflagNX},
+		{Name: "fadd.s rup", Code: withRm(GenCode(OpFaddS, 10, 11, 12), RmRUP), F: [3]uint64{s(1), s(0x1p-30)}, WantF: s(1 + 0x1p-23), Flags: FflagNX},
+		{Name: "fsub.d zero", Code: withRm(GenCode(OpFsubD, 10, 11, 12), RmRNE), F: [3]uint64{d(1), d(1)}, WantF: d(0)},
+		{Name: "fsub.d zero rdn", Code: withRm(GenCode(OpFsubD, 10, 11, 12), RmRDN), F: [3]uint64{d(1), d(1)}, WantF: d(math.Copysign(0, -1))},
+		{Name: "fmul.s overflow", Code: GenCode(OpFmulS, 10, 11, 12), F: [3]uint64{s(maxS), s(2)}, WantF: s(float32(math.Inf(1))), Flags: FflagOF | FflagNX},
+		{Name: "fmul.s overflow rtz", Code: withRm(GenCode(OpFmulS, 10, 11, 12), RmRTZ), F: [3]uint64{s(maxS), s(2)}, WantF: s(maxS), Flags: FflagOF | FflagNX},
+		{Name: "fmul.s underflow", Code: withRm(GenCode(OpFmulS, 10, 11, 12), RmRNE), F: [3]uint64{s(minSubS), s(0.5)}, WantF: s(0), Flags: FflagUF | FflagNX},
+		{Name: "fmul.s underflow rup", Code: withRm(GenCode(OpFmulS, 10, 11, 12), RmRUP), F: [3]uint64{s(minSubS), s(0.5)}, WantF: s(minSubS), Flags: FflagUF | FflagNX},
+		{Name: "fmul.s subnormal exact", Code: GenCode(OpFmulS, 10, 11, 12), F: [3]uint64{s(minSubS), s(2)}, WantF: s(2 * minSubS)},
+		{Name: "fdiv.d", Code: GenCode(OpFdivD, 10, 11, 12), F: [3]uint64{d(1), d(3)}, WantF: d(1.0 / 3), Flags: FflagNX},
+		{Name: "fdiv.d by zero", Code: GenCode(OpFdivD, 10, 11, 12), F: [3]uint64{d(-1), d(0)}, WantF: d(math.Inf(-1)), Flags: FflagDZ},
+		{Name: "fdiv.d 0/0", Code: GenCode(OpFdivD, 10, 11, 12), F: [3]uint64{d(0), d(0)}, WantF: qnanD, Flags: FflagNV},
+		{Name: "fsqrt.d", Code: GenCode(OpFsqrtD, 10, 11, 0), F: [3]uint64{d(2)}, WantF: d(math.Sqrt2), Flags: FflagNX},
+		{Name: "fsqrt.s exact", Code: GenCode(OpFsqrtS, 10, 11, 0), F: [3]uint64{s(4)}, WantF: s(2)},
+		{Name: "fsqrt.s negative", Code: GenCode(OpFsqrtS, 10, 11, 0), F: [3]uint64{s(-1)}, WantF: qnanS, Flags: FflagNV},
+		{Name: "fmadd.d", Code: GenCodeR4(OpFmaddD, 10, 11, 12, 13), F: [3]uint64{d(2), d(3), d(4)}, WantF: d(10)},
+		{Name: "fnmsub.s", Code: GenCodeR4(OpFnmsubS, 10, 11, 12, 13), F: [3]uint64{s(2), s(3), s(4)}, WantF: s(-2)},
+		{Name: "fmadd.d inf*0+qnan", Code: GenCodeR4(OpFmaddD, 10, 11, 12, 13), F: [3]uint64{d(math.Inf(1)), d(0), qnanD}, WantF: qnanD, Flags: FflagNV},
+		{Name: "fmin.s zeros", Code: GenCode(OpFminS, 10, 11, 12), F: [3]uint64{s(0), s(float32(math.Copysign(0, -1)))}, WantF: s(float32(math.Copysign(0, -1)))},
+		{Name: "fmax.s snan", Code: GenCode(OpFmaxS, 10, 11, 12), F: [3]uint64{snanS, s(1)}, WantF: s(1), Flags: FflagNV},
+		{Name: "fsgnjn.d", Code: GenCode(OpFsgnjnD, 10, 11, 12), F: [3]uint64{d(1), d(1)}, WantF: d(-1)},
+		{Name: "not NaN-boxed", Code: GenCode(OpFaddS, 10, 11, 12), F: [3]uint64{uint64(math.Float32bits(1)), s(1)}, WantF: qnanS},
+		{Name: "fcvt.w.s rne", Code: withRm(GenCode(OpFcvtWS, 10, 11, 0), RmRNE), F: [3]uint64{s(2.5)}, ToX: true, WantX: 2, Flags: FflagNX},
+		{Name: "fcvt.w.s rmm", Code: withRm(GenCode(OpFcvtWS, 10, 11, 0), RmRMM), F: [3]uint64{s(2.5)}, ToX: true, WantX: 3, Flags: FflagNX},
+		{Name: "fcvt.w.d rdn", Code: withRm(GenCode(OpFcvtWD, 10, 11, 0), RmRDN), F: [3]uint64{d(-2.5)}, ToX: true, WantX: 0xfffffffd, Flags: FflagNX},
+		{Name: "fcvt.w.s NaN", Code: GenCode(OpFcvtWS, 10, 11, 0), F: [3]uint64{qnanS}, ToX: true, WantX: 0x7fffffff, Flags: FflagNV},
+		{Name: "fcvt.w.d overflow", Code: GenCode(OpFcvtWD, 10, 11, 0), F: [3]uint64{d(-3e9)}, ToX: true, WantX: 0x80000000, Flags: FflagNV},
+		{Name: "fcvt.wu.s negative", Code: GenCode(OpFcvtWuS, 10, 11, 0), F: [3]uint64{s(-1)}, ToX: true, WantX: 0, Flags: FflagNV},
+		{Name: "fcvt.wu.s -0.5 rtz", Code: withRm(GenCode(OpFcvtWuS, 10, 11, 0), RmRTZ), F: [3]uint64{s(-0.5)}, ToX: true, WantX: 0, Flags: FflagNX},
+		{Name: "fcvt.s.w", Code: GenCode(OpFcvtSW, 10, 11, 0), X: 16777217, WantF: s(16777216), Flags: FflagNX},
+		{Name: "fcvt.d.wu", Code: GenCode(OpFcvtDWu, 10, 11, 0), X: 0xffffffff, WantF: d(4294967295)},
+		{Name: "fcvt.s.d", Code: GenCode(OpFcvtSD, 10, 11, 0), F: [3]uint64{d(0.1)}, WantF: s(0.1), Flags: FflagNX},
+		{Name: "fcvt.d.s", Code: GenCode(OpFcvtDS, 10, 11, 0), F: [3]uint64{s(0.1)}, WantF: d(float64(float32(0.1)))},
+		{Name: "fmv.x.w", Code: GenCode(OpFmvXW, 10, 11, 0), F: [3]uint64{s(-1)}, ToX: true, WantX: 0xbf800000},
+		{Name: "fmv.w.x", Code: GenCode(OpFmvWX, 10, 11, 0), X: 0x3f800000, WantF: s(1)},
+		{Name: "feq.s qnan", Code: GenCode(OpFeqS, 10, 11, 12), F: [3]uint64{qnanS, s(1)}, ToX: true, WantX: 0},
+		{Name: "flt.s qnan", Code: GenCode(OpFltS, 10, 11, 12), F: [3]uint64{qnanS, s(1)}, ToX: true, WantX: 0, Flags: FflagNV},
+		{Name: "fle.d", Code: GenCode(OpFleD, 10, 11, 12), F: [3]uint64{d(1), d(1)}, ToX: true, WantX: 1},
+		{Name: "fclass.s -inf", Code: GenCode(OpFclassS, 10, 11, 0), F: [3]uint64{s(float32(math.Inf(-1)))}, ToX: true, WantX: 1 << 0},
+		{Name: "fclass.s subnormal", Code: GenCode(OpFclassS, 10, 11, 0), F: [3]uint64{s(minSubS)}, ToX: true, WantX: 1 << 5},
+		{Name: "fclass.d snan", Code: GenCode(OpFclassD, 10, 11, 0), F: [3]uint64{0x7ff00000_00000001}, ToX: true, WantX: 1 << 8},
+		{Name: "fclass.d qnan", Code: GenCode(OpFclassD, 10, 11, 0), F: [3]uint64{qnanD}, ToX: true, WantX: 1 << 9},
+	} {
+		e := NewEmulator()
+		copy(e.Cpu.F[11:], td.F[:])
+		e.Cpu.X[11] = td.X
+		loadCode(e, 0, td.Code)
+
+		if err := e.Step(); err != nil {
+			t.Errorf("%s: %v", td.Name, err)
+			continue
+		}
+		if td.ToX && e.Cpu.X[10] != td.WantX {
+			t.Errorf("%s: x10 must be 0x%08x, but was 0x%08x", td.Name, td.WantX, e.Cpu.X[10])
+		}
+		if !td.ToX && e.Cpu.F[10] != td.WantF {
+			t.Errorf("%s: f10 must be 0x%016x, but was 0x%016x", td.Name, td.WantF, e.Cpu.F[10])
+		}
+		if e.Cpu.Csr.Fcsr != td.Flags {
+			t.Errorf("%s: fflags must be %05b, but was %05b", td.Name, td.Flags, e.Cpu.Csr.Fcsr)
+		}
+	}
+}
+
+func Test_FPLoadStore(t *testing.T) {
+	e := NewEmulator()
+	e.WriteU32(0x100, math.Float32bits(1.5))
+	e.WriteU32(0x108, 0x00000000)
+	e.WriteU32(0x10c, 0x40090000) // 3.125
+	e.Cpu.X[10] = 0x100
+	loadCode(e, 0,
+		GenCode(OpFlw, 1, 0, 10),
+		GenCode(OpFld, 2, 8, 10),
+		GenCode(OpFsw, 1, 16, 10),
+		GenCode(OpFsd, 2, 24, 10),
+		GenCode(OpFld, 3, 4, 10),
+	)
+	for idx := 0; idx < 4; idx++ {
+		if err := e.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e.Cpu.F[1] != s(1.5) || e.Cpu.F[2] != d(3.125) {
+		t.Errorf("Wrong f1 0x%016x, f2 0x%016x", e.Cpu.F[1], e.Cpu.F[2])
+	}
+	lo, _ := e.ReadU32(0x110)
+	hi, _ := e.ReadU32(0x11c)
+	if lo != math.Float32bits(1.5) || hi != 0x40090000 {
+		t.Errorf("Wrong memory 0x%08x, 0x%08x", lo, hi)
+	}
+
+	// fld must be 8-byte aligned
+	err := e.Step()
+	var trap *Trap
+	if !errors.As(err, &trap) || trap.Cause != CauseLoadAddressMisaligned || trap.Tval != 0x104 {
+		t.Errorf("misaligned fld must trap, but was %v", err)
+	}
+}
+
+func Test_Fcsr(t *testing.T) {
+	e := NewEmulator()
+	cpu := e.Cpu
+	exec := func(code uint32) {
+		cpu.raw = code
+		cpu.Execute(NewInstruction(code))
+	}
+
+	// frm is used by the dynamic rounding mode
+	exec(GenCode(OpCsrrwi, 0, int(CsrFrm), int(RmRUP)))
+	cpu.F[11], cpu.F[12] = s(1), s(0x1p-30)
+	exec(GenCode(OpFaddS, 10, 11, 12))
+	if cpu.F[10] != s(1+0x1p-23) {
+		t.Errorf("Wrong f10 0x%016x", cpu.F[10])
+	}
+
+	exec(GenCode(OpCsrrs, 5, int(CsrFcsr), 0))
+	exec(GenCode(OpCsrrs, 6, int(CsrFflags), 0))
+	if cpu.X[5] != RmRUP<<5|FflagNX || cpu.X[6] != FflagNX {
+		t.Errorf("Wrong fcsr 0x%x, fflags 0x%x", cpu.X[5], cpu.X[6])
+	}
+
+	// FS is dirty
+	mstatus, _ := cpu.ReadCsr(CsrMstatus)
+	if mstatus&MstatusFS != FsDirty || mstatus&MstatusSD == 0 {
+		t.Errorf("Wrong mstatus 0x%08x", mstatus)
+	}
+
+	// reserved rounding mode
+	exec(GenCode(OpCsrrwi, 0, int(CsrFrm), 0b101))
+	exec(GenCode(OpFaddS, 10, 11, 12))
+	var trap *Trap
+	if !errors.As(cpu.err, &trap) || trap.Cause != CauseIllegalInstruction {
+		t.Errorf("dynamic rm 0b101 must be illegal, but was %v", cpu.err)
+	}
+	cpu.err = nil
+
+	// FP instructions and CSRs are illegal if FS is off
+	cpu.Csr.Mstatus &^= MstatusFS
+	exec(GenCode(OpFaddS, 10, 11, 12))
+	if !errors.As(cpu.err, &trap) || trap.Cause != CauseIllegalInstruction {
+		t.Errorf("fadd.s must be illegal, but was %v", cpu.err)
+	}
+	if _, ok := cpu.ReadCsr(CsrFcsr); ok {
+		t.Error("fcsr must not be readable")
+	}
+}
+
+func Test_FPCodeString(t *testing.T) {
+	type TestData struct {
+		Code uint32
+		Want string
+	}
+
+	// llvm-mc -triple=riscv32 -mattr=+f,+d -show-encoding
+	for _, td := range []TestData{
+		{0x00c59553, "fadd.s fa0, fa1, fa2, rtz"},
+		{0x00c5f553, "fadd.s fa0, fa1, fa2"},
+		{0x6ac5c543, "fmadd.d fa0, fa1, fa2, fa3, rmm"},
+		{0x011d7fcb, "fnmsub.s ft11, fs10, fa7, ft0"},
+		{0xc0058553, "fcvt.w.s a0, fa1, rne"},
+		{0xd2158553, "fcvt.d.wu fa0, a1"},
+		{0xff852507, "flw fa0, -8(a0)"},
+		{0x7fb13c27, "fsd fs11, 2040(sp)"},
+		{0x22209053, "fsgnjn.d ft0, ft1, ft2"},
+		{0xa2b50553, "fle.d a0, fa0, fa1"},
+		{0xe0051553, "fclass.s a0, fa0"},
+		{0xe0050553, "fmv.x.w a0, fa0"},
+		{0x5805b553, "fsqrt.s fa0, fa1, rup"},
+		{0x4015a553, "fcvt.s.d fa0, fa1, rdn"},
+		{0x28c59553, "fmax.s fa0, fa1, fa2"},
+	} {
+		got := NewInstruction(td.Code).GetCodeString()
+		if got != td.Want {
+			t.Errorf("0x%08x: got %s, want %s", td.Code, got, td.Want)
+		}
+	}
+}
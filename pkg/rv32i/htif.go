@@ -0,0 +1,91 @@
+package rv32i
+
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HTIF devices and commands
+const (
+	htifDeviceSyscall = uint8(0) // cmd 0 with payload bit 0 set is exit
+	htifDeviceConsole = uint8(1)
+
+	htifConsolePutchar = uint8(1)
+)
+
+// HTIF is the host-target interface of Spike used by riscv-tests. The guest
+// writes a command to the 64-bit tohost, and the host clears it and writes
+// the response to fromhost. A command is device (63:56), cmd (55:48) and
+// payload (47:0).
+type HTIF struct {
+	ToHost   uint32
+	FromHost uint32 // 0 if the guest has no fromhost
+	Console  io.Writer
+
+	written bool // the upper word of tohost was written
+}
+
+func NewHTIF(tohost uint32, fromhost uint32, console io.Writer) *HTIF {
+	return &HTIF{ToHost: tohost, FromHost: fromhost, Console: console}
+}
+
+// watch marks tohost written if addr..addr+size overlaps the upper word
+func (h *HTIF) watch(addr uint32, size uint32) {
+	hi := uint64(h.ToHost) + 4
+	if uint64(addr) < hi+4 && hi < uint64(addr)+uint64(size) {
+		h.written = true
+	}
+}
+
+// poll runs the command in tohost after the instruction which wrote it.
+// RV32 writes the 64-bit tohost by 2 stores, and the command is complete
+// when the upper word is written, as riscv-tests and GCC write the lower
+// word first. It returns *ExitError for the exit command.
+func (h *HTIF) poll(bus *Bus) error {
+	if !h.written {
+		return nil
+	}
+	h.written = false
+
+	lo, err := bus.Read(h.ToHost, 4)
+	if err != nil {
+		return err
+	}
+	hi, err := bus.Read(h.ToHost+4, 4)
+	if err != nil {
+		return err
+	}
+	if lo == 0 && hi == 0 {
+		return nil
+	}
+	device, cmd := uint8(hi>>24), uint8(hi>>16)
+	payload := uint64(hi&0xffff)<<32 | uint64(lo)
+	trace("htif: device:%d, cmd:%d, payload:0x%x", device, cmd, payload)
+
+	// the command is consumed
+	bus.Write(h.ToHost, 4, 0)
+	bus.Write(h.ToHost+4, 4, 0)
+
+	switch {
+	case device == htifDeviceSyscall && cmd == 0 && payload&1 == 1:
+		return &ExitError{Code: int(payload >> 1)}
+	case device == htifDeviceConsole && cmd == htifConsolePutchar:
+		if h.Console != nil {
+			h.Console.Write([]byte{uint8(payload)})
+		}
+		h.respond(bus, device, cmd, 0x100|payload&0xff)
+	default:
+		log.Warnf("htif: device %d, cmd %d is not implemented", device, cmd)
+	}
+	return nil
+}
+
+// respond writes the response of the command to fromhost
+func (h *HTIF) respond(bus *Bus, device uint8, cmd uint8, data uint64) {
+	if h.FromHost == 0 {
+		return
+	}
+	bus.Write(h.FromHost, 4, uint32(data))
+	bus.Write(h.FromHost+4, 4, uint32(device)<<24|uint32(cmd)<<16|uint32(data>>32)&0xffff)
+}
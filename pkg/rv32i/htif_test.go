@@ -0,0 +1,78 @@
+package rv32i
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"errors"
+	"testing"
+)
+
+func Test_HTIF(t *testing.T) {
+	const tohost = RAMBase + 0x1000
+	const fromhost = RAMBase + 0x1040
+	code := []uint32{
+		GenCode(OpLui, 6, int(tohost>>12), 0),
+		// putchar 'h', the upper word is written last
+		GenCode(OpAddi, 5, 0, 'h'),
+		GenCode(OpSw, 5, 0, 6),
+		GenCode(OpLui, 7, 0x01010, 0),
+		GenCode(OpSw, 7, 4, 6),
+		// wait for the response
+		GenCode(OpLw, 28, 0x40, 6),
+		GenCode(OpBeq, 28, 0, -4),
+		// exit 7
+		GenCode(OpAddi, 5, 0, 7<<1|1),
+		GenCode(OpSw, 5, 0, 6),
+		GenCode(OpSw, 0, 4, 6),
+		GenCode(OpJal, 0, 0, 0),
+	}
+	te := testELF{
+		Entry: RAMBase,
+		Segments: []testSegment{
+			{Paddr: RAMBase, Data: u32sToBytes(code...)},
+		},
+		Symbols: []testSymbol{
+			{Name: "tohost", Addr: tohost, Size: 8, Type: elf.STT_OBJECT},
+			{Name: "fromhost", Addr: fromhost, Size: 8, Type: elf.STT_OBJECT},
+		},
+	}
+
+	console := new(bytes.Buffer)
+	cfg := DefaultEmulatorConfig()
+	cfg.Console = console
+	e, err := NewEmulatorWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Load(te.write(t)); err != nil {
+		t.Fatal(err)
+	}
+	if e.Htif == nil || e.Htif.ToHost != tohost || e.Htif.FromHost != fromhost {
+		t.Fatalf("HTIF must be found, but was %+v", e.Htif)
+	}
+
+	err = e.Run()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 7 {
+		t.Fatalf("exit code must be 7, but err was %v", err)
+	}
+	if console.String() != "h" {
+		t.Errorf("console must be %q, but was %q", "h", console.String())
+	}
+	lo, _ := e.ReadU32(fromhost)
+	hi, _ := e.ReadU32(fromhost + 4)
+	if lo != 0x100|'h' || hi != 0x01010000 {
+		t.Errorf("fromhost must be 0x%08x%08x, but was 0x%08x%08x", 0x01010000, 0x100|'h', hi, lo)
+	}
+	if v, _ := e.ReadU32(tohost); v != 0 {
+		t.Errorf("tohost must be cleared, but was 0x%08x", v)
+	}
+
+	// RunWithOptions stops with the exit code
+	e.Load(te.write(t))
+	r := e.RunWithOptions(context.Background(), RunOptions{})
+	if r.Kind != StopExit || r.ExitCode != 7 {
+		t.Errorf("run must stop with exit code 7, but was %v", r)
+	}
+}
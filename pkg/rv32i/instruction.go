@@ -2,6 +2,7 @@ package rv32i
 
 import (
 	"fmt"
+	"strings"
 )
 
 //go:generate stringer -type InstructionType
@@ -16,6 +17,9 @@ const (
 	InstructionTypeR
 	InstructionTypeF
 	InstructionTypeC
+	InstructionTypeA
+	InstructionTypeFP
+	InstructionTypeInvalid
 )
 
 //go:generate stringer -type OpName
@@ -69,6 +73,86 @@ const (
 	OpCsrrwi
 	OpCsrrsi
 	OpCsrrci
+	OpSret
+	OpMret
+	OpWfi
+	OpSfenceVma
+	// RV32M
+	OpMul
+	OpMulh
+	OpMulhsu
+	OpMulhu
+	OpDiv
+	OpDivu
+	OpRem
+	OpRemu
+	// RV32A
+	OpLrW
+	OpScW
+	OpAmoswapW
+	OpAmoaddW
+	OpAmoxorW
+	OpAmoandW
+	OpAmoorW
+	OpAmominW
+	OpAmomaxW
+	OpAmominuW
+	OpAmomaxuW
+	// RV32F
+	OpFlw
+	OpFsw
+	OpFmaddS
+	OpFmsubS
+	OpFnmsubS
+	OpFnmaddS
+	OpFaddS
+	OpFsubS
+	OpFmulS
+	OpFdivS
+	OpFsqrtS
+	OpFsgnjS
+	OpFsgnjnS
+	OpFsgnjxS
+	OpFminS
+	OpFmaxS
+	OpFcvtWS
+	OpFcvtWuS
+	OpFmvXW
+	OpFeqS
+	OpFltS
+	OpFleS
+	OpFclassS
+	OpFcvtSW
+	OpFcvtSWu
+	OpFmvWX
+	// RV32D
+	OpFld
+	OpFsd
+	OpFmaddD
+	OpFmsubD
+	OpFnmsubD
+	OpFnmaddD
+	OpFaddD
+	OpFsubD
+	OpFmulD
+	OpFdivD
+	OpFsqrtD
+	OpFsgnjD
+	OpFsgnjnD
+	OpFsgnjxD
+	OpFminD
+	OpFmaxD
+	OpFcvtSD
+	OpFcvtDS
+	OpFeqD
+	OpFltD
+	OpFleD
+	OpFclassD
+	OpFcvtWD
+	OpFcvtWuD
+	OpFcvtDW
+	OpFcvtDWu
+	OpInvalid // illegal instruction
 )
 
 type Instruction struct {
@@ -80,6 +164,15 @@ type Instruction struct {
 	Funct3 uint8
 	Rd     uint8
 	Opcode uint8
+	C      uint16 // original instruction if it's expanded from RV32C
+}
+
+// Len returns the size of the instruction in bytes
+func (i *Instruction) Len() uint32 {
+	if i.C != 0 {
+		return 2
+	}
+	return 4
 }
 
 func NewInstruction(instr uint32) *Instruction {
@@ -117,7 +210,7 @@ func NewInstruction(instr uint32) *Instruction {
 		imm105 := instr >> 25 & 0b111111
 		imm41 := instr >> 8 & 0b1111
 		imm11 := instr >> 7 & 0b1
-		imm = imm12<<11 | imm105<<5 | imm41<<1 | imm11
+		imm = imm12<<12 | imm11<<11 | imm105<<5 | imm41<<1
 		imm = SignExtension(imm, 12)
 	case InstructionTypeI:
 		if opcode == 0b1100111 {
@@ -135,12 +228,32 @@ func NewInstruction(instr uint32) *Instruction {
 		imm = SignExtension(imm, 11)
 	case InstructionTypeR:
 		imm = instr >> 25 & 0b11111
+	case InstructionTypeFP:
+		switch opcode {
+		case 0b0000111:
+			// flw, fld
+			imm = SignExtension(instr>>20, 11)
+		case 0b0100111:
+			// fsw, fsd
+			imm = SignExtension(instr>>25<<5|instr>>7&0b11111, 11)
+		}
 	}
 	instance.Imm = imm
 
 	return &instance
 }
 
+// Rs3 returns rs3 of the fused multiply-add instructions
+func (i *Instruction) Rs3() uint8 {
+	return i.Funct7 >> 2
+}
+
+// Csr returns the CSR address of csr* instructions, which is also
+// funct12 of ecall, ebreak, sret, mret and wfi
+func (i *Instruction) Csr() uint32 {
+	return uint32(i.Funct7)<<5 | uint32(i.Rs2)
+}
+
 func GetCodeBase(t string, op1 int, op2 int, op3 int) uint32 {
 	var code uint32
 	switch t {
@@ -287,12 +400,270 @@ func GenCode(opn OpName, op1 int, op2 int, op3 int) uint32 {
 	case OpAnd:
 		code = (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b0110011
 		return code
+	case OpEcall:
+		code = 0b1110011
+		return code
+	case OpEbreak:
+		code = (0b1 << 20) | 0b1110011
+		return code
+	case OpSret:
+		code = (0b0001000_00010 << 20) | 0b1110011
+		return code
+	case OpMret:
+		code = (0b0011000_00010 << 20) | 0b1110011
+		return code
+	case OpWfi:
+		code = (0b0001000_00101 << 20) | 0b1110011
+		return code
+	case OpSfenceVma:
+		code = (0b0001001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | 0b1110011
+		return code
+	case OpCsrrw:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpCsrrs:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpCsrrc:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b011 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpCsrrwi:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b101 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpCsrrsi:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b110 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpCsrrci:
+		code = (uint32(op2) << 20) | (uint32(op3) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1110011
+		return code
+	case OpMul:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpMulh:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpMulhsu:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpMulhu:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b011 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpDiv:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b100 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpDivu:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b101 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpRem:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b110 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpRemu:
+		code = (0b1 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b0110011
+		return code
+	case OpLrW:
+		code = (0b00010 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpScW:
+		code = (0b00011 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmoswapW:
+		code = (0b00001 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmoaddW:
+		code = (0b00000 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmoxorW:
+		code = (0b00100 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmoandW:
+		code = (0b01100 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmoorW:
+		code = (0b01000 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmominW:
+		code = (0b10000 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmomaxW:
+		code = (0b10100 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmominuW:
+		code = (0b11000 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	case OpAmomaxuW:
+		code = (0b11100 << 27) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b0101111
+		return code
+	// RV32F, RV32D
+	case OpFlw, OpFld:
+		funct3 := uint32(0b010)
+		if opn == OpFld {
+			funct3 = 0b011
+		}
+		code = ((uint32(op2) & 0xfff) << 20) | (uint32(op3) << 15) | (funct3 << 12) | (uint32(op1) << 7) | 0b0000111
+		return code
+	case OpFsw, OpFsd:
+		funct3 := uint32(0b010)
+		if opn == OpFsd {
+			funct3 = 0b011
+		}
+		imm115 := (uint32(op2) >> 5) & 0b1111111
+		imm40 := uint32(op2) & 0b11111
+		code = (imm115 << 25) | (uint32(op1) << 20) | (uint32(op3) << 15) | (funct3 << 12) | (imm40 << 7) | 0b0100111
+		return code
+	case OpFaddS:
+		code = (0b0000000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFaddD:
+		code = (0b0000001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsubS:
+		code = (0b0000100 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsubD:
+		code = (0b0000101 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmulS:
+		code = (0b0001000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmulD:
+		code = (0b0001001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFdivS:
+		code = (0b0001100 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFdivD:
+		code = (0b0001101 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsqrtS:
+		code = (0b0101100 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsqrtD:
+		code = (0b0101101 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjS:
+		code = (0b0010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjD:
+		code = (0b0010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjnS:
+		code = (0b0010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjnD:
+		code = (0b0010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjxS:
+		code = (0b0010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFsgnjxD:
+		code = (0b0010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFminS:
+		code = (0b0010100 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFminD:
+		code = (0b0010101 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmaxS:
+		code = (0b0010100 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmaxD:
+		code = (0b0010101 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtWS:
+		code = (0b1100000 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtWD:
+		code = (0b1100001 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtWuS:
+		code = (0b1100000 << 25) | (0b00001 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtWuD:
+		code = (0b1100001 << 25) | (0b00001 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFeqS:
+		code = (0b1010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFeqD:
+		code = (0b1010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b010 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFltS:
+		code = (0b1010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFltD:
+		code = (0b1010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFleS:
+		code = (0b1010000 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFleD:
+		code = (0b1010001 << 25) | (uint32(op3) << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFclassS:
+		code = (0b1110000 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFclassD:
+		code = (0b1110001 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b001 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtSW:
+		code = (0b1101000 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtSWu:
+		code = (0b1101000 << 25) | (0b00001 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtDW:
+		code = (0b1101001 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtDWu:
+		code = (0b1101001 << 25) | (0b00001 << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmvXW:
+		code = (0b1110000 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFmvWX:
+		code = (0b1111000 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtSD:
+		code = (0b0100000 << 25) | (0b00001 << 20) | (uint32(op2) << 15) | (0b111 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
+	case OpFcvtDS:
+		code = (0b0100001 << 25) | (0b00000 << 20) | (uint32(op2) << 15) | (0b000 << 12) | (uint32(op1) << 7) | 0b1010011
+		return code
 	// TODO:
 	default:
 		return 1
 	}
 }
 
+// GenCodeR4 generates fused multiply-add instructions with the dynamic
+// rounding mode
+func GenCodeR4(opn OpName, rd int, rs1 int, rs2 int, rs3 int) uint32 {
+	var opcode, format uint32
+	switch opn {
+	case OpFmaddS:
+		opcode, format = 0b1000011, 0b00
+	case OpFmaddD:
+		opcode, format = 0b1000011, 0b01
+	case OpFmsubS:
+		opcode, format = 0b1000111, 0b00
+	case OpFmsubD:
+		opcode, format = 0b1000111, 0b01
+	case OpFnmsubS:
+		opcode, format = 0b1001011, 0b00
+	case OpFnmsubD:
+		opcode, format = 0b1001011, 0b01
+	case OpFnmaddS:
+		opcode, format = 0b1001111, 0b00
+	case OpFnmaddD:
+		opcode, format = 0b1001111, 0b01
+	default:
+		// same as GenCode
+		return 1
+	}
+	return (uint32(rs3) << 27) | (format << 25) | (uint32(rs2) << 20) | (uint32(rs1) << 15) | (0b111 << 12) | (uint32(rd) << 7) | opcode
+}
+
 func (i *Instruction) GetInstructionType() InstructionType {
 	switch i.Opcode {
 	case 0b0110111, 0b0010111:
@@ -318,8 +689,12 @@ func (i *Instruction) GetInstructionType() InstructionType {
 		return InstructionTypeF
 	case 0b1110011:
 		return InstructionTypeC
+	case 0b0101111:
+		return InstructionTypeA
+	case 0b0000111, 0b0100111, 0b1000011, 0b1000111, 0b1001011, 0b1001111, 0b1010011:
+		return InstructionTypeFP
 	default:
-		panic(fmt.Sprintf("Opcode 0x%07b not Supported", i.Opcode))
+		return InstructionTypeInvalid
 	}
 }
 
@@ -332,14 +707,14 @@ func (i *Instruction) GetOpName() OpName {
 		case 0b0010111:
 			return OpAuipc
 		default:
-			panic(fmt.Sprintf("Opcode: %07b is invalid for %v", i.Opcode, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeJ:
 		switch i.Opcode {
 		case 0b1101111:
 			return OpJal
 		default:
-			panic(fmt.Sprintf("Opcode: %07b is invalid for %v", i.Opcode, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeB:
 		switch i.Funct3 {
@@ -356,7 +731,7 @@ func (i *Instruction) GetOpName() OpName {
 		case 0b111:
 			return OpBgeu
 		default:
-			panic(fmt.Sprintf("Funct3: %03b is invalid for %v", i.Funct3, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeI:
 		switch i.Opcode {
@@ -376,7 +751,7 @@ func (i *Instruction) GetOpName() OpName {
 			case 0b101:
 				return OpLhu
 			default:
-				panic(fmt.Sprintf("Opcode: %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct3, i.Type))
+				return OpInvalid
 			}
 		case 0b0010011:
 			// ADDI, SLTI, ...
@@ -394,10 +769,10 @@ func (i *Instruction) GetOpName() OpName {
 			case 0b111:
 				return OpAndi
 			default:
-				panic(fmt.Sprintf("Opcode: %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct3, i.Type))
+				return OpInvalid
 			}
 		default:
-			panic(fmt.Sprintf("Opcode: %07b is invalid for %v", i.Opcode, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeS:
 		switch i.Funct3 {
@@ -408,13 +783,17 @@ func (i *Instruction) GetOpName() OpName {
 		case 0b010:
 			return OpSw
 		default:
-			panic(fmt.Sprintf("Funct3: %03b is invalid for %v", i.Funct3, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeR:
 		switch i.Opcode {
 		case 0b0010011:
 			switch i.Funct3 {
 			case 0b001:
+				if i.Funct7 != 0b0000000 {
+					// shamt[5] is reserved in RV32
+					return OpInvalid
+				}
 				return OpSlli
 			case 0b101:
 				switch i.Funct7 {
@@ -423,12 +802,15 @@ func (i *Instruction) GetOpName() OpName {
 				case 0b0100000:
 					return OpSrai
 				default:
-					panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+					return OpInvalid
 				}
 			default:
-				panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+				return OpInvalid
 			}
 		case 0b0110011:
+			if i.Funct7 == 0b0000001 {
+				return i.getOpNameM()
+			}
 			switch i.Funct3 {
 			case 0b000:
 				switch i.Funct7 {
@@ -437,16 +819,8 @@ func (i *Instruction) GetOpName() OpName {
 				case 0b0100000:
 					return OpSub
 				default:
-					panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+					return OpInvalid
 				}
-			case 0b001:
-				return OpSll
-			case 0b010:
-				return OpSlt
-			case 0b011:
-				return OpSltu
-			case 0b100:
-				return OpXor
 			case 0b101:
 				switch i.Funct7 {
 				case 0b0000000:
@@ -454,18 +828,36 @@ func (i *Instruction) GetOpName() OpName {
 				case 0b0100000:
 					return OpSra
 				default:
-					panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+					return OpInvalid
 				}
+			}
+			// the others have funct7 0 only
+			if i.Funct7 != 0 {
+				return OpInvalid
+			}
+			switch i.Funct3 {
+			case 0b001:
+				return OpSll
+			case 0b010:
+				return OpSlt
+			case 0b011:
+				return OpSltu
+			case 0b100:
+				return OpXor
 			case 0b110:
 				return OpOr
 			case 0b111:
 				return OpAnd
 			default:
-				panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+				return OpInvalid
 			}
 		default:
-			panic(fmt.Sprintf("Opcode: %07b, Funct7 %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct7, i.Funct3, i.Type))
+			return OpInvalid
 		}
+	case InstructionTypeA:
+		return i.getOpNameA()
+	case InstructionTypeFP:
+		return i.getOpNameFP()
 	case InstructionTypeF:
 		switch i.Funct3 {
 		case 0b000:
@@ -473,20 +865,29 @@ func (i *Instruction) GetOpName() OpName {
 		case 0b001:
 			return OpFenceI
 		default:
-			panic(fmt.Sprintf("Opcode: %07b,  Funct3: %03b is invalid for %v", i.Opcode, i.Funct3, i.Type))
+			return OpInvalid
 		}
 	case InstructionTypeC:
 		switch i.Funct3 {
 		case 0b000:
-			switch i.Rs2 {
-			case 0:
+			// sfence.vma has rs1 and rs2 in funct12
+			if i.Funct7 == 0b0001001 && i.Rd == 0 {
+				return OpSfenceVma
+			}
+			switch i.Csr() {
+			case 0b0000000_00000:
 				return OpEcall
-			case 1:
+			case 0b0000000_00001:
 				return OpEbreak
+			case 0b0001000_00010:
+				return OpSret
+			case 0b0011000_00010:
+				return OpMret
+			case 0b0001000_00101:
+				return OpWfi
 			default:
-				panic(fmt.Sprintf("Opcode: %07b,  Rs2: %b, Funct3: %03b is invalid for %v", i.Opcode, i.Rs2, i.Funct3, i.Type))
+				return OpInvalid
 			}
-
 		case 0b001:
 			return OpCsrrw
 		case 0b010:
@@ -500,31 +901,331 @@ func (i *Instruction) GetOpName() OpName {
 		case 0b111:
 			return OpCsrrci
 		default:
-			panic(fmt.Sprintf("Opcode: %07b, Funct3: %03b is invalid for %v", i.Opcode, i.Funct3, i.Type))
+			return OpInvalid
 		}
 	default:
-		panic(fmt.Sprintf("Opcode: %07b is invalid for %v", i.Opcode, i.Type))
+		return OpInvalid
+	}
+}
+
+// getOpNameM decodes RV32M (OP with funct7 = 0b0000001)
+func (i *Instruction) getOpNameM() OpName {
+	switch i.Funct3 {
+	case 0b000:
+		return OpMul
+	case 0b001:
+		return OpMulh
+	case 0b010:
+		return OpMulhsu
+	case 0b011:
+		return OpMulhu
+	case 0b100:
+		return OpDiv
+	case 0b101:
+		return OpDivu
+	case 0b110:
+		return OpRem
+	default:
+		return OpRemu
 	}
 }
 
+// getOpNameA decodes RV32A. funct7 is funct5, aq and rl.
+func (i *Instruction) getOpNameA() OpName {
+	if i.Funct3 != 0b010 {
+		return OpInvalid
+	}
+	switch i.Funct7 >> 2 {
+	case 0b00010:
+		if i.Rs2 != 0 {
+			return OpInvalid
+		}
+		return OpLrW
+	case 0b00011:
+		return OpScW
+	case 0b00001:
+		return OpAmoswapW
+	case 0b00000:
+		return OpAmoaddW
+	case 0b00100:
+		return OpAmoxorW
+	case 0b01100:
+		return OpAmoandW
+	case 0b01000:
+		return OpAmoorW
+	case 0b10000:
+		return OpAmominW
+	case 0b10100:
+		return OpAmomaxW
+	case 0b11000:
+		return OpAmominuW
+	case 0b11100:
+		return OpAmomaxuW
+	default:
+		return OpInvalid
+	}
+}
+
+// getOpNameFP decodes RV32F and RV32D. The lowest 2 bits of funct7 are
+// the format, 00 for single and 01 for double.
+func (i *Instruction) getOpNameFP() OpName {
+	pick := func(s OpName, d OpName) OpName {
+		switch i.Funct7 & 0b11 {
+		case 0b00:
+			return s
+		case 0b01:
+			return d
+		default:
+			return OpInvalid
+		}
+	}
+
+	switch i.Opcode {
+	case 0b0000111:
+		switch i.Funct3 {
+		case 0b010:
+			return OpFlw
+		case 0b011:
+			return OpFld
+		default:
+			return OpInvalid
+		}
+	case 0b0100111:
+		switch i.Funct3 {
+		case 0b010:
+			return OpFsw
+		case 0b011:
+			return OpFsd
+		default:
+			return OpInvalid
+		}
+	case 0b1000011:
+		return pick(OpFmaddS, OpFmaddD)
+	case 0b1000111:
+		return pick(OpFmsubS, OpFmsubD)
+	case 0b1001011:
+		return pick(OpFnmsubS, OpFnmsubD)
+	case 0b1001111:
+		return pick(OpFnmaddS, OpFnmaddD)
+	}
+
+	// OP-FP
+	switch i.Funct7 >> 2 {
+	case 0b00000:
+		return pick(OpFaddS, OpFaddD)
+	case 0b00001:
+		return pick(OpFsubS, OpFsubD)
+	case 0b00010:
+		return pick(OpFmulS, OpFmulD)
+	case 0b00011:
+		return pick(OpFdivS, OpFdivD)
+	case 0b01011:
+		if i.Rs2 != 0 {
+			return OpInvalid
+		}
+		return pick(OpFsqrtS, OpFsqrtD)
+	case 0b00100:
+		switch i.Funct3 {
+		case 0b000:
+			return pick(OpFsgnjS, OpFsgnjD)
+		case 0b001:
+			return pick(OpFsgnjnS, OpFsgnjnD)
+		case 0b010:
+			return pick(OpFsgnjxS, OpFsgnjxD)
+		default:
+			return OpInvalid
+		}
+	case 0b00101:
+		switch i.Funct3 {
+		case 0b000:
+			return pick(OpFminS, OpFminD)
+		case 0b001:
+			return pick(OpFmaxS, OpFmaxD)
+		default:
+			return OpInvalid
+		}
+	case 0b01000:
+		// fcvt.s.d, fcvt.d.s
+		switch {
+		case i.Funct7 == 0b0100000 && i.Rs2 == 1:
+			return OpFcvtSD
+		case i.Funct7 == 0b0100001 && i.Rs2 == 0:
+			return OpFcvtDS
+		default:
+			return OpInvalid
+		}
+	case 0b10100:
+		switch i.Funct3 {
+		case 0b000:
+			return pick(OpFleS, OpFleD)
+		case 0b001:
+			return pick(OpFltS, OpFltD)
+		case 0b010:
+			return pick(OpFeqS, OpFeqD)
+		default:
+			return OpInvalid
+		}
+	case 0b11000:
+		switch i.Rs2 {
+		case 0:
+			return pick(OpFcvtWS, OpFcvtWD)
+		case 1:
+			return pick(OpFcvtWuS, OpFcvtWuD)
+		default:
+			return OpInvalid
+		}
+	case 0b11010:
+		switch i.Rs2 {
+		case 0:
+			return pick(OpFcvtSW, OpFcvtDW)
+		case 1:
+			return pick(OpFcvtSWu, OpFcvtDWu)
+		default:
+			return OpInvalid
+		}
+	case 0b11100:
+		switch {
+		case i.Rs2 != 0:
+			return OpInvalid
+		case i.Funct3 == 0b000:
+			return pick(OpFmvXW, OpInvalid)
+		case i.Funct3 == 0b001:
+			return pick(OpFclassS, OpFclassD)
+		default:
+			return OpInvalid
+		}
+	case 0b11110:
+		if i.Rs2 != 0 || i.Funct3 != 0b000 {
+			return OpInvalid
+		}
+		return pick(OpFmvWX, OpInvalid)
+	default:
+		return OpInvalid
+	}
+}
+
+var fpMnemonics = map[OpName]string{
+	OpFlw:     "flw",
+	OpFsw:     "fsw",
+	OpFmaddS:  "fmadd.s",
+	OpFmsubS:  "fmsub.s",
+	OpFnmsubS: "fnmsub.s",
+	OpFnmaddS: "fnmadd.s",
+	OpFaddS:   "fadd.s",
+	OpFsubS:   "fsub.s",
+	OpFmulS:   "fmul.s",
+	OpFdivS:   "fdiv.s",
+	OpFsqrtS:  "fsqrt.s",
+	OpFsgnjS:  "fsgnj.s",
+	OpFsgnjnS: "fsgnjn.s",
+	OpFsgnjxS: "fsgnjx.s",
+	OpFminS:   "fmin.s",
+	OpFmaxS:   "fmax.s",
+	OpFcvtWS:  "fcvt.w.s",
+	OpFcvtWuS: "fcvt.wu.s",
+	OpFmvXW:   "fmv.x.w",
+	OpFeqS:    "feq.s",
+	OpFltS:    "flt.s",
+	OpFleS:    "fle.s",
+	OpFclassS: "fclass.s",
+	OpFcvtSW:  "fcvt.s.w",
+	OpFcvtSWu: "fcvt.s.wu",
+	OpFmvWX:   "fmv.w.x",
+	OpFld:     "fld",
+	OpFsd:     "fsd",
+	OpFmaddD:  "fmadd.d",
+	OpFmsubD:  "fmsub.d",
+	OpFnmsubD: "fnmsub.d",
+	OpFnmaddD: "fnmadd.d",
+	OpFaddD:   "fadd.d",
+	OpFsubD:   "fsub.d",
+	OpFmulD:   "fmul.d",
+	OpFdivD:   "fdiv.d",
+	OpFsqrtD:  "fsqrt.d",
+	OpFsgnjD:  "fsgnj.d",
+	OpFsgnjnD: "fsgnjn.d",
+	OpFsgnjxD: "fsgnjx.d",
+	OpFminD:   "fmin.d",
+	OpFmaxD:   "fmax.d",
+	OpFcvtSD:  "fcvt.s.d",
+	OpFcvtDS:  "fcvt.d.s",
+	OpFeqD:    "feq.d",
+	OpFltD:    "flt.d",
+	OpFleD:    "fle.d",
+	OpFclassD: "fclass.d",
+	OpFcvtWD:  "fcvt.w.d",
+	OpFcvtWuD: "fcvt.wu.d",
+	OpFcvtDW:  "fcvt.d.w",
+	OpFcvtDWu: "fcvt.d.wu",
+}
+
+// Mnemonic returns the assembler mnemonic of the OpName such as "amoadd.w"
+func (op OpName) Mnemonic() string {
+	if name, ok := fpMnemonics[op]; ok {
+		return name
+	}
+	if op == OpSfenceVma {
+		return "sfence.vma"
+	}
+	name := strings.ToLower(op.String()[2:])
+	if op >= OpLrW && op <= OpAmomaxuW {
+		// LrW -> lr.w
+		return name[:len(name)-1] + ".w"
+	}
+	return name
+}
+
 func (i *Instruction) GetCodeString() string {
+	if i.C != 0 {
+		return i.getCompressedCodeString()
+	}
+	if i.GetOpName() == OpInvalid {
+		return fmt.Sprintf("Invalid opcode:%07b, funct3:%03b, funct7:%07b", i.Opcode, i.Funct3, i.Funct7)
+	}
+
 	switch i.GetInstructionType() {
 	case InstructionTypeR:
 		return fmt.Sprintf("%s %s, %s, %s", i.GetOpName().String()[2:], RegName(i.Rd), RegName(i.Rs1), RegName(i.Rs2))
 	case InstructionTypeI:
 		return fmt.Sprintf("%s %s, %d(%s)", i.GetOpName().String()[2:], RegName(i.Rd), InterpretSingnedUint32(i.Imm), RegName(i.Rs1))
 	case InstructionTypeS:
-		return fmt.Sprintf("%s %s, %d(%s)", i.GetOpName().String()[2:], RegName(i.Rs1), InterpretSingnedUint32(i.Imm), RegName(i.Rs2))
+		return fmt.Sprintf("%s %s, %d(%s)", i.GetOpName().String()[2:], RegName(i.Rs2), InterpretSingnedUint32(i.Imm), RegName(i.Rs1))
 	case InstructionTypeB:
 		return fmt.Sprintf("%s %s, %s, %d", i.GetOpName().String()[2:], RegName(i.Rs1), RegName(i.Rs2), InterpretSingnedUint32(i.Imm))
 	case InstructionTypeU:
 		return fmt.Sprintf("%s %s, %d", i.GetOpName().String()[2:], RegName(i.Rd), InterpretSingnedUint32(i.Imm>>12))
 	case InstructionTypeJ:
 		return fmt.Sprintf("%s %s, PC+0x%x", i.GetOpName().String()[2:], RegName(i.Rd), InterpretSingnedUint32(i.Imm))
+	case InstructionTypeA:
+		op := i.GetOpName()
+		name := op.Mnemonic()
+		switch i.Funct7 & 0b11 {
+		case 0b10:
+			name += ".aq"
+		case 0b01:
+			name += ".rl"
+		case 0b11:
+			name += ".aqrl"
+		}
+		if op == OpLrW {
+			return fmt.Sprintf("%s %s, (%s)", name, RegName(i.Rd), RegName(i.Rs1))
+		}
+		return fmt.Sprintf("%s %s, %s, (%s)", name, RegName(i.Rd), RegName(i.Rs2), RegName(i.Rs1))
+	case InstructionTypeFP:
+		return i.getFPCodeString()
 	case InstructionTypeF:
 		return i.GetOpName().String()[2:] + "(TBD)"
 	case InstructionTypeC:
-		return i.GetOpName().String()[2:] + "(TBD)"
+		switch op := i.GetOpName(); op {
+		case OpCsrrw, OpCsrrs, OpCsrrc:
+			return fmt.Sprintf("%s %s, %s, %s", op.String()[2:], RegName(i.Rd), CsrName(i.Csr()), RegName(i.Rs1))
+		case OpCsrrwi, OpCsrrsi, OpCsrrci:
+			return fmt.Sprintf("%s %s, %s, %d", op.String()[2:], RegName(i.Rd), CsrName(i.Csr()), i.Rs1)
+		case OpSfenceVma:
+			return fmt.Sprintf("%s %s, %s", op.Mnemonic(), RegName(i.Rs1), RegName(i.Rs2))
+		default:
+			return op.String()[2:]
+		}
 	default:
 		return i.GetOpName().String()[2:] + "(TBD)"
 	}
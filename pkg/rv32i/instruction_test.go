@@ -41,6 +41,8 @@ func Test_NewInstruction(t *testing.T) {
 		{0x00008067, Instruction{Type: InstructionTypeI, Imm: 0, Funct7: 0, Rs2: 0, Rs1: 1, Funct3: 0, Rd: 0, Opcode: 0x00008067 & 0b1111111}},
 		//       28: 63 00 00 00   beqz    zero, 0x28 <.Lline_table_start0+0x28>
 		{0x00000063, Instruction{Type: InstructionTypeB, Imm: 0, Funct7: 0, Rs2: 0, Rs1: 0, Funct3: 0, Rd: 0, Opcode: 0x00000063 & 0b1111111}},
+		//       2c: e3 1e 05 fe   bnez    a0, -4
+		{0xfe051ee3, Instruction{Type: InstructionTypeB, Imm: 0xfffffffc, Funct7: 127, Rs2: 0, Rs1: 10, Funct3: 1, Rd: 29, Opcode: 0xfe051ee3 & 0b1111111}},
 		// 800000ac: 97 00 00 00   auipc   ra, 0
 		{0x00000097, Instruction{Type: InstructionTypeU, Imm: 0, Funct7: 0, Rs2: 0, Rs1: 0, Funct3: 0, Rd: 1, Opcode: 0x00000097 & 0b1111111}},
 		//       3c: 73 63 76 31   csrrsi  t1, 791, 12
@@ -77,6 +79,11 @@ func Test_GetOpName(t *testing.T) {
 		{0x00000097, OpAuipc},
 		//       3c: 73 63 76 31   csrrsi  t1, 791, 12
 		{0x31766373, OpCsrrsi},
+		//       40: 73 00 20 30   mret
+		{0x30200073, OpMret},
+		{0x10200073, OpSret},
+		{0x10500073, OpWfi},
+		{0x12b50073, OpSfenceVma},
 	} {
 		got := NewInstruction(td.Instr).GetOpName()
 		if got != td.Want {
@@ -107,3 +114,24 @@ func Test_GenCode(t *testing.T) {
 		}
 	}
 }
+
+func Test_GenCodeR4(t *testing.T) {
+	type TestData struct {
+		opn  OpName
+		want uint32
+	}
+
+	tds := []TestData{
+		{OpFmaddS, 0x68c5f543},
+		{OpFnmaddD, 0x6ac5f54f},
+		// not a R4 instruction
+		{OpAddi, 1},
+	}
+
+	for idx, td := range tds {
+		got := GenCodeR4(td.opn, 10, 11, 12, 13)
+		if got != td.want {
+			t.Errorf("[%d] Wrong code. got: 0x%08x, want: 0x%08x", idx, got, td.want)
+		}
+	}
+}
@@ -16,15 +16,19 @@ func _() {
 	_ = x[InstructionTypeR-5]
 	_ = x[InstructionTypeF-6]
 	_ = x[InstructionTypeC-7]
+	_ = x[InstructionTypeA-8]
+	_ = x[InstructionTypeFP-9]
+	_ = x[InstructionTypeInvalid-10]
 }
 
-const _InstructionType_name = "InstructionTypeUInstructionTypeJInstructionTypeBInstructionTypeIInstructionTypeSInstructionTypeRInstructionTypeFInstructionTypeC"
+const _InstructionType_name = "InstructionTypeUInstructionTypeJInstructionTypeBInstructionTypeIInstructionTypeSInstructionTypeRInstructionTypeFInstructionTypeCInstructionTypeAInstructionTypeFPInstructionTypeInvalid"
 
-var _InstructionType_index = [...]uint8{0, 16, 32, 48, 64, 80, 96, 112, 128}
+var _InstructionType_index = [...]uint8{0, 16, 32, 48, 64, 80, 96, 112, 128, 144, 161, 183}
 
 func (i InstructionType) String() string {
-	if i < 0 || i >= InstructionType(len(_InstructionType_index)-1) {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_InstructionType_index)-1 {
 		return "InstructionType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _InstructionType_name[_InstructionType_index[i]:_InstructionType_index[i+1]]
+	return _InstructionType_name[_InstructionType_index[idx]:_InstructionType_index[idx+1]]
 }
@@ -17,7 +17,8 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
-func (l *Loader) LoadAt(filePath string, loadAddr *[]uint8, maxSize uint32) error {
+// LoadAt loads a text dump (.txt) or a raw binary into bus at addr
+func (l *Loader) LoadAt(filePath string, bus *Bus, addr uint32) error {
 	var err error
 	ext := filepath.Ext(filePath)
 
@@ -29,35 +30,38 @@ func (l *Loader) LoadAt(filePath string, loadAddr *[]uint8, maxSize uint32) erro
 		defer fp.Close()
 
 		reader := bufio.NewReaderSize(fp, 1024)
-		err = l.readInto(reader, loadAddr, maxSize)
+		err = l.readInto(reader, bus, addr)
 	} else {
-		err = l.ReadBinary(filePath, loadAddr)
+		var data []byte
+		data, err = os.ReadFile(filePath)
 		if err != nil {
-			return nil
+			return err
 		}
+		err = bus.Load(addr, data)
 	}
 
 	return err
 }
 
-func (l *Loader) LoadStringAt(data string, loadAddr *[]uint8, maxSize uint32) error {
+// LoadStringAt loads a text dump into bus at addr
+func (l *Loader) LoadStringAt(data string, bus *Bus, addr uint32) error {
 	reader := bufio.NewReader(strings.NewReader(data))
-	return l.readInto(reader, loadAddr, maxSize)
+	return l.readInto(reader, bus, addr)
 }
 
-func (l *Loader) readInto(reader *bufio.Reader, loadAddr *[]uint8, maxSize uint32) error {
+func (l *Loader) readInto(reader *bufio.Reader, bus *Bus, addr uint32) error {
 	var err error
-	var mem *[]uint32
+	var codes *[]uint32
 
-	mem, err = l.ReadText(reader)
+	codes, err = l.ReadText(reader)
 	if err != nil {
 		return nil
 	}
-	for idx, u32 := range *mem {
-		(*loadAddr)[idx*4] = uint8(u32 & 0x000000FF)
-		(*loadAddr)[idx*4+1] = uint8((u32 & 0x0000FF00) >> 8)
-		(*loadAddr)[idx*4+2] = uint8((u32 & 0x00FF0000) >> 16)
-		(*loadAddr)[idx*4+3] = uint8((u32 & 0xFF000000) >> 24)
+	for idx, u32 := range *codes {
+		by4 := []byte{uint8(u32), uint8(u32 >> 8), uint8(u32 >> 16), uint8(u32 >> 24)}
+		if err = bus.Load(addr+uint32(idx*4), by4); err != nil {
+			return err
+		}
 	}
 
 	return err
@@ -2,7 +2,6 @@ package rv32i
 
 import (
 	"bufio"
-	"encoding/binary"
 	"os"
 	"testing"
 )
@@ -35,7 +34,7 @@ func Test_ReadText(t *testing.T) {
 }
 
 func Test_LoadStringAt(t *testing.T) {
-	mem := make([]uint8, MaxMemory)
+	bus := NewEmulator().Bus
 	program := `00000000 <boot>:
        0: 93 00 00 00   li      ra, 0
        4: 13 04 00 00   li      s0, 0
@@ -43,7 +42,7 @@ func Test_LoadStringAt(t *testing.T) {
 `
 
 	loader := NewLoader()
-	err := loader.LoadStringAt(program, &mem, MaxMemory)
+	err := loader.LoadStringAt(program, bus, 0)
 	if err != nil {
 		t.Error("Failed to load a string")
 	}
@@ -51,7 +50,7 @@ func Test_LoadStringAt(t *testing.T) {
 	testdata := []uint32{0x00000093, 0x00000413, 0x00004537}
 
 	for idx, u32 := range testdata {
-		got := binary.LittleEndian.Uint32(mem[idx*4 : idx*4+4])
+		got, _ := bus.Read(uint32(idx*4), 4)
 		if got != u32 {
 			t.Errorf("idx %d wanted 0x%08x, gt 0x%08x", idx, u32, got)
 		}
@@ -59,7 +58,7 @@ func Test_LoadStringAt(t *testing.T) {
 }
 
 func Test_LoadStringAt2(t *testing.T) {
-	mem := make([]uint8, MaxMemory)
+	bus := NewEmulator().Bus
 	program := `00000000 <boot>:
        0: 0x00000093 Addi ra, 0(zero)
        4: 0x00000413 Addi s0, 0(zero)
@@ -67,7 +66,7 @@ func Test_LoadStringAt2(t *testing.T) {
        c: 0x00001117 Auipc sp, 1`
 
 	loader := NewLoader()
-	err := loader.LoadStringAt(program, &mem, MaxMemory)
+	err := loader.LoadStringAt(program, bus, 0)
 	if err != nil {
 		t.Error("Failed to load a string")
 	}
@@ -75,7 +74,7 @@ func Test_LoadStringAt2(t *testing.T) {
 	testdata := []uint32{0x00000093, 0x00000413, 0x00004537, 0x00001117}
 
 	for idx, u32 := range testdata {
-		got := binary.LittleEndian.Uint32(mem[idx*4 : idx*4+4])
+		got, _ := bus.Read(uint32(idx*4), 4)
 		if got != u32 {
 			t.Errorf("idx %d wanted 0x%08x, gt 0x%08x", idx, u32, got)
 		}
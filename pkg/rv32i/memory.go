@@ -0,0 +1,162 @@
+package rv32i
+
+import (
+	"fmt"
+)
+
+const (
+	pageShift = 12
+	pageSize  = uint32(1 << pageShift)
+	pageMask  = pageSize - 1
+)
+
+// MemoryRegion is a RAM range in the guest physical address space
+type MemoryRegion struct {
+	Base uint32
+	Size uint32
+}
+
+// End returns the address next to the last byte of the region
+func (r MemoryRegion) End() uint64 {
+	return uint64(r.Base) + uint64(r.Size)
+}
+
+type page [pageSize]uint8
+
+// RAM is a sparse memory device.
+// Pages are allocated on the first write, so large RAM anywhere in the 32-bit
+// address space is cheap.
+type RAM struct {
+	size  uint32
+	pages map[uint32]*page
+}
+
+func NewRAM(size uint32) *RAM {
+	return &RAM{
+		size:  size,
+		pages: map[uint32]*page{},
+	}
+}
+
+// inRange returns an error if offset..offset+size is out of the RAM
+func (m *RAM) inRange(offset uint32, size uint32) error {
+	if uint64(offset)+uint64(size) > uint64(m.size) {
+		return fmt.Errorf("0x%08x-0x%08x is out of RAM", offset, uint64(offset)+uint64(size))
+	}
+	return nil
+}
+
+// page returns the page of offset. It returns nil for a page which hasn't been
+// written yet unless alloc is set.
+func (m *RAM) page(offset uint32, alloc bool) *page {
+	n := offset >> pageShift
+	p, ok := m.pages[n]
+	if !ok && alloc {
+		p = &page{}
+		m.pages[n] = p
+	}
+	return p
+}
+
+func (m *RAM) Read(offset uint32, size uint32) (uint32, error) {
+	if err := m.inRange(offset, size); err != nil {
+		return 0, err
+	}
+	if offset&pageMask > pageSize-size {
+		// across pages
+		var buf [4]byte
+		m.ReadBytes(offset, buf[:size])
+		return uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16 | uint32(buf[3])<<24, nil
+	}
+
+	p := m.page(offset, false)
+	if p == nil {
+		return 0, nil
+	}
+	off := offset & pageMask
+	var data uint32
+	for i := uint32(0); i < size; i++ {
+		data |= uint32(p[off+i]) << (8 * i)
+	}
+	return data, nil
+}
+
+func (m *RAM) Write(offset uint32, size uint32, data uint32) error {
+	buf := [4]byte{uint8(data), uint8(data >> 8), uint8(data >> 16), uint8(data >> 24)}
+	return m.WriteBytes(offset, buf[:size])
+}
+
+func (m *RAM) Tick() {}
+
+// ReadBytes copies len(data) bytes at offset into data
+func (m *RAM) ReadBytes(offset uint32, data []byte) error {
+	if err := m.inRange(offset, uint32(len(data))); err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		off := offset & pageMask
+		n := uint32(len(data))
+		if n > pageSize-off {
+			n = pageSize - off
+		}
+		if p := m.page(offset, false); p != nil {
+			copy(data[:n], p[off:off+n])
+		} else {
+			for i := range data[:n] {
+				data[i] = 0
+			}
+		}
+		data = data[n:]
+		offset += n
+	}
+	return nil
+}
+
+// WriteBytes copies data to offset
+func (m *RAM) WriteBytes(offset uint32, data []byte) error {
+	if err := m.inRange(offset, uint32(len(data))); err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		off := offset & pageMask
+		n := uint32(len(data))
+		if n > pageSize-off {
+			n = pageSize - off
+		}
+		copy(m.page(offset, true)[off:off+n], data[:n])
+		data = data[n:]
+		offset += n
+	}
+	return nil
+}
+
+func (m *RAM) Load(offset uint32, data []byte) error {
+	return m.WriteBytes(offset, data)
+}
+
+// Clear zeroes size bytes at offset. Pages in the range are freed, and pages
+// never written stay unallocated.
+func (m *RAM) Clear(offset uint32, size uint32) error {
+	if err := m.inRange(offset, size); err != nil {
+		return err
+	}
+	end := uint64(offset) + uint64(size)
+	for cur := uint64(offset); cur < end; {
+		off := uint32(cur) & pageMask
+		n := uint64(pageSize - off)
+		if n > end-cur {
+			n = end - cur
+		}
+		if p := m.page(uint32(cur), false); p != nil {
+			if n == uint64(pageSize) {
+				delete(m.pages, uint32(cur)>>pageShift)
+			} else {
+				for i := off; i < off+uint32(n); i++ {
+					p[i] = 0
+				}
+			}
+		}
+		cur += n
+	}
+	return nil
+}
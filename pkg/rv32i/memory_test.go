@@ -0,0 +1,109 @@
+package rv32i
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func Test_RAM(t *testing.T) {
+	m := NewRAM(0x10000)
+
+	// never written memory reads as 0 and doesn't allocate pages
+	if v, err := m.Read(0x8000, 4); v != 0 || err != nil {
+		t.Errorf("got 0x%08x, %v", v, err)
+	}
+	if len(m.pages) != 0 {
+		t.Errorf("%d pages must not be allocated", len(m.pages))
+	}
+
+	// across a page boundary
+	m.Write(0xffe, 4, 0x11223344)
+	if v, _ := m.Read(0xffe, 4); v != 0x11223344 {
+		t.Errorf("got 0x%08x", v)
+	}
+	if v, _ := m.Read(0xfff, 2); v != 0x2233 {
+		t.Errorf("got 0x%04x", v)
+	}
+	if len(m.pages) != 2 {
+		t.Errorf("2 pages must be allocated, but %d", len(m.pages))
+	}
+
+	data := []byte{1, 2, 3, 4, 5, 6}
+	m.WriteBytes(0x1ffd, data)
+	got := make([]byte, 6)
+	m.ReadBytes(0x1ffd, got)
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %v", got)
+	}
+
+	// Clear frees the pages in the range, and zeroes the partial ones
+	m.Clear(0x1000, 0x1000)
+	if v, _ := m.Read(0xffe, 4); v != 0x3344 || len(m.pages) != 2 {
+		t.Errorf("got 0x%08x and %d pages", v, len(m.pages))
+	}
+	if err := m.Clear(0xfff0, 0x20); err == nil {
+		t.Error("clear out of the RAM must fail")
+	}
+
+	// out of the RAM
+	if err := m.Write(0xfffe, 4, 0); err == nil {
+		t.Error("write out of the RAM must fail")
+	}
+	if _, err := m.Read(0x10000, 1); err == nil {
+		t.Error("read out of the RAM must fail")
+	}
+}
+
+func Test_EmulatorConfig(t *testing.T) {
+	_, err := NewEmulatorWithConfig(EmulatorConfig{})
+	if err == nil {
+		t.Error("a config without memory must be rejected")
+	}
+	_, err = NewEmulatorWithConfig(EmulatorConfig{Memory: []MemoryRegion{{0, 0x2000}, {0x1000, 0x1000}}})
+	if err == nil {
+		t.Error("overlapping regions must be rejected")
+	}
+
+	// a program linked at 0x80000000
+	e, err := NewEmulatorWithConfig(EmulatorConfig{Memory: []MemoryRegion{{0x80000000, 0x100000}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.LoadString(`80000000 <boot>:
+80000000: 37 45 00 00   lui     a0, 4
+80000004: 23 20 a5 00   sw      a0, 0(a0)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Cpu.PC != 0x80000000 {
+		t.Errorf("PC must be 0x80000000, but was 0x%08x", e.Cpu.PC)
+	}
+	e.Step()
+
+	// 0x4000 is not mapped
+	err = e.Step()
+	var trap *Trap
+	if !errors.As(err, &trap) || trap.Cause != CauseStoreAccessFault || trap.Tval != 0x4000 {
+		t.Errorf("sw must raise a store access fault, but got %v", err)
+	}
+
+	// memory is kept mapped after Reset
+	e.Reset()
+	if err := e.WriteU32(0x800ffffc, 1); err != nil {
+		t.Error(err)
+	}
+
+	// an unmapped range fails before allocating the buffer
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := e.ReadBytes(0x100, 0xfff00000); err == nil {
+		t.Error("ReadBytes of an unmapped range must fail")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n >= 1<<20 {
+		t.Errorf("ReadBytes of an unmapped range must not allocate the buffer, but allocated %d bytes", n)
+	}
+}
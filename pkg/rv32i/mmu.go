@@ -0,0 +1,232 @@
+package rv32i
+
+// satp fields
+const (
+	SatpModeSv32  = uint32(1 << 31)
+	satpASIDShift = 22
+	satpASIDMask  = uint32(0x1ff) << satpASIDShift
+	satpPPNMask   = uint32(0x3f_ffff)
+)
+
+// Sv32 page table entry bits
+const (
+	pteV = uint32(1 << 0)
+	pteR = uint32(1 << 1)
+	pteW = uint32(1 << 2)
+	pteX = uint32(1 << 3)
+	pteU = uint32(1 << 4)
+	pteG = uint32(1 << 5)
+	pteA = uint32(1 << 6)
+	pteD = uint32(1 << 7)
+
+	ptePPNShift = 10
+	pteSize     = 4
+	pageLevels  = 2
+	vpnBits     = 10
+	vpnMask     = uint32(1<<vpnBits - 1)
+)
+
+// accessType is the kind of a memory access to pick permissions and causes
+type accessType int
+
+const (
+	accessFetch accessType = iota
+	accessLoad
+	accessStore // stores and AMOs
+)
+
+var pageFaults = [...]TrapCause{CauseInstructionPageFault, CauseLoadPageFault, CauseStorePageFault}
+var accessFaults = [...]TrapCause{CauseInstructionAccessFault, CauseLoadAccessFault, CauseStoreAccessFault}
+
+// tlbSize is the number of TLB entries, which are direct-mapped by VPN
+const tlbSize = 256
+
+type tlbEntry struct {
+	valid bool
+	vpn   uint32
+	asid  uint32
+	pte   uint32 // leaf PTE
+	ppn   uint32 // PPN of the 4KiB page, superpages are split into 4KiB pages
+}
+
+// TLB caches Sv32 translations of a hart
+type TLB struct {
+	entries [tlbSize]tlbEntry
+	Hits    uint64
+	Misses  uint64
+}
+
+func (t *TLB) lookup(vpn uint32, asid uint32) *tlbEntry {
+	e := &t.entries[vpn%tlbSize]
+	if e.valid && e.vpn == vpn && (e.asid == asid || e.pte&pteG != 0) {
+		return e
+	}
+	return nil
+}
+
+func (t *TLB) insert(vpn uint32, asid uint32, pte uint32, ppn uint32) *tlbEntry {
+	e := &t.entries[vpn%tlbSize]
+	*e = tlbEntry{valid: true, vpn: vpn, asid: asid, pte: pte, ppn: ppn}
+	return e
+}
+
+// fence invalidates entries as sfence.vma does. Every address is
+// invalidated if allAddr is true, and every ASID if allASID is true.
+// Global mappings are kept unless allASID is true.
+func (t *TLB) fence(vaddr uint32, asid uint32, allAddr bool, allASID bool) {
+	vpn := vaddr >> pageShift
+	for i := range t.entries {
+		e := &t.entries[i]
+		if !allAddr && e.vpn != vpn {
+			continue
+		}
+		if !allASID && (e.asid != asid || e.pte&pteG != 0) {
+			continue
+		}
+		e.valid = false
+	}
+}
+
+// Flush invalidates all entries
+func (t *TLB) Flush() {
+	t.fence(0, 0, true, true)
+}
+
+// dataPriv returns the privilege level of loads and stores, which is MPP
+// when mstatus.MPRV is set in M-mode
+func (c *Cpu) dataPriv() uint32 {
+	if c.Priv == PrivM && c.Csr.Mstatus&MstatusMPRV != 0 {
+		return (c.Csr.Mstatus & MstatusMPP) >> 11
+	}
+	return c.Priv
+}
+
+func (c *Cpu) pageFault(vaddr uint32, access accessType) error {
+	return &Trap{Cause: pageFaults[access], Tval: vaddr, PC: c.PC}
+}
+
+func (c *Cpu) accessFault(vaddr uint32, access accessType) error {
+	return &Trap{Cause: accessFaults[access], Tval: vaddr, PC: c.PC}
+}
+
+// translate returns the physical address of vaddr. Addresses are translated
+// by Sv32 below M-mode when satp.MODE is set. It returns a page fault or an
+// access fault as *Trap.
+func (c *Cpu) translate(vaddr uint32, access accessType) (uint32, error) {
+	priv := c.Priv
+	if access != accessFetch {
+		priv = c.dataPriv()
+	}
+	satp := c.Csr.Satp
+	if priv == PrivM || satp&SatpModeSv32 == 0 {
+		return vaddr, nil
+	}
+
+	asid := (satp & satpASIDMask) >> satpASIDShift
+	e := c.tlb.lookup(vaddr>>pageShift, asid)
+	// D is set by the walk on the first store to the page
+	if e == nil || access == accessStore && e.pte&pteD == 0 {
+		c.tlb.Misses++
+		var err error
+		if e, err = c.walk(vaddr, access, priv, asid); err != nil {
+			return 0, err
+		}
+	} else {
+		c.tlb.Hits++
+		if !c.permitted(e.pte, priv, access) {
+			return 0, c.pageFault(vaddr, access)
+		}
+	}
+	return e.ppn<<pageShift | vaddr&pageMask, nil
+}
+
+// walk walks the page table, updates A and D of the leaf PTE and adds the
+// translation to the TLB
+func (c *Cpu) walk(vaddr uint32, access accessType, priv uint32, asid uint32) (*tlbEntry, error) {
+	a := uint64(c.Csr.Satp&satpPPNMask) << pageShift
+	for level := pageLevels - 1; level >= 0; level-- {
+		vpn := vaddr >> (pageShift + vpnBits*level) & vpnMask
+		pteAddr := a + uint64(vpn*pteSize)
+		// the walk is an S-mode access
+		if pteAddr >= 1<<32 || !c.pmpCheck(uint32(pteAddr), pteSize, PrivS, PmpR) {
+			return nil, c.accessFault(vaddr, access)
+		}
+		pte, err := c.Emu.ReadU32(uint32(pteAddr))
+		if err != nil {
+			return nil, c.accessFault(vaddr, access)
+		}
+		if pte&pteV == 0 || pte&(pteR|pteW) == pteW {
+			return nil, c.pageFault(vaddr, access)
+		}
+		ppn := uint64(pte >> ptePPNShift)
+		if pte&(pteR|pteX) == 0 {
+			// pointer to the next level
+			a = ppn << pageShift
+			continue
+		}
+
+		// leaf
+		if level > 0 && ppn&uint64(vpnMask) != 0 {
+			// misaligned superpage
+			return nil, c.pageFault(vaddr, access)
+		}
+		if !c.permitted(pte, priv, access) {
+			return nil, c.pageFault(vaddr, access)
+		}
+		// A and D are updated by the hardware
+		updated := pte | pteA
+		if access == accessStore {
+			updated |= pteD
+		}
+		if updated != pte {
+			if !c.pmpCheck(uint32(pteAddr), pteSize, PrivS, PmpW) {
+				return nil, c.accessFault(vaddr, access)
+			}
+			if err = c.Emu.WriteU32(uint32(pteAddr), updated); err != nil {
+				return nil, c.accessFault(vaddr, access)
+			}
+			pte = updated
+		}
+		if level > 0 {
+			ppn |= uint64(vaddr>>pageShift) & uint64(vpnMask)
+		}
+		// Sv32 has 34-bit physical addresses but the bus has 32 bits
+		if ppn >= 1<<(32-pageShift) {
+			return nil, c.accessFault(vaddr, access)
+		}
+		return c.tlb.insert(vaddr>>pageShift, asid, pte, uint32(ppn)), nil
+	}
+	return nil, c.pageFault(vaddr, access)
+}
+
+// permitted checks R/W/X and U of the leaf PTE with mstatus.SUM and MXR
+func (c *Cpu) permitted(pte uint32, priv uint32, access accessType) bool {
+	mstatus := c.Csr.Mstatus
+	if pte&pteU != 0 {
+		// S-mode can access user pages with SUM, but can't execute them
+		if priv == PrivS && (access == accessFetch || mstatus&MstatusSUM == 0) {
+			return false
+		}
+	} else if priv == PrivU {
+		return false
+	}
+
+	switch access {
+	case accessFetch:
+		return pte&pteX != 0
+	case accessLoad:
+		return pte&pteR != 0 || mstatus&MstatusMXR != 0 && pte&pteX != 0
+	default:
+		return pte&pteW != 0
+	}
+}
+
+// sfenceVma runs sfence.vma. It's illegal in U-mode, and in S-mode when
+// mstatus.TVM is set.
+func (c *Cpu) sfenceVma(i *Instruction) bool {
+	if c.Priv == PrivU || c.Priv == PrivS && c.Csr.Mstatus&MstatusTVM != 0 {
+		return false
+	}
+	c.tlb.fence(c.X[i.Rs1], c.X[i.Rs2]&(satpASIDMask>>satpASIDShift), i.Rs1 == 0, i.Rs2 == 0)
+	return true
+}
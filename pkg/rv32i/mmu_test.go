@@ -0,0 +1,156 @@
+package rv32i
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testRootTable = RAMBase + 0x1000
+	testLeafTable = RAMBase + 0x2000
+)
+
+// newMMUEmulator returns an emulator in S-mode with Sv32 page tables
+func newMMUEmulator() *Emulator {
+	e := NewEmulator()
+	pte := func(pa uint32, flags uint32) uint32 {
+		return pa>>pageShift<<ptePPNShift | flags
+	}
+	// 0x00400000: user RW, 0x00401000: user X, 0x00402000: reserved W
+	e.WriteU32(testRootTable+4*1, pte(testLeafTable, pteV))
+	e.WriteU32(testLeafTable+4*0, pte(RAMBase+0x10000, pteV|pteR|pteW|pteU))
+	e.WriteU32(testLeafTable+4*1, pte(RAMBase+0x11000, pteV|pteX|pteU))
+	e.WriteU32(testLeafTable+4*2, pte(RAMBase+0x12000, pteV|pteW))
+	// 0xc0000000: kernel megapage, 0xc0400000: misaligned megapage
+	e.WriteU32(testRootTable+4*0x300, pte(RAMBase, pteV|pteR|pteW|pteX|pteA|pteD))
+	e.WriteU32(testRootTable+4*0x301, pte(RAMBase+0x1000, pteV|pteR))
+
+	e.Cpu.Priv = PrivS
+	e.Cpu.Csr.Satp = SatpModeSv32 | testRootTable>>pageShift
+	return e
+}
+
+func Test_Translate(t *testing.T) {
+	type TestData struct {
+		Priv    uint32
+		Mstatus uint32
+		Vaddr   uint32
+		Access  accessType
+		Want    uint32
+		Cause   TrapCause // 0 if no fault
+	}
+
+	for _, td := range []TestData{
+		{PrivS, 0, 0xc000_0123, accessLoad, RAMBase + 0x123, 0},
+		{PrivS, 0, 0xc012_3456, accessFetch, RAMBase + 0x12_3456, 0},
+		{PrivU, 0, 0xc000_0000, accessLoad, 0, CauseLoadPageFault},
+		{PrivU, 0, 0x0040_0010, accessStore, RAMBase + 0x10010, 0},
+		{PrivS, 0, 0x0040_0010, accessLoad, 0, CauseLoadPageFault},
+		{PrivS, MstatusSUM, 0x0040_0010, accessLoad, RAMBase + 0x10010, 0},
+		{PrivS, MstatusSUM, 0x0040_1000, accessFetch, 0, CauseInstructionPageFault},
+		{PrivU, 0, 0x0040_1000, accessFetch, RAMBase + 0x11000, 0},
+		{PrivU, 0, 0x0040_1000, accessLoad, 0, CauseLoadPageFault},
+		{PrivU, MstatusMXR, 0x0040_1000, accessLoad, RAMBase + 0x11000, 0},
+		{PrivU, 0, 0x0040_1000, accessStore, 0, CauseStorePageFault},
+		{PrivS, MstatusSUM, 0x0040_2000, accessStore, 0, CauseStorePageFault},
+		{PrivS, 0, 0x0040_3000, accessLoad, 0, CauseLoadPageFault},
+		{PrivS, 0, 0x0080_0000, accessLoad, 0, CauseLoadPageFault},
+		{PrivS, 0, 0xc040_0000, accessLoad, 0, CauseLoadPageFault},
+		// M-mode is not translated unless MPRV is set for loads and stores
+		{PrivM, 0, 0x0040_0010, accessLoad, 0x0040_0010, 0},
+		{PrivM, MstatusMPRV, 0x0040_0010, accessLoad, RAMBase + 0x10010, 0},
+		{PrivM, MstatusMPRV, 0x0040_0010, accessFetch, 0x0040_0010, 0},
+	} {
+		e := newMMUEmulator()
+		e.Cpu.Priv = td.Priv
+		// MPP is U
+		e.Cpu.Csr.Mstatus = e.Cpu.Csr.Mstatus&^MstatusMPP | td.Mstatus
+		got, err := e.Cpu.translate(td.Vaddr, td.Access)
+		if td.Cause == 0 {
+			if err != nil || got != td.Want {
+				t.Errorf("0x%08x: got 0x%08x, %v, want 0x%08x", td.Vaddr, got, err, td.Want)
+			}
+			continue
+		}
+		var trap *Trap
+		if !errors.As(err, &trap) || trap.Cause != td.Cause || trap.Tval != td.Vaddr {
+			t.Errorf("0x%08x must raise %v, but was %v", td.Vaddr, td.Cause, err)
+		}
+	}
+}
+
+func Test_TLB(t *testing.T) {
+	e := newMMUEmulator()
+	cpu := e.Cpu
+	cpu.Csr.Mstatus |= MstatusSUM
+	leaf := testLeafTable + 4*0
+
+	// A is set by the first access, and D by the first store
+	if _, ok := cpu.load(0x0040_0000, 4); !ok {
+		t.Fatal(cpu.err)
+	}
+	if _, ok := cpu.load(0x0040_0004, 4); !ok {
+		t.Fatal(cpu.err)
+	}
+	pte, _ := e.ReadU32(leaf)
+	if pte&pteA == 0 || pte&pteD != 0 {
+		t.Errorf("only A must be set, but pte was 0x%08x", pte)
+	}
+	if !cpu.store(0x0040_0000, 4, 0x1234) {
+		t.Fatal(cpu.err)
+	}
+	pte, _ = e.ReadU32(leaf)
+	if pte&pteD == 0 {
+		t.Errorf("D must be set, but pte was 0x%08x", pte)
+	}
+	if v, _ := e.ReadU32(RAMBase + 0x10000); v != 0x1234 {
+		t.Errorf("0x1234 must be stored, but was 0x%x", v)
+	}
+	if s := e.Stats(); s.TLBHits != 1 || s.TLBMisses != 2 {
+		t.Errorf("TLB hits and misses must be 1 and 2, but were %d and %d", s.TLBHits, s.TLBMisses)
+	}
+
+	// stale entries are used until sfence.vma
+	e.WriteU32(leaf, RAMBase>>pageShift<<ptePPNShift|pteV|pteR|pteW|pteA|pteD)
+	if pa, _ := cpu.translate(0x0040_0000, accessLoad); pa != RAMBase+0x10000 {
+		t.Errorf("stale translation must be used, but was 0x%08x", pa)
+	}
+	cpu.X[10] = 0x0040_0000
+	cpu.raw = GenCode(OpSfenceVma, 0, 10, 0)
+	cpu.Execute(NewInstruction(cpu.raw))
+	if pa, _ := cpu.translate(0x0040_0000, accessLoad); pa != RAMBase {
+		t.Errorf("new translation must be used, but was 0x%08x", pa)
+	}
+
+	// sfence.vma is illegal in U-mode
+	cpu.Priv = PrivU
+	cpu.Execute(NewInstruction(cpu.raw))
+	var trap *Trap
+	if !errors.As(cpu.err, &trap) || trap.Cause != CauseIllegalInstruction {
+		t.Errorf("sfence.vma must be illegal in U-mode, but was %v", cpu.err)
+	}
+}
+
+func Test_PageFault(t *testing.T) {
+	e := newMMUEmulator()
+	f := &e.Cpu.Csr
+	f.Medeleg = 1 << CauseLoadPageFault
+	f.Stvec = 0xc000_0200
+	// S-mode code in the kernel megapage
+	loadCode(e, RAMBase+0x100,
+		GenCode(OpLui, 5, 0x00400, 0),
+		GenCode(OpLw, 10, 0, 5),
+	)
+	loadCode(e, RAMBase+0x200, GenCode(OpAddi, 11, 0, 1))
+	e.Cpu.PC = 0xc000_0100
+
+	if err := e.StepUntil(0xc000_0204); err != nil {
+		t.Fatal(err)
+	}
+	if f.Scause != uint32(CauseLoadPageFault) || f.Stval != 0x0040_0000 || f.Sepc != 0xc000_0104 {
+		t.Errorf("load page fault must be taken, but scause:%d, stval:0x%08x, sepc:0x%08x", f.Scause, f.Stval, f.Sepc)
+	}
+	if e.Cpu.X[11] != 1 {
+		t.Errorf("X11 must be %d, but was %d", 1, e.Cpu.X[11])
+	}
+}
@@ -4,3 +4,6 @@ package rv32i
 
 func trace(args ...interface{}) {
 }
+
+func tracef(format string, args ...interface{}) {
+}
@@ -55,15 +55,92 @@ func _() {
 	_ = x[OpCsrrwi-44]
 	_ = x[OpCsrrsi-45]
 	_ = x[OpCsrrci-46]
+	_ = x[OpSret-47]
+	_ = x[OpMret-48]
+	_ = x[OpWfi-49]
+	_ = x[OpSfenceVma-50]
+	_ = x[OpMul-51]
+	_ = x[OpMulh-52]
+	_ = x[OpMulhsu-53]
+	_ = x[OpMulhu-54]
+	_ = x[OpDiv-55]
+	_ = x[OpDivu-56]
+	_ = x[OpRem-57]
+	_ = x[OpRemu-58]
+	_ = x[OpLrW-59]
+	_ = x[OpScW-60]
+	_ = x[OpAmoswapW-61]
+	_ = x[OpAmoaddW-62]
+	_ = x[OpAmoxorW-63]
+	_ = x[OpAmoandW-64]
+	_ = x[OpAmoorW-65]
+	_ = x[OpAmominW-66]
+	_ = x[OpAmomaxW-67]
+	_ = x[OpAmominuW-68]
+	_ = x[OpAmomaxuW-69]
+	_ = x[OpFlw-70]
+	_ = x[OpFsw-71]
+	_ = x[OpFmaddS-72]
+	_ = x[OpFmsubS-73]
+	_ = x[OpFnmsubS-74]
+	_ = x[OpFnmaddS-75]
+	_ = x[OpFaddS-76]
+	_ = x[OpFsubS-77]
+	_ = x[OpFmulS-78]
+	_ = x[OpFdivS-79]
+	_ = x[OpFsqrtS-80]
+	_ = x[OpFsgnjS-81]
+	_ = x[OpFsgnjnS-82]
+	_ = x[OpFsgnjxS-83]
+	_ = x[OpFminS-84]
+	_ = x[OpFmaxS-85]
+	_ = x[OpFcvtWS-86]
+	_ = x[OpFcvtWuS-87]
+	_ = x[OpFmvXW-88]
+	_ = x[OpFeqS-89]
+	_ = x[OpFltS-90]
+	_ = x[OpFleS-91]
+	_ = x[OpFclassS-92]
+	_ = x[OpFcvtSW-93]
+	_ = x[OpFcvtSWu-94]
+	_ = x[OpFmvWX-95]
+	_ = x[OpFld-96]
+	_ = x[OpFsd-97]
+	_ = x[OpFmaddD-98]
+	_ = x[OpFmsubD-99]
+	_ = x[OpFnmsubD-100]
+	_ = x[OpFnmaddD-101]
+	_ = x[OpFaddD-102]
+	_ = x[OpFsubD-103]
+	_ = x[OpFmulD-104]
+	_ = x[OpFdivD-105]
+	_ = x[OpFsqrtD-106]
+	_ = x[OpFsgnjD-107]
+	_ = x[OpFsgnjnD-108]
+	_ = x[OpFsgnjxD-109]
+	_ = x[OpFminD-110]
+	_ = x[OpFmaxD-111]
+	_ = x[OpFcvtSD-112]
+	_ = x[OpFcvtDS-113]
+	_ = x[OpFeqD-114]
+	_ = x[OpFltD-115]
+	_ = x[OpFleD-116]
+	_ = x[OpFclassD-117]
+	_ = x[OpFcvtWD-118]
+	_ = x[OpFcvtWuD-119]
+	_ = x[OpFcvtDW-120]
+	_ = x[OpFcvtDWu-121]
+	_ = x[OpInvalid-122]
 }
 
-const _OpName_name = "OpLuiOpAuipcOpJalOpJalrOpBeqOpBneOpBltOpBgeOpBltuOpBgeuOpLbOpLhOpLwOpLbuOpLhuOpSbOpShOpSwOpAddiOpSltiOpSltiuOpXoriOpOriOpAndiOpSlliOpSrliOpSraiOpAddOpSubOpSllOpSltOpSltuOpXorOpSrlOpSraOpOrOpAndOpFenceOpFenceIOpEcallOpEbreakOpCsrrwOpCsrrsOpCsrrcOpCsrrwiOpCsrrsiOpCsrrci"
+const _OpName_name = "OpLuiOpAuipcOpJalOpJalrOpBeqOpBneOpBltOpBgeOpBltuOpBgeuOpLbOpLhOpLwOpLbuOpLhuOpSbOpShOpSwOpAddiOpSltiOpSltiuOpXoriOpOriOpAndiOpSlliOpSrliOpSraiOpAddOpSubOpSllOpSltOpSltuOpXorOpSrlOpSraOpOrOpAndOpFenceOpFenceIOpEcallOpEbreakOpCsrrwOpCsrrsOpCsrrcOpCsrrwiOpCsrrsiOpCsrrciOpSretOpMretOpWfiOpSfenceVmaOpMulOpMulhOpMulhsuOpMulhuOpDivOpDivuOpRemOpRemuOpLrWOpScWOpAmoswapWOpAmoaddWOpAmoxorWOpAmoandWOpAmoorWOpAmominWOpAmomaxWOpAmominuWOpAmomaxuWOpFlwOpFswOpFmaddSOpFmsubSOpFnmsubSOpFnmaddSOpFaddSOpFsubSOpFmulSOpFdivSOpFsqrtSOpFsgnjSOpFsgnjnSOpFsgnjxSOpFminSOpFmaxSOpFcvtWSOpFcvtWuSOpFmvXWOpFeqSOpFltSOpFleSOpFclassSOpFcvtSWOpFcvtSWuOpFmvWXOpFldOpFsdOpFmaddDOpFmsubDOpFnmsubDOpFnmaddDOpFaddDOpFsubDOpFmulDOpFdivDOpFsqrtDOpFsgnjDOpFsgnjnDOpFsgnjxDOpFminDOpFmaxDOpFcvtSDOpFcvtDSOpFeqDOpFltDOpFleDOpFclassDOpFcvtWDOpFcvtWuDOpFcvtDWOpFcvtDWuOpInvalid"
 
-var _OpName_index = [...]uint16{0, 5, 12, 17, 23, 28, 33, 38, 43, 49, 55, 59, 63, 67, 72, 77, 81, 85, 89, 95, 101, 108, 114, 119, 125, 131, 137, 143, 148, 153, 158, 163, 169, 174, 179, 184, 188, 193, 200, 208, 215, 223, 230, 237, 244, 252, 260, 268}
+var _OpName_index = [...]uint16{0, 5, 12, 17, 23, 28, 33, 38, 43, 49, 55, 59, 63, 67, 72, 77, 81, 85, 89, 95, 101, 108, 114, 119, 125, 131, 137, 143, 148, 153, 158, 163, 169, 174, 179, 184, 188, 193, 200, 208, 215, 223, 230, 237, 244, 252, 260, 268, 274, 280, 285, 296, 301, 307, 315, 322, 327, 333, 338, 344, 349, 354, 364, 373, 382, 391, 399, 408, 417, 427, 437, 442, 447, 455, 463, 472, 481, 488, 495, 502, 509, 517, 525, 534, 543, 550, 557, 565, 574, 581, 587, 593, 599, 608, 616, 625, 632, 637, 642, 650, 658, 667, 676, 683, 690, 697, 704, 712, 720, 729, 738, 745, 752, 760, 768, 774, 780, 786, 795, 803, 812, 820, 829, 838}
 
 func (i OpName) String() string {
-	if i < 0 || i >= OpName(len(_OpName_index)-1) {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_OpName_index)-1 {
 		return "OpName(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _OpName_name[_OpName_index[i]:_OpName_index[i+1]]
+	return _OpName_name[_OpName_index[idx]:_OpName_index[idx+1]]
 }
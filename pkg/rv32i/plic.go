@@ -0,0 +1,213 @@
+package rv32i
+
+// default PLIC mapping, same as QEMU virt
+const (
+	PLICBase = uint32(0x0c00_0000)
+	PLICSize = uint32(0x0400_0000)
+)
+
+// UARTIRQ is the PLIC source of the UART in cmd/demo, same as QEMU virt
+const UARTIRQ = uint32(10)
+
+// PLIC registers
+const (
+	plicPriority  = uint32(0x00_0000) // 4 bytes per source
+	plicPending   = uint32(0x00_1000) // 1 bit per source
+	plicEnable    = uint32(0x00_2000) // 1 bit per source, 0x80 bytes per context
+	plicContext   = uint32(0x20_0000) // threshold and claim/complete, 0x1000 bytes per context
+	plicThreshold = uint32(0)
+	plicClaim     = uint32(4)
+
+	plicEnableStride  = uint32(0x80)
+	plicContextStride = uint32(0x1000)
+)
+
+const (
+	plicSources     = 1024 // source 0 means no interrupt
+	plicPriorityMax = uint32(7)
+)
+
+// InterruptSource is a device which has an interrupt line
+type InterruptSource interface {
+	// Interrupt returns true while the device requests an interrupt
+	Interrupt() bool
+}
+
+// PLIC is the platform-level interrupt controller.
+// Each hart has 2 contexts, 2*hart for M-mode (mip.MEIP) and 2*hart+1 for
+// S-mode (mip.SEIP). Interrupt lines are level-triggered.
+type PLIC struct {
+	harts     []*Cpu
+	lines     map[uint32]InterruptSource
+	levels    map[uint32]bool // lines set by SetLevel
+	priority  [plicSources]uint32
+	pending   [plicSources / 32]uint32
+	claimed   [plicSources / 32]uint32 // claimed but not completed yet
+	enable    [][plicSources / 32]uint32
+	threshold []uint32
+}
+
+func NewPLIC(harts ...*Cpu) *PLIC {
+	contexts := 2 * len(harts)
+	return &PLIC{
+		harts:     harts,
+		lines:     map[uint32]InterruptSource{},
+		levels:    map[uint32]bool{},
+		enable:    make([][plicSources / 32]uint32, contexts),
+		threshold: make([]uint32, contexts),
+	}
+}
+
+// Connect connects the interrupt line of the device to the source
+func (p *PLIC) Connect(source uint32, dev InterruptSource) {
+	p.lines[source] = dev
+}
+
+// SetLevel sets the interrupt line of the source which has no device
+func (p *PLIC) SetLevel(source uint32, level bool) {
+	p.levels[source] = level
+	p.update()
+}
+
+func isSet(bits []uint32, source uint32) bool {
+	return bits[source/32]&(1<<(source%32)) != 0
+}
+
+func setBit(bits []uint32, source uint32, v bool) {
+	if v {
+		bits[source/32] |= 1 << (source % 32)
+	} else {
+		bits[source/32] &^= 1 << (source % 32)
+	}
+}
+
+// gateway makes a source pending when its line is high and it's not being
+// handled
+func (p *PLIC) gateway(source uint32, level bool) {
+	if level && !isSet(p.claimed[:], source) {
+		setBit(p.pending[:], source, true)
+	}
+}
+
+// best returns the pending and enabled source with the highest priority over
+// the threshold of the context, or 0
+func (p *PLIC) best(ctx int) uint32 {
+	best, max := uint32(0), p.threshold[ctx]
+	for i, bits := range p.pending {
+		bits &= p.enable[ctx][i]
+		for bits != 0 {
+			bit := uint32(0)
+			for bits&(1<<bit) == 0 {
+				bit++
+			}
+			bits &^= 1 << bit
+			source := uint32(i)*32 + bit
+			if p.priority[source] > max {
+				best, max = source, p.priority[source]
+			}
+		}
+	}
+	return best
+}
+
+// update samples the interrupt lines and sets mip.MEIP and mip.SEIP of the
+// harts
+func (p *PLIC) update() {
+	for source, dev := range p.lines {
+		p.gateway(source, dev.Interrupt())
+	}
+	for source, level := range p.levels {
+		p.gateway(source, level)
+	}
+	for h, cpu := range p.harts {
+		mip := cpu.Csr.Mip &^ (MipMEIP | MipSEIP)
+		if p.best(2*h) != 0 {
+			mip |= MipMEIP
+		}
+		if p.best(2*h+1) != 0 {
+			mip |= MipSEIP
+		}
+		cpu.Csr.Mip = mip
+	}
+}
+
+func (p *PLIC) context(offset uint32) (int, uint32, bool) {
+	ctx := int((offset - plicContext) / plicContextStride)
+	return ctx, (offset - plicContext) % plicContextStride, ctx < len(p.threshold)
+}
+
+func (p *PLIC) Read(offset uint32, size uint32) (uint32, error) {
+	switch {
+	case offset < plicPending:
+		if source := offset / 4; source > 0 {
+			return p.priority[source], nil
+		}
+	case offset < plicEnable:
+		if i := (offset - plicPending) / 4; i < plicSources/32 {
+			return p.pending[i], nil
+		}
+	case offset < plicContext:
+		ctx, i := int((offset-plicEnable)/plicEnableStride), (offset-plicEnable)%plicEnableStride/4
+		if ctx < len(p.enable) && i < plicSources/32 {
+			return p.enable[ctx][i], nil
+		}
+	default:
+		ctx, reg, ok := p.context(offset)
+		if !ok {
+			break
+		}
+		switch reg {
+		case plicThreshold:
+			return p.threshold[ctx], nil
+		case plicClaim:
+			source := p.best(ctx)
+			if source != 0 {
+				setBit(p.pending[:], source, false)
+				setBit(p.claimed[:], source, true)
+				p.update()
+			}
+			return source, nil
+		}
+	}
+	return 0, nil
+}
+
+func (p *PLIC) Write(offset uint32, size uint32, data uint32) error {
+	switch {
+	case offset < plicPending:
+		if source := offset / 4; source > 0 {
+			p.priority[source] = data & plicPriorityMax
+		}
+	case offset < plicEnable:
+		// pending bits are read-only
+	case offset < plicContext:
+		ctx, i := int((offset-plicEnable)/plicEnableStride), (offset-plicEnable)%plicEnableStride/4
+		if ctx < len(p.enable) && i < plicSources/32 {
+			if i == 0 {
+				// source 0 doesn't exist
+				data &^= 1
+			}
+			p.enable[ctx][i] = data
+		}
+	default:
+		ctx, reg, ok := p.context(offset)
+		if !ok {
+			break
+		}
+		switch reg {
+		case plicThreshold:
+			p.threshold[ctx] = data & plicPriorityMax
+		case plicClaim:
+			// complete is ignored if the source is not enabled for the context
+			if data < plicSources && isSet(p.enable[ctx][:], data) {
+				setBit(p.claimed[:], data, false)
+			}
+		}
+	}
+	p.update()
+	return nil
+}
+
+func (p *PLIC) Tick() {
+	p.update()
+}
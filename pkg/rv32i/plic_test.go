@@ -0,0 +1,110 @@
+package rv32i
+
+import (
+	"testing"
+)
+
+func Test_PLIC(t *testing.T) {
+	cpu := NewCpu()
+	p := NewPLIC(cpu)
+	mctx := plicContext
+	sctx := plicContext + plicContextStride
+
+	p.Write(plicPriority+4*3, 4, 2)
+	p.Write(plicPriority+4*5, 4, 0xff)
+	if v, _ := p.Read(plicPriority+4*5, 4); v != plicPriorityMax {
+		t.Errorf("priority must be masked to %d, but was %d", plicPriorityMax, v)
+	}
+
+	// pending but not enabled
+	p.SetLevel(3, true)
+	p.SetLevel(5, true)
+	if v, _ := p.Read(plicPending, 4); v != 1<<3|1<<5 {
+		t.Errorf("pending must be 0x%x, but was 0x%x", 1<<3|1<<5, v)
+	}
+	if cpu.Csr.Mip&MipMEIP != 0 {
+		t.Error("mip.MEIP must not be set without enables")
+	}
+
+	// M context has 3 and 5, S context has 3
+	p.Write(plicEnable, 4, 1<<3|1<<5)
+	p.Write(plicEnable+plicEnableStride, 4, 1<<3)
+	if cpu.Csr.Mip&(MipMEIP|MipSEIP) != MipMEIP|MipSEIP {
+		t.Errorf("mip.MEIP and SEIP must be set, but mip was 0x%x", cpu.Csr.Mip)
+	}
+
+	// threshold masks lower priorities
+	p.Write(sctx+plicThreshold, 4, 2)
+	if cpu.Csr.Mip&MipSEIP != 0 {
+		t.Error("mip.SEIP must be masked by the threshold")
+	}
+
+	// claim returns the highest priority
+	if v, _ := p.Read(mctx+plicClaim, 4); v != 5 {
+		t.Errorf("claim must return 5, but was %d", v)
+	}
+	if v, _ := p.Read(mctx+plicClaim, 4); v != 3 {
+		t.Errorf("claim must return 3, but was %d", v)
+	}
+	if v, _ := p.Read(mctx+plicClaim, 4); v != 0 {
+		t.Errorf("claim must return 0, but was %d", v)
+	}
+	if cpu.Csr.Mip&MipMEIP != 0 {
+		t.Error("mip.MEIP must be cleared after claims")
+	}
+
+	// the line is still high, so it's pending again after complete
+	p.SetLevel(3, false)
+	p.Write(mctx+plicClaim, 4, 3)
+	p.Write(mctx+plicClaim, 4, 5)
+	p.Tick()
+	if v, _ := p.Read(plicPending, 4); v != 1<<5 {
+		t.Errorf("pending must be 0x%x, but was 0x%x", 1<<5, v)
+	}
+}
+
+func Test_ExternalInterrupt(t *testing.T) {
+	u := NewUART(nil, nil)
+	cfg := DefaultEmulatorConfig()
+	cfg.Devices = append(cfg.Devices, Mapping{Base: UARTBase, Size: UARTSize, Device: u, IRQ: UARTIRQ})
+	e, err := NewEmulatorWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.WriteU32(PLICBase+plicPriority+4*UARTIRQ, 1)
+	e.WriteU32(PLICBase+plicEnable, 1<<UARTIRQ)
+	e.Cpu.Csr.Mtvec = 0x100
+	e.Cpu.Csr.Mie = MipMEIP
+	e.Cpu.Csr.Mstatus |= MstatusMIE
+	// handler claims the interrupt to a0
+	loadCode(e, 0, GenCode(OpJal, 0, 0, 0))
+	loadCode(e, 0x100,
+		GenCode(OpLui, 11, int(PLICBase+plicContext)>>12, 0),
+		GenCode(OpLw, 10, int(plicClaim), 11),
+	)
+
+	e.Step()
+	if e.Cpu.Csr.Mcause != 0 {
+		t.Fatalf("no interrupt must be taken, but mcause was 0x%08x", e.Cpu.Csr.Mcause)
+	}
+
+	// a byte received in loopback mode
+	u.Write(uartIER, 1, uint32(uartIerRDA))
+	u.Write(uartMCR, 1, uint32(uartMcrLoop))
+	u.Write(uartRBR, 1, 'x')
+	e.Step()
+	e.Step()
+	if e.Cpu.Csr.Mcause != uint32(CauseMachineExternalInterrupt) {
+		t.Errorf("external interrupt must be taken, but mcause was 0x%08x", e.Cpu.Csr.Mcause)
+	}
+	if e.Cpu.X[10] != UARTIRQ {
+		t.Errorf("claim must return %d, but was %d", UARTIRQ, e.Cpu.X[10])
+	}
+
+	// devices without interrupts can't be connected
+	cfg.Devices = []Mapping{{Base: 0x2000_0000, Size: 0x1000, Device: NewROM(0x1000), IRQ: 1}}
+	if _, err = NewEmulatorWithConfig(cfg); err == nil {
+		t.Error("ROM must not be connected to the PLIC")
+	}
+}
@@ -0,0 +1,142 @@
+package rv32i
+
+import (
+	"fmt"
+)
+
+// PMP CSR addresses
+const (
+	CsrPmpcfg0  = uint32(0x3a0) // pmpcfg0-3
+	CsrPmpaddr0 = uint32(0x3b0) // pmpaddr0-15
+)
+
+// PmpEntries is the number of PMP entries
+const PmpEntries = 16
+
+// pmpcfg fields
+const (
+	PmpR = uint8(1 << 0)
+	PmpW = uint8(1 << 1)
+	PmpX = uint8(1 << 2)
+	PmpA = uint8(0b11 << 3)
+	PmpL = uint8(1 << 7)
+
+	PmpOff   = uint8(0 << 3)
+	PmpTOR   = uint8(1 << 3)
+	PmpNA4   = uint8(2 << 3)
+	PmpNAPOT = uint8(3 << 3)
+
+	pmpcfgMask = PmpR | PmpW | PmpX | PmpA | PmpL
+)
+
+func init() {
+	for i := uint32(0); i < PmpEntries/4; i++ {
+		csrNames[CsrPmpcfg0+i] = fmt.Sprintf("pmpcfg%d", i)
+	}
+	for i := uint32(0); i < PmpEntries; i++ {
+		csrNames[CsrPmpaddr0+i] = fmt.Sprintf("pmpaddr%d", i)
+	}
+}
+
+// readPmp reads pmpcfg* and pmpaddr*. It returns false for other CSRs.
+func (c *Cpu) readPmp(addr uint32) (uint32, bool) {
+	f := &c.Csr
+	switch {
+	case addr >= CsrPmpcfg0 && addr < CsrPmpcfg0+PmpEntries/4:
+		var data uint32
+		for i := uint32(0); i < 4; i++ {
+			data |= uint32(f.Pmpcfg[(addr-CsrPmpcfg0)*4+i]) << (8 * i)
+		}
+		return data, true
+	case addr >= CsrPmpaddr0 && addr < CsrPmpaddr0+PmpEntries:
+		return f.Pmpaddr[addr-CsrPmpaddr0], true
+	default:
+		return 0, false
+	}
+}
+
+// writePmp writes pmpcfg* and pmpaddr*. It returns false for other CSRs.
+// Writes to locked entries are ignored.
+func (c *Cpu) writePmp(addr uint32, data uint32) bool {
+	f := &c.Csr
+	switch {
+	case addr >= CsrPmpcfg0 && addr < CsrPmpcfg0+PmpEntries/4:
+		for i := uint32(0); i < 4; i++ {
+			n := (addr-CsrPmpcfg0)*4 + i
+			if f.Pmpcfg[n]&PmpL != 0 {
+				continue
+			}
+			cfg := uint8(data>>(8*i)) & pmpcfgMask
+			// R=0 and W=1 is reserved
+			if cfg&PmpR == 0 {
+				cfg &^= PmpW
+			}
+			f.Pmpcfg[n] = cfg
+		}
+		return true
+	case addr >= CsrPmpaddr0 && addr < CsrPmpaddr0+PmpEntries:
+		n := addr - CsrPmpaddr0
+		locked := f.Pmpcfg[n]&PmpL != 0
+		// the top of a locked TOR range is locked as well
+		if n+1 < PmpEntries && f.Pmpcfg[n+1]&(PmpL|PmpA) == PmpL|PmpTOR {
+			locked = true
+		}
+		if !locked {
+			f.Pmpaddr[n] = data
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// pmpRange returns the byte range lo..hi of the entry n. pmpaddr has bits
+// 33:2 of the 34-bit physical address.
+func (f *CsrFile) pmpRange(n int) (lo uint64, hi uint64) {
+	addr := uint64(f.Pmpaddr[n])
+	switch f.Pmpcfg[n] & PmpA {
+	case PmpTOR:
+		if n > 0 {
+			lo = uint64(f.Pmpaddr[n-1]) << 2
+		}
+		return lo, addr << 2
+	case PmpNA4:
+		return addr << 2, addr<<2 + 4
+	case PmpNAPOT:
+		// trailing ones encode the size, 8 << ones
+		ones := addr ^ (addr + 1)
+		return (addr &^ ones) << 2, (addr&^ones)<<2 + (ones+1)<<2
+	default:
+		return 0, 0
+	}
+}
+
+// pmpCheck returns true if priv can access size bytes at the physical address
+// pa with perm. The lowest matching entry decides. M-mode is only checked by
+// locked entries. S and U-mode accesses which match no entry fail, unless no
+// entry is enabled.
+func (c *Cpu) pmpCheck(pa uint32, size uint32, priv uint32, perm uint8) bool {
+	f := &c.Csr
+	enabled := false
+	start, end := uint64(pa), uint64(pa)+uint64(size)
+	for n := 0; n < PmpEntries; n++ {
+		cfg := f.Pmpcfg[n]
+		if cfg&PmpA == PmpOff {
+			continue
+		}
+		enabled = true
+		lo, hi := f.pmpRange(n)
+		if end <= lo || start >= hi {
+			continue
+		}
+		// accesses partially matching an entry fail
+		if start < lo || end > hi {
+			return false
+		}
+		if priv == PrivM && cfg&PmpL == 0 {
+			return true
+		}
+		return cfg&perm == perm
+	}
+	return priv == PrivM || !enabled
+}
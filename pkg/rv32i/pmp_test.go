@@ -0,0 +1,119 @@
+package rv32i
+
+import (
+	"testing"
+)
+
+func Test_PmpCsr(t *testing.T) {
+	cpu := NewCpu()
+
+	// W without R is cleared
+	cpu.WriteCsr(CsrPmpcfg0, 0x60_8f_02_1b)
+	if v, _ := cpu.ReadCsr(CsrPmpcfg0); v != 0x00_8f_00_1b {
+		t.Errorf("pmpcfg0 must be 0x008f001b, but was 0x%08x", v)
+	}
+	if CsrName(CsrPmpcfg0+3) != "pmpcfg3" || CsrName(CsrPmpaddr0+15) != "pmpaddr15" {
+		t.Errorf("wrong names %s, %s", CsrName(CsrPmpcfg0+3), CsrName(CsrPmpaddr0+15))
+	}
+
+	// entry 2 is locked TOR, so pmpaddr1 and pmpaddr2 are locked
+	cpu = NewCpu()
+	cpu.WriteCsr(CsrPmpaddr0+1, 0x100)
+	cpu.WriteCsr(CsrPmpaddr0+2, 0x200)
+	cpu.WriteCsr(CsrPmpcfg0, uint32(PmpL|PmpTOR|PmpR)<<16)
+	cpu.WriteCsr(CsrPmpcfg0, 0)
+	cpu.WriteCsr(CsrPmpaddr0+1, 0x111)
+	cpu.WriteCsr(CsrPmpaddr0+2, 0x222)
+	cpu.WriteCsr(CsrPmpaddr0+3, 0x333)
+	if v, _ := cpu.ReadCsr(CsrPmpcfg0); v != uint32(PmpL|PmpTOR|PmpR)<<16 {
+		t.Errorf("locked pmpcfg must not be written, but pmpcfg0 was 0x%08x", v)
+	}
+	for i, want := range []uint32{0, 0x100, 0x200, 0x333} {
+		if cpu.Csr.Pmpaddr[i] != want {
+			t.Errorf("pmpaddr%d must be 0x%x, but was 0x%x", i, want, cpu.Csr.Pmpaddr[i])
+		}
+	}
+}
+
+func Test_PmpCheck(t *testing.T) {
+	cpu := NewCpu()
+	// 0: TOR 0x1000-0x2000 RX
+	// 1: NA4 0x3000 RW
+	// 2: NAPOT 0x4000-0x5000 R, locked
+	// 3: NAPOT everything RWX
+	cpu.WriteCsr(CsrPmpaddr0+0, 0x1000>>2)
+	cpu.WriteCsr(CsrPmpaddr0+1, 0x2000>>2)
+	cpu.WriteCsr(CsrPmpaddr0+2, 0x3000>>2)
+	cpu.WriteCsr(CsrPmpaddr0+3, 0x4000>>2|(0x1000>>3-1))
+	cpu.WriteCsr(CsrPmpaddr0+4, 0xffffffff)
+	cpu.WriteCsr(CsrPmpcfg0, uint32(PmpNAPOT|PmpR|PmpL)<<24|uint32(PmpNA4|PmpR|PmpW)<<16|uint32(PmpTOR|PmpR|PmpX)<<8)
+
+	type TestData struct {
+		Addr uint32
+		Size uint32
+		Priv uint32
+		Perm uint8
+		Want bool
+	}
+
+	for _, td := range []TestData{
+		// nothing matches
+		{0x0000, 4, PrivU, PmpR, false},
+		{0x0000, 4, PrivM, PmpW, true},
+		// TOR
+		{0x1000, 4, PrivU, PmpX, true},
+		{0x1ffc, 4, PrivS, PmpR, true},
+		{0x1ffc, 4, PrivS, PmpW, false},
+		{0x1ffc, 4, PrivM, PmpW, true},
+		{0x1ffe, 4, PrivM, PmpR, false}, // partial
+		// NA4
+		{0x3000, 4, PrivU, PmpW, true},
+		{0x3004, 4, PrivU, PmpW, false},
+		// NAPOT, locked
+		{0x4ffc, 4, PrivU, PmpR, true},
+		{0x4000, 1, PrivM, PmpW, false},
+		{0x5000, 4, PrivU, PmpR, false},
+	} {
+		if got := cpu.pmpCheck(td.Addr, td.Size, td.Priv, td.Perm); got != td.Want {
+			t.Errorf("0x%08x, size:%d, priv:%d, perm:%x must be %v", td.Addr, td.Size, td.Priv, td.Perm, td.Want)
+		}
+	}
+
+	// the last entry matches everything
+	cpu.WriteCsr(CsrPmpcfg0+1, uint32(PmpNAPOT|PmpR|PmpW|PmpX))
+	if !cpu.pmpCheck(0x8000_0000, 4, PrivU, PmpR|PmpW|PmpX) {
+		t.Error("NAPOT of pmpaddr 0xffffffff must match everything")
+	}
+}
+
+func Test_PmpFault(t *testing.T) {
+	e := NewEmulator()
+	f := &e.Cpu.Csr
+	// U-mode can execute 0-0x1000 and read and write 0x1000-0x2000
+	e.Cpu.WriteCsr(CsrPmpaddr0+0, 0x1000>>2)
+	e.Cpu.WriteCsr(CsrPmpaddr0+1, 0x2000>>2)
+	e.Cpu.WriteCsr(CsrPmpcfg0, uint32(PmpTOR|PmpR|PmpW)<<8|uint32(PmpTOR|PmpX))
+	f.Mtvec = 0x3000
+	loadCode(e, 0,
+		GenCode(OpLui, 5, 1, 0),
+		GenCode(OpSw, 0, 0, 5),
+		GenCode(OpLw, 10, 0, 0),
+	)
+	loadCode(e, 0x3000, GenCode(OpAddi, 0, 0, 0))
+	e.Cpu.Priv = PrivU
+
+	if err := e.StepUntil(0x3004); err != nil {
+		t.Fatal(err)
+	}
+	if f.Mcause != uint32(CauseLoadAccessFault) || f.Mtval != 0 || f.Mepc != 8 {
+		t.Errorf("load access fault must be taken, but mcause:%d, mtval:0x%08x, mepc:0x%08x", f.Mcause, f.Mtval, f.Mepc)
+	}
+
+	// instruction fetch outside of the entries
+	e.Cpu.Priv = PrivU
+	e.Cpu.PC = 0x1000
+	e.Step()
+	if f.Mcause != uint32(CauseInstructionAccessFault) || f.Mtval != 0x1000 {
+		t.Errorf("instruction access fault must be taken, but mcause:%d, mtval:0x%08x", f.Mcause, f.Mtval)
+	}
+}
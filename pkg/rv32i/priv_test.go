@@ -0,0 +1,153 @@
+package rv32i
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Delegation(t *testing.T) {
+	e := NewEmulator()
+	f := &e.Cpu.Csr
+	// M-mode: delegate ecall from U to S, then mret to S at 0x100
+	loadCode(e, 0,
+		GenCode(OpAddi, 5, 0, 1<<CauseEnvironmentCallFromU),
+		GenCode(OpCsrrw, 0, int(CsrMedeleg), 5),
+		GenCode(OpAddi, 5, 0, 0x200),
+		GenCode(OpCsrrw, 0, int(CsrStvec), 5),
+		GenCode(OpAddi, 5, 0, 0x100),
+		GenCode(OpCsrrw, 0, int(CsrMepc), 5),
+		GenCode(OpLui, 5, 1, 0),
+		GenCode(OpAddi, 5, 5, -0x800), // MPP = S
+		GenCode(OpCsrrw, 0, int(CsrMstatus), 5),
+		GenCode(OpMret, 0, 0, 0),
+	)
+	// S-mode: sret to U at 0x180, SPP is U
+	loadCode(e, 0x100,
+		GenCode(OpAddi, 5, 0, 0x180),
+		GenCode(OpCsrrw, 0, int(CsrSepc), 5),
+		GenCode(OpSret, 0, 0, 0),
+	)
+	// U-mode: ecall
+	loadCode(e, 0x180, GenCode(OpEcall, 0, 0, 0))
+	loadCode(e, 0x200, GenCode(OpAddi, 10, 0, 1))
+
+	e.StepUntil(0x100)
+	if e.Cpu.Priv != PrivS {
+		t.Fatalf("Priv must be S after mret, but was %d", e.Cpu.Priv)
+	}
+	e.StepUntil(0x180)
+	if e.Cpu.Priv != PrivU {
+		t.Fatalf("Priv must be U after sret, but was %d", e.Cpu.Priv)
+	}
+	if err := e.StepUntil(0x204); err != nil {
+		t.Fatal(err)
+	}
+	if e.Cpu.Priv != PrivS || f.Scause != uint32(CauseEnvironmentCallFromU) || f.Sepc != 0x180 {
+		t.Errorf("ecall must be taken in S, but priv:%d, scause:%d, sepc:0x%08x", e.Cpu.Priv, f.Scause, f.Sepc)
+	}
+	if f.Mstatus&MstatusSPP != 0 || f.Mcause != 0 {
+		t.Errorf("wrong mstatus:0x%08x, mcause:%d", f.Mstatus, f.Mcause)
+	}
+	if e.Cpu.X[10] != 1 {
+		t.Errorf("X10 must be %d, but was %d", 1, e.Cpu.X[10])
+	}
+
+	// ecall from S isn't delegated
+	f.Mtvec = 0x300
+	e.Cpu.PC = 0x180
+	loadCode(e, 0x300, GenCode(OpAddi, 0, 0, 0))
+	e.Step()
+	if e.Cpu.Priv != PrivM || f.Mcause != uint32(CauseEnvironmentCallFromS) || f.Mstatus&MstatusMPP != PrivS<<11 {
+		t.Errorf("ecall must be taken in M, but priv:%d, mcause:%d, mstatus:0x%08x", e.Cpu.Priv, f.Mcause, f.Mstatus)
+	}
+}
+
+func Test_PrivilegeChecks(t *testing.T) {
+	type TestData struct {
+		Priv    uint32
+		Mstatus uint32
+		Code    uint32
+		Illegal bool
+	}
+
+	for _, td := range []TestData{
+		{PrivU, 0, GenCode(OpCsrrs, 10, int(CsrSstatus), 0), true},
+		{PrivU, 0, GenCode(OpCsrrs, 10, int(CsrCycle), 0), true},
+		{PrivS, 0, GenCode(OpCsrrs, 10, int(CsrSstatus), 0), false},
+		{PrivS, 0, GenCode(OpCsrrs, 10, int(CsrMstatus), 0), true},
+		{PrivS, 0, GenCode(OpCsrrs, 10, int(CsrSatp), 0), false},
+		{PrivS, MstatusTVM, GenCode(OpCsrrs, 10, int(CsrSatp), 0), true},
+		{PrivM, MstatusTVM, GenCode(OpCsrrs, 10, int(CsrSatp), 0), false},
+		{PrivU, 0, GenCode(OpSret, 0, 0, 0), true},
+		{PrivS, 0, GenCode(OpSret, 0, 0, 0), false},
+		{PrivS, MstatusTSR, GenCode(OpSret, 0, 0, 0), true},
+		{PrivS, 0, GenCode(OpMret, 0, 0, 0), true},
+		{PrivS, 0, GenCode(OpWfi, 0, 0, 0), false},
+		{PrivS, MstatusTW, GenCode(OpWfi, 0, 0, 0), true},
+		{PrivM, MstatusTW, GenCode(OpWfi, 0, 0, 0), false},
+	} {
+		cpu := NewCpu()
+		cpu.Emu = NewEmulator()
+		cpu.Priv = td.Priv
+		cpu.Csr.Mstatus |= td.Mstatus
+		cpu.raw = td.Code
+		cpu.Execute(NewInstruction(td.Code))
+		var trap *Trap
+		illegal := errors.As(cpu.err, &trap) && trap.Cause == CauseIllegalInstruction
+		if illegal != td.Illegal {
+			t.Errorf("0x%08x in priv %d: illegal must be %v, but err was %v", td.Code, td.Priv, td.Illegal, cpu.err)
+		}
+	}
+}
+
+func Test_CounterEnable(t *testing.T) {
+	cpu := NewCpu()
+	cpu.Emu = NewEmulator()
+	cpu.Priv = PrivU
+	cpu.Csr.Mcounteren = CounterenCY
+	cpu.Csr.Scounteren = CounterenCY
+	cpu.Csr.Cycle = 42
+	code := GenCode(OpCsrrs, 10, int(CsrCycle), 0)
+	cpu.Execute(NewInstruction(code))
+	if cpu.err != nil || cpu.X[10] != 42 {
+		t.Errorf("cycle must be readable, but X10:%d, err:%v", cpu.X[10], cpu.err)
+	}
+
+	code = GenCode(OpCsrrs, 10, int(CsrInstret), 0)
+	cpu.Execute(NewInstruction(code))
+	if cpu.err == nil {
+		t.Error("instret must not be readable")
+	}
+}
+
+func Test_SupervisorInterrupt(t *testing.T) {
+	e := NewEmulator()
+	f := &e.Cpu.Csr
+	loadCode(e, 0, GenCode(OpJal, 0, 0, 0))
+	loadCode(e, 0x200, GenCode(OpAddi, 0, 0, 0))
+	f.Mtvec = 0x100
+	f.Stvec = 0x200
+	f.Mideleg = MipSSIP
+	f.Mie = MipSSIP
+	f.Mip = MipSSIP
+
+	// delegated interrupts are not taken in M-mode
+	e.Step()
+	if e.Cpu.PC != 0 {
+		t.Fatalf("interrupt must not be taken in M-mode, but PC was 0x%08x", e.Cpu.PC)
+	}
+
+	// not taken in S-mode while sstatus.SIE is clear
+	e.Cpu.Priv = PrivS
+	e.Step()
+	if e.Cpu.PC != 0 {
+		t.Fatalf("interrupt must not be taken without SIE, but PC was 0x%08x", e.Cpu.PC)
+	}
+
+	// always taken in U-mode
+	e.Cpu.Priv = PrivU
+	e.Step()
+	if f.Scause != uint32(CauseSupervisorSoftwareInterrupt) || e.Cpu.Priv != PrivS || e.Cpu.PC != 0x204 {
+		t.Errorf("interrupt must be taken in S, but scause:0x%08x, priv:%d, PC:0x%08x", f.Scause, e.Cpu.Priv, e.Cpu.PC)
+	}
+}
@@ -0,0 +1,41 @@
+package rv32i
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// Test_RiscvTests runs the riscv-tests binaries in testdata/riscv-tests.
+// A test passes if it exits through tohost with 0, and the exit code is
+// the number of the failed test case otherwise.
+func Test_RiscvTests(t *testing.T) {
+	paths, err := filepath.Glob("testdata/riscv-tests/rv32*-p-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no riscv-tests binaries")
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Parallel()
+			e := NewEmulator()
+			if err := e.Load(path); err != nil {
+				t.Fatal(err)
+			}
+			if e.Htif == nil {
+				t.Fatal("tohost is not found")
+			}
+			r := e.RunWithOptions(context.Background(), RunOptions{MaxInstructions: 1_000_000})
+			switch {
+			case r.Kind != StopExit:
+				t.Errorf("test must exit, but was %v", r)
+			case r.ExitCode != 0:
+				t.Errorf("test #%d failed", r.ExitCode)
+			}
+		})
+	}
+}
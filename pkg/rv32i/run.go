@@ -0,0 +1,183 @@
+package rv32i
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//go:generate stringer -type StopKind -trimprefix Stop
+type StopKind int
+
+const (
+	// StopError is an error other than the reasons below, including invalid
+	// RunOptions
+	StopError StopKind = iota
+	// StopTrap is a trap without a guest handler
+	StopTrap
+	// StopExit is the exit syscall
+	StopExit
+	// StopPC is reaching RunOptions.StopPCs or StopSymbols
+	StopPC
+	// StopMaxInstructions is executing RunOptions.MaxInstructions
+	StopMaxInstructions
+	// StopCanceled is the context being canceled or timed out
+	StopCanceled
+	// StopToHost is a non-zero write to RunOptions.ToHost
+	StopToHost
+	// StopSelfLoop is a jump to itself which no interrupt can break
+	StopSelfLoop
+	// StopBreakpoint is reaching a breakpoint of AddBreakpoint
+	StopBreakpoint
+	// StopWatchpoint is an access to a watchpoint of AddWatchpoint
+	StopWatchpoint
+)
+
+// RunOptions are the stop conditions of RunWithOptions in addition to errors
+type RunOptions struct {
+	MaxInstructions uint64   // 0 is unlimited
+	StopPCs         []uint32 // stops before executing these addresses
+	StopSymbols     []string // same as StopPCs, looked up in Emulator.Symbols
+	ToHost          uint32   // address of tohost, 0 if unused
+	StopOnSelfLoop  bool
+}
+
+// StopReason is why RunWithOptions stopped
+type StopReason struct {
+	Kind     StopKind
+	PC       uint32
+	Steps    uint64 // instructions executed by the run
+	ExitCode int    // exit code of StopExit, and of StopToHost in the riscv-tests convention
+	ToHost   uint32 // value written to tohost
+	Err      error  // error of StopError, StopTrap, StopExit and StopCanceled
+	// Breakpoint is a copy of the breakpoint of StopBreakpoint or the
+	// watchpoint of StopWatchpoint
+	Breakpoint *Breakpoint
+	WatchAddr  uint32 // address the guest accessed for StopWatchpoint
+}
+
+func (r StopReason) String() string {
+	s := fmt.Sprintf("%v at pc 0x%08x after %d steps", r.Kind, r.PC, r.Steps)
+	switch r.Kind {
+	case StopExit:
+		s += fmt.Sprintf(", exit code %d", r.ExitCode)
+	case StopToHost:
+		s += fmt.Sprintf(", tohost 0x%08x", r.ToHost)
+	case StopBreakpoint:
+		s += fmt.Sprintf(", %v", r.Breakpoint)
+	case StopWatchpoint:
+		s += fmt.Sprintf(", %v accessed at 0x%08x", r.Breakpoint, r.WatchAddr)
+	}
+	if r.Err != nil && r.Kind != StopExit {
+		s += ": " + r.Err.Error()
+	}
+	return s
+}
+
+// cancelCheckInterval is the number of steps between checks of the context
+const cancelCheckInterval = 1024
+
+// RunWithOptions runs until one of the stop conditions is met or ctx is done.
+// The breakpoints of AddBreakpoint are not checked for the first instruction
+// so that a run can resume from the breakpoint it stopped at. Watchpoints
+// stop after the instruction which accessed memory.
+func (e *Emulator) RunWithOptions(ctx context.Context, opts RunOptions) StopReason {
+	cpu := e.Cpu
+	stops := map[uint32]bool{}
+	for _, pc := range opts.StopPCs {
+		stops[pc] = true
+	}
+	for _, name := range opts.StopSymbols {
+		addr, ok := e.Symbols.Lookup(name)
+		if !ok {
+			return StopReason{Kind: StopError, PC: cpu.PC, Err: fmt.Errorf("symbol %s not found", name)}
+		}
+		stops[addr] = true
+	}
+
+	var steps uint64
+	stop := func(kind StopKind, err error) StopReason {
+		return StopReason{Kind: kind, PC: cpu.PC, Steps: steps, Err: err}
+	}
+	for {
+		if stops[cpu.PC] {
+			return stop(StopPC, nil)
+		}
+		if steps > 0 {
+			if bp := e.breakpointAt(cpu.PC); bp != nil {
+				r := stop(StopBreakpoint, nil)
+				copied := *bp
+				r.Breakpoint = &copied
+				return r
+			}
+		}
+		if opts.MaxInstructions > 0 && steps >= opts.MaxInstructions {
+			return stop(StopMaxInstructions, nil)
+		}
+		if steps%cancelCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return stop(StopCanceled, ctx.Err())
+			default:
+			}
+		}
+
+		pc := cpu.PC
+		e.breakpoints.hit = nil
+		err := cpu.Step()
+		steps++
+		if err != nil {
+			var exitErr *ExitError
+			var trap *Trap
+			switch {
+			case errors.As(err, &exitErr):
+				r := stop(StopExit, err)
+				r.ExitCode = exitErr.Code
+				return r
+			case errors.As(err, &trap):
+				return stop(StopTrap, err)
+			default:
+				return stop(StopError, err)
+			}
+		}
+
+		if bp := e.breakpoints.hit; bp != nil {
+			r := stop(StopWatchpoint, nil)
+			copied := *bp
+			r.Breakpoint = &copied
+			r.WatchAddr = e.breakpoints.hitAddr
+			return r
+		}
+		if opts.ToHost != 0 {
+			if v, err := e.ReadU32(opts.ToHost); err == nil && v != 0 {
+				r := stop(StopToHost, nil)
+				r.ToHost = v
+				r.ExitCode = toHostExitCode(v)
+				return r
+			}
+		}
+		if opts.StopOnSelfLoop && cpu.PC == pc && !cpu.interruptible() {
+			return stop(StopSelfLoop, nil)
+		}
+	}
+}
+
+// toHostExitCode returns the exit code of tohost in the riscv-tests
+// convention, (code << 1) | 1. Other values are returned as is.
+func toHostExitCode(v uint32) int {
+	if v&1 == 1 {
+		return int(v >> 1)
+	}
+	return int(v)
+}
+
+// interruptible returns true if an enabled interrupt can be taken when it
+// becomes pending
+func (c *Cpu) interruptible() bool {
+	f := &c.Csr
+	if f.Mie&^f.Mideleg != 0 && f.Mtvec != 0 && (c.Priv < PrivM || f.Mstatus&MstatusMIE != 0) {
+		return true
+	}
+	return f.Mie&f.Mideleg != 0 && f.Stvec != 0 &&
+		(c.Priv < PrivS || c.Priv == PrivS && f.Mstatus&MstatusSIE != 0)
+}
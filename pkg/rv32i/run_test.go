@@ -0,0 +1,75 @@
+package rv32i
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_RunWithOptions(t *testing.T) {
+	type TestData struct {
+		Name  string
+		Codes []uint32
+		Opts  RunOptions
+		Kind  StopKind
+		PC    uint32
+		Steps uint64
+	}
+
+	loop := GenCode(OpJal, 0, 0, 0)
+	nop := GenCode(OpAddi, 0, 0, 0)
+	for _, td := range []TestData{
+		{"pc", []uint32{nop, nop, nop}, RunOptions{StopPCs: []uint32{8}}, StopPC, 8, 2},
+		{"symbol", []uint32{nop, nop, nop}, RunOptions{StopSymbols: []string{"end"}}, StopPC, 4, 1},
+		{"unknown symbol", []uint32{nop}, RunOptions{StopSymbols: []string{"none"}}, StopError, 0, 0},
+		{"max", []uint32{nop, nop, loop}, RunOptions{MaxInstructions: 10}, StopMaxInstructions, 8, 10},
+		{"self loop", []uint32{nop, loop}, RunOptions{StopOnSelfLoop: true}, StopSelfLoop, 4, 2},
+		{"trap", []uint32{nop, GenCode(OpEbreak, 0, 0, 0)}, RunOptions{}, StopTrap, 4, 2},
+		{"exit", syscallCode(SysExit, 3), RunOptions{}, StopExit, 8, 3},
+		{
+			"tohost",
+			[]uint32{GenCode(OpAddi, 5, 0, 5), GenCode(OpSw, 5, 0x100, 0), loop},
+			RunOptions{ToHost: 0x100},
+			StopToHost, 8, 2,
+		},
+	} {
+		e := NewEmulator()
+		e.Syscalls = NewDefaultSyscalls(SyscallConfig{})
+		e.Symbols.Add(Symbol{Name: "end", Addr: 4})
+		loadCode(e, 0, td.Codes...)
+		r := e.RunWithOptions(context.Background(), td.Opts)
+		if r.Kind != td.Kind || r.PC != td.PC || r.Steps != td.Steps {
+			t.Errorf("%s: got %v, want %v at pc 0x%08x after %d steps", td.Name, r, td.Kind, td.PC, td.Steps)
+		}
+		switch r.Kind {
+		case StopExit:
+			if r.ExitCode != 3 {
+				t.Errorf("%s: exit code must be 3, but was %d", td.Name, r.ExitCode)
+			}
+		case StopToHost:
+			if r.ToHost != 5 || r.ExitCode != 2 {
+				t.Errorf("%s: tohost must be 5 and exit code 2, but were %d and %d", td.Name, r.ToHost, r.ExitCode)
+			}
+		}
+	}
+}
+
+func Test_RunCanceled(t *testing.T) {
+	e := NewEmulator()
+	loadCode(e, 0, GenCode(OpJal, 0, 0, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r := e.RunWithOptions(ctx, RunOptions{})
+	if r.Kind != StopCanceled || r.Err != context.DeadlineExceeded {
+		t.Errorf("run must be canceled, but was %v", r)
+	}
+
+	// an idle loop waiting for interrupts is not a self loop
+	e.Cpu.Csr.Mtvec = 0x100
+	e.Cpu.Csr.Mie = MipMTIP
+	e.Cpu.Csr.Mstatus |= MstatusMIE
+	r = e.RunWithOptions(context.Background(), RunOptions{StopOnSelfLoop: true, MaxInstructions: 100})
+	if r.Kind != StopMaxInstructions {
+		t.Errorf("idle loop must not stop, but was %v", r)
+	}
+}
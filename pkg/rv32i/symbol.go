@@ -0,0 +1,125 @@
+package rv32i
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Symbol struct {
+	Name string
+	Addr uint32
+	Size uint32
+	Func bool
+}
+
+// SymbolTable keeps symbols of the loaded program and resolves
+// address->name and name->address
+type SymbolTable struct {
+	byName map[string]*Symbol
+	byAddr []*Symbol // sorted by Addr
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		byName: make(map[string]*Symbol),
+		byAddr: make([]*Symbol, 0),
+	}
+}
+
+func (st *SymbolTable) Add(sym Symbol) {
+	s := &sym
+	if _, ok := st.byName[s.Name]; !ok {
+		st.byName[s.Name] = s
+	}
+
+	idx := sort.Search(len(st.byAddr), func(i int) bool {
+		return st.byAddr[i].Addr > s.Addr
+	})
+	st.byAddr = append(st.byAddr, nil)
+	copy(st.byAddr[idx+1:], st.byAddr[idx:])
+	st.byAddr[idx] = s
+}
+
+func (st *SymbolTable) Len() int {
+	if st == nil {
+		return 0
+	}
+	return len(st.byAddr)
+}
+
+// Lookup returns the address of the symbol
+func (st *SymbolTable) Lookup(name string) (uint32, bool) {
+	sym, ok := st.Symbol(name)
+	if !ok {
+		return 0, false
+	}
+	return sym.Addr, true
+}
+
+func (st *SymbolTable) Symbol(name string) (*Symbol, bool) {
+	if st == nil {
+		return nil, false
+	}
+	sym, ok := st.byName[name]
+	return sym, ok
+}
+
+// Find returns the symbol which contains addr.
+// A symbol without size covers addresses until the next symbol.
+func (st *SymbolTable) Find(addr uint32) (*Symbol, bool) {
+	if st == nil {
+		return nil, false
+	}
+
+	idx := sort.Search(len(st.byAddr), func(i int) bool {
+		return st.byAddr[i].Addr > addr
+	})
+	if idx == 0 {
+		return nil, false
+	}
+	nearest := st.byAddr[idx-1].Addr
+
+	// prefer functions, then sized symbols among the ones at the same address
+	var found *Symbol
+	for i := idx - 1; i >= 0; i-- {
+		sym := st.byAddr[i]
+		if found != nil && sym.Addr != found.Addr {
+			break
+		}
+		if sym.Size > 0 {
+			if addr-sym.Addr >= sym.Size {
+				continue
+			}
+		} else if sym.Addr != nearest {
+			continue
+		}
+		if found == nil || (sym.Func && !found.Func) || (sym.Size > 0 && found.Size == 0 && sym.Func == found.Func) {
+			found = sym
+		}
+	}
+
+	return found, found != nil
+}
+
+// Format returns "name+0xoffset" if addr belongs to a symbol,
+// otherwise "0xaddr"
+func (st *SymbolTable) Format(addr uint32) string {
+	sym, ok := st.Find(addr)
+	if !ok {
+		return fmt.Sprintf("0x%08x", addr)
+	}
+	if addr == sym.Addr {
+		return sym.Name
+	}
+	return fmt.Sprintf("%s+0x%x", sym.Name, addr-sym.Addr)
+}
+
+// symbolAddr formats an address lazily for tracef()
+type symbolAddr struct {
+	st   *SymbolTable
+	addr uint32
+}
+
+func (s symbolAddr) String() string {
+	return s.st.Format(s.addr)
+}
@@ -0,0 +1,84 @@
+package rv32i
+
+import (
+	"debug/elf"
+	"testing"
+)
+
+func Test_SymbolTable(t *testing.T) {
+	st := NewSymbolTable()
+	st.Add(Symbol{Name: "boot", Addr: 0x0})
+	st.Add(Symbol{Name: "_out", Addr: 0x1c})
+	st.Add(Symbol{Name: "is_even", Addr: 0x20, Size: 0x3c, Func: true})
+	st.Add(Symbol{Name: "counter", Addr: 0x1000, Size: 4})
+
+	addr, ok := st.Lookup("is_even")
+	if !ok || addr != 0x20 {
+		t.Errorf("is_even must be 0x%08x, but was 0x%08x", 0x20, addr)
+	}
+	if _, ok = st.Lookup("main"); ok {
+		t.Error("main must not be found")
+	}
+
+	type TestData struct {
+		Addr uint32
+		Want string
+	}
+	for _, td := range []TestData{
+		{0x0, "boot"},
+		{0x18, "boot+0x18"},
+		{0x1c, "_out"},
+		{0x20, "is_even"},
+		{0x58, "is_even+0x38"},
+		// past the end of is_even
+		{0x5c, "0x0000005c"},
+		{0x1002, "counter+0x2"},
+		{0x1004, "0x00001004"},
+	} {
+		got := st.Format(td.Addr)
+		if got != td.Want {
+			t.Errorf("Format(0x%08x) got:%s, want:%s", td.Addr, got, td.Want)
+		}
+	}
+
+	// nil table
+	var nilst *SymbolTable
+	if got := nilst.Format(0x20); got != "0x00000020" {
+		t.Errorf("Format on nil got:%s", got)
+	}
+}
+
+func Test_ReadELFSymbols(t *testing.T) {
+	te := testELF{
+		Entry: 0,
+		Segments: []testSegment{
+			{Paddr: 0, Data: u32sToBytes(0x00000013, 0x00000013, 0x00008067)},
+		},
+		Symbols: []testSymbol{
+			{Name: "boot", Addr: 0x0, Type: elf.STT_NOTYPE},
+			{Name: "_out", Addr: 0x8, Size: 4, Type: elf.STT_FUNC},
+			{Name: ".Lpcrel_hi0", Addr: 0x4, Type: elf.STT_NOTYPE},
+		},
+	}
+	path := te.write(t)
+
+	e := NewEmulator()
+	err := e.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Symbols.Len() != 2 {
+		t.Errorf("Len must be %d, but was %d", 2, e.Symbols.Len())
+	}
+	sym, ok := e.Symbols.Symbol("_out")
+	if !ok {
+		t.Fatal("_out not found")
+	}
+	if sym.Addr != 0x8 || sym.Size != 4 || !sym.Func {
+		t.Errorf("Unexpected symbol %+v", sym)
+	}
+	if got := e.Symbols.Format(0x4); got != "boot+0x4" {
+		t.Errorf("Format(0x4) got:%s", got)
+	}
+}
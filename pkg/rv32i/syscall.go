@@ -0,0 +1,439 @@
+package rv32i
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// syscall numbers (Linux asm-generic, also used by newlib/libgloss)
+const (
+	SysOpenat       = uint32(56)
+	SysClose        = uint32(57)
+	SysRead         = uint32(63)
+	SysWrite        = uint32(64)
+	SysExit         = uint32(93)
+	SysExitGroup    = uint32(94)
+	SysGettimeofday = uint32(169)
+	SysBrk          = uint32(214)
+)
+
+// errno
+const (
+	ENOENT = 2
+	EBADF  = 9
+	ENOMEM = 12
+	EACCES = 13
+	EFAULT = 14
+	EINVAL = 22
+	EMFILE = 24
+	ENOSYS = 38
+)
+
+// openat flags
+const (
+	atFdcwd  = int32(-100)
+	oAccmode = 0b11
+	oRdonly  = 0
+	oWronly  = 1
+	oRdwr    = 2
+	oCreat   = 0o100
+	oExcl    = 0o200
+	oTrunc   = 0o1000
+	oAppend  = 0o2000
+)
+
+const (
+	firstFile = int32(3) // next to stdin, stdout and stderr
+	maxOpen   = 64
+	maxPath   = 4096
+)
+
+// SyscallHandler emulates a system call requested by ecall.
+// Arguments are in a0-a5 and the result must be set to a0.
+// Returning an error stops the emulator.
+type SyscallHandler interface {
+	Syscall(c *Cpu) error
+}
+
+type SyscallHandlerFunc func(c *Cpu) error
+
+func (f SyscallHandlerFunc) Syscall(c *Cpu) error {
+	return f(c)
+}
+
+// ExitError is returned from Step/Run when the program calls exit
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exited with code %d", e.Code)
+}
+
+// Syscalls dispatches ecall to a handler keyed by a7
+type Syscalls struct {
+	handlers map[uint32]SyscallHandler
+}
+
+func NewSyscalls() *Syscalls {
+	return &Syscalls{
+		handlers: make(map[uint32]SyscallHandler),
+	}
+}
+
+func (s *Syscalls) Register(num uint32, h SyscallHandler) {
+	s.handlers[num] = h
+}
+
+func (s *Syscalls) Unregister(num uint32) {
+	delete(s.handlers, num)
+}
+
+func (s *Syscalls) Dispatch(c *Cpu) error {
+	num := c.X[Regs["a7"]]
+	h, ok := s.handlers[num]
+	if !ok {
+		log.Warnf("syscall %d is not implemented", num)
+		c.setSyscallResult(-ENOSYS)
+		return nil
+	}
+	trace("syscall: ", num)
+	return h.Syscall(c)
+}
+
+func (c *Cpu) syscallArg(n int) uint32 {
+	return c.X[Regs["a0"]+n]
+}
+
+func (c *Cpu) setSyscallResult(v int32) {
+	c.X[Regs["a0"]] = uint32(v)
+}
+
+type SyscallConfig struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Root is the directory exposed to openat. openat fails if empty.
+	Root string
+	// Now is used by gettimeofday. time.Now if nil.
+	Now func() time.Time
+}
+
+// defaultSyscalls is a newlib/Linux compatible syscall set
+type defaultSyscalls struct {
+	cfg      SyscallConfig
+	brk      uint32
+	firstBrk uint32
+	heap     *Mapping // the RAM which has firstBrk
+	files    map[int32]*os.File
+}
+
+// NewDefaultSyscalls returns write, read, exit, brk, openat/close on
+// cfg.Root and gettimeofday
+func NewDefaultSyscalls(cfg SyscallConfig) *Syscalls {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	d := &defaultSyscalls{
+		cfg:   cfg,
+		files: make(map[int32]*os.File),
+	}
+
+	s := NewSyscalls()
+	s.Register(SysOpenat, SyscallHandlerFunc(d.openat))
+	s.Register(SysClose, SyscallHandlerFunc(d.close))
+	s.Register(SysRead, SyscallHandlerFunc(d.read))
+	s.Register(SysWrite, SyscallHandlerFunc(d.write))
+	s.Register(SysExit, SyscallHandlerFunc(d.exit))
+	s.Register(SysExitGroup, SyscallHandlerFunc(d.exit))
+	s.Register(SysGettimeofday, SyscallHandlerFunc(d.gettimeofday))
+	s.Register(SysBrk, SyscallHandlerFunc(d.brkHandler))
+	return s
+}
+
+func (d *defaultSyscalls) exit(c *Cpu) error {
+	return &ExitError{Code: int(int32(c.syscallArg(0)))}
+}
+
+// write(fd, buf, count)
+func (d *defaultSyscalls) write(c *Cpu) error {
+	fd := int32(c.syscallArg(0))
+	addr := c.syscallArg(1)
+
+	var w io.Writer
+	switch fd {
+	case 1:
+		w = d.cfg.Stdout
+	case 2:
+		w = d.cfg.Stderr
+	default:
+		if fp, ok := d.files[fd]; ok {
+			w = fp
+		}
+	}
+	if w == nil {
+		c.setSyscallResult(-EBADF)
+		return nil
+	}
+
+	count := c.syscallArg(2)
+	if !c.Emu.Bus.IsMapped(addr, count) {
+		c.setSyscallResult(-EFAULT)
+		return nil
+	}
+
+	buf, err := c.Emu.ReadBytes(addr, count)
+	if err != nil {
+		c.setSyscallResult(-EFAULT)
+		return nil
+	}
+
+	n, err := w.Write(buf)
+	if err != nil && n == 0 {
+		c.setSyscallResult(-EINVAL)
+		return nil
+	}
+	c.setSyscallResult(int32(n))
+	return nil
+}
+
+// read(fd, buf, count)
+func (d *defaultSyscalls) read(c *Cpu) error {
+	fd := int32(c.syscallArg(0))
+	addr := c.syscallArg(1)
+
+	var r io.Reader
+	if fd == 0 {
+		r = d.cfg.Stdin
+	} else if fp, ok := d.files[fd]; ok {
+		r = fp
+	}
+	if r == nil {
+		c.setSyscallResult(-EBADF)
+		return nil
+	}
+
+	count := c.syscallArg(2)
+	if !c.Emu.Bus.IsMapped(addr, count) {
+		c.setSyscallResult(-EFAULT)
+		return nil
+	}
+
+	buf := make([]byte, count)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		c.setSyscallResult(-EINVAL)
+		return nil
+	}
+	if err = c.Emu.WriteBytes(addr, buf[:n]); err != nil {
+		c.setSyscallResult(-EFAULT)
+		return nil
+	}
+	c.setSyscallResult(int32(n))
+	return nil
+}
+
+// openat(dirfd, pathname, flags, mode)
+func (d *defaultSyscalls) openat(c *Cpu) error {
+	if d.cfg.Root == "" {
+		c.setSyscallResult(-EACCES)
+		return nil
+	}
+	if int32(c.syscallArg(0)) != atFdcwd {
+		c.setSyscallResult(-EBADF)
+		return nil
+	}
+	name, err := c.Emu.ReadCString(c.syscallArg(1), maxPath)
+	if err != nil {
+		c.setSyscallResult(-EFAULT)
+		return nil
+	}
+	if len(d.files) >= maxOpen {
+		c.setSyscallResult(-EMFILE)
+		return nil
+	}
+
+	path, err := d.hostPath(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.setSyscallResult(-ENOENT)
+		} else {
+			c.setSyscallResult(-EACCES)
+		}
+		return nil
+	}
+
+	flags := c.syscallArg(2)
+	hostFlags := 0
+	switch flags & oAccmode {
+	case oRdonly:
+		hostFlags = os.O_RDONLY
+	case oWronly:
+		hostFlags = os.O_WRONLY
+	case oRdwr:
+		hostFlags = os.O_RDWR
+	default:
+		c.setSyscallResult(-EINVAL)
+		return nil
+	}
+	if flags&oCreat != 0 {
+		hostFlags |= os.O_CREATE
+	}
+	if flags&oExcl != 0 {
+		hostFlags |= os.O_EXCL
+	}
+	if flags&oTrunc != 0 {
+		hostFlags |= os.O_TRUNC
+	}
+	if flags&oAppend != 0 {
+		hostFlags |= os.O_APPEND
+	}
+
+	fp, err := os.OpenFile(path, hostFlags, os.FileMode(c.syscallArg(3)&0o777))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.setSyscallResult(-ENOENT)
+		} else {
+			c.setSyscallResult(-EACCES)
+		}
+		return nil
+	}
+
+	fd := firstFile
+	for {
+		if _, ok := d.files[fd]; !ok {
+			break
+		}
+		fd++
+	}
+	d.files[fd] = fp
+	c.setSyscallResult(fd)
+	return nil
+}
+
+// hostPath returns the path of name in Root with the symlinks resolved, so
+// that the guest can't escape from Root by ".." nor symlinks. A file which
+// doesn't exist yet is resolved by its directory for O_CREAT.
+func (d *defaultSyscalls) hostPath(name string) (string, error) {
+	root, err := filepath.EvalSymlinks(d.cfg.Root)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(root, filepath.Clean("/"+name))
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// a dangling symlink would be followed by O_CREAT
+		if _, err := os.Lstat(path); err == nil {
+			return "", os.ErrPermission
+		}
+		dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+		if err != nil {
+			return "", err
+		}
+		resolved = filepath.Join(dir, filepath.Base(path))
+	} else if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", os.ErrPermission
+	}
+	return resolved, nil
+}
+
+// close(fd)
+func (d *defaultSyscalls) close(c *Cpu) error {
+	fd := int32(c.syscallArg(0))
+	fp, ok := d.files[fd]
+	if !ok {
+		// stdin, stdout and stderr are never closed on the host
+		if fd >= 0 && fd < firstFile {
+			c.setSyscallResult(0)
+		} else {
+			c.setSyscallResult(-EBADF)
+		}
+		return nil
+	}
+	delete(d.files, fd)
+	fp.Close()
+	c.setSyscallResult(0)
+	return nil
+}
+
+// gettimeofday(tv, tz)
+// struct timeval { int64 tv_sec; int32 tv_usec; }
+func (d *defaultSyscalls) gettimeofday(c *Cpu) error {
+	addr := c.syscallArg(0)
+	if addr != 0 {
+		now := d.cfg.Now()
+		buf := make([]byte, 16)
+		sec := uint64(now.Unix())
+		usec := uint32(now.Nanosecond() / 1000)
+		for i := 0; i < 8; i++ {
+			buf[i] = uint8(sec >> (8 * i))
+		}
+		for i := 0; i < 4; i++ {
+			buf[8+i] = uint8(usec >> (8 * i))
+		}
+		if err := c.Emu.WriteBytes(addr, buf); err != nil {
+			c.setSyscallResult(-EFAULT)
+			return nil
+		}
+	}
+	c.setSyscallResult(0)
+	return nil
+}
+
+// brk(addr) returns the new program break, or the current one on failure.
+// The break can move only within the RAM of the initial one, and not below
+// the initial one.
+func (d *defaultSyscalls) brkHandler(c *Cpu) error {
+	if d.brk == 0 {
+		d.brk = initialBrk(c.Emu)
+		d.firstBrk = d.brk
+		// the initial break can be the end of the RAM
+		if m := c.Emu.Bus.find(d.brk-1, 1); m != nil {
+			if _, ok := m.Device.(*RAM); ok {
+				d.heap = m
+			}
+		}
+	}
+
+	addr := c.syscallArg(0)
+	if d.heap != nil && addr >= d.firstBrk && d.heap.contains(addr-1, 1) {
+		d.brk = addr
+	}
+	c.setSyscallResult(int32(d.brk))
+	return nil
+}
+
+// initialBrk returns the end of the loaded program.
+// If the program doesn't have the symbol, the upper half of the RAM the program
+// runs in (or the first RAM) is used.
+func initialBrk(e *Emulator) uint32 {
+	for _, name := range []string{"_end", "end", "__free_ram_start"} {
+		if addr, ok := e.Symbols.Lookup(name); ok {
+			return addr
+		}
+	}
+	var r *Mapping
+	for _, m := range e.Bus.Mappings() {
+		if _, ok := m.Device.(*RAM); !ok {
+			continue
+		}
+		if r == nil || m.contains(e.Cpu.PC, 1) {
+			r = m
+		}
+	}
+	if r == nil {
+		return 0
+	}
+	return r.Base + r.Size/2
+}
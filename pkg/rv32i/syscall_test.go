@@ -0,0 +1,286 @@
+package rv32i
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const codeEcall = uint32(0x00000073)
+
+func loadCode(e *Emulator, addr uint32, codes ...uint32) {
+	for idx, code := range codes {
+		e.WriteU32(addr+uint32(idx*4), code)
+	}
+}
+
+// syscallCode returns a0..a2 <- args, a7 <- num, ecall
+func syscallCode(num uint32, args ...int) []uint32 {
+	codes := make([]uint32, 0)
+	for idx, arg := range args {
+		codes = append(codes, GenCode(OpAddi, Regs["a0"]+idx, 0, arg))
+	}
+	codes = append(codes, GenCode(OpAddi, Regs["a7"], 0, int(num)), codeEcall)
+	return codes
+}
+
+func Test_SyscallWriteExit(t *testing.T) {
+	stdout := new(bytes.Buffer)
+
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{Stdout: stdout})
+
+	msg := "hello\n"
+	e.WriteBytes(0x400, []byte(msg))
+	codes := syscallCode(SysWrite, 1, 0x400, len(msg))
+	codes = append(codes, syscallCode(SysExit, 3)...)
+	loadCode(e, 0, codes...)
+
+	err := e.Run()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Run must return ExitError, but was %v", err)
+	}
+	if exitErr.Code != 3 {
+		t.Errorf("exit code must be %d, but was %d", 3, exitErr.Code)
+	}
+	if stdout.String() != msg {
+		t.Errorf("stdout must be %q, but was %q", msg, stdout.String())
+	}
+
+	// count out of memory
+	e.Cpu.PC = 0
+	loadCode(e, 0, syscallCode(SysWrite, 1, 0x400, -1)...)
+	e.StepUntil(4 * 5)
+	if int32(e.Cpu.X[10]) != -EFAULT {
+		t.Errorf("a0 must be %d, but was %d", -EFAULT, int32(e.Cpu.X[10]))
+	}
+}
+
+func Test_SyscallRead(t *testing.T) {
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{Stdin: strings.NewReader("abc")})
+
+	loadCode(e, 0, syscallCode(SysRead, 0, 0x400, 16)...)
+	e.StepUntil(4 * 5)
+
+	if e.Cpu.X[10] != 3 {
+		t.Errorf("a0 must be %d, but was %d", 3, e.Cpu.X[10])
+	}
+	buf, _ := e.ReadBytes(0x400, 3)
+	if string(buf) != "abc" {
+		t.Errorf("buffer must be abc, but was %q", buf)
+	}
+
+	// bad fd
+	e.Cpu.PC = 0
+	loadCode(e, 0, syscallCode(SysRead, 5, 0x400, 16)...)
+	e.StepUntil(4 * 5)
+	if int32(e.Cpu.X[10]) != -EBADF {
+		t.Errorf("a0 must be %d, but was %d", -EBADF, int32(e.Cpu.X[10]))
+	}
+}
+
+func Test_SyscallOpenat(t *testing.T) {
+	root := t.TempDir()
+	err := os.WriteFile(filepath.Join(root, "in.txt"), []byte("data"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{Root: root})
+
+	// "../in.txt" must stay in root
+	e.WriteBytes(0x400, []byte("../in.txt\x00"))
+	codes := syscallCode(SysOpenat, -100, 0x400, 0)
+	// s1 <- fd
+	codes = append(codes, GenCode(OpAddi, Regs["s1"], Regs["a0"], 0))
+	loadCode(e, 0, codes...)
+	e.StepUntil(uint32(4 * len(codes)))
+
+	fd := e.Cpu.X[Regs["s1"]]
+	if fd != 3 {
+		t.Fatalf("fd must be %d, but was %d", 3, int32(fd))
+	}
+
+	e.Cpu.PC = 0
+	codes = syscallCode(SysRead, int(fd), 0x600, 16)
+	codes = append(codes, syscallCode(SysClose, int(fd))...)
+	loadCode(e, 0, codes...)
+	e.StepUntil(4 * 5)
+	buf, _ := e.ReadBytes(0x600, 4)
+	if e.Cpu.X[10] != 4 || string(buf) != "data" {
+		t.Errorf("read must return 4 and data, but was %d and %q", e.Cpu.X[10], buf)
+	}
+	e.StepUntil(uint32(4 * len(codes)))
+	if e.Cpu.X[10] != 0 {
+		t.Errorf("close must return 0, but was %d", int32(e.Cpu.X[10]))
+	}
+
+	// not found
+	e.Cpu.PC = 0
+	e.WriteBytes(0x400, []byte("nothing\x00"))
+	loadCode(e, 0, syscallCode(SysOpenat, -100, 0x400, 0)...)
+	e.StepUntil(4 * 5)
+	if int32(e.Cpu.X[10]) != -ENOENT {
+		t.Errorf("a0 must be %d, but was %d", -ENOENT, int32(e.Cpu.X[10]))
+	}
+}
+
+func Test_SyscallOpenatSymlink(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	for _, path := range []string{filepath.Join(root, "in.txt"), filepath.Join(outside, "secret.txt")} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"link.txt":   "in.txt",
+		"secret.txt": filepath.Join(outside, "secret.txt"),
+		"dir":        outside,
+		"dangling":   filepath.Join(outside, "new.txt"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Skip(err)
+		}
+	}
+
+	type TestData struct {
+		Name  string
+		Flags int
+		Want  int32
+	}
+	for _, td := range []TestData{
+		{"link.txt", oRdonly, 3},
+		{"new.txt", oWronly | oCreat, 3},
+		{"secret.txt", oRdonly, -EACCES},
+		{"dir/secret.txt", oRdonly, -EACCES},
+		{"dir/new.txt", oWronly | oCreat, -EACCES},
+		{"dangling", oWronly | oCreat, -EACCES},
+	} {
+		e := NewEmulator()
+		e.Syscalls = NewDefaultSyscalls(SyscallConfig{Root: root})
+		e.WriteBytes(0x400, []byte(td.Name+"\x00"))
+		loadCode(e, 0, syscallCode(SysOpenat, -100, 0x400, td.Flags)...)
+		e.StepUntil(4 * 5)
+		if got := int32(e.Cpu.X[10]); got != td.Want {
+			t.Errorf("openat %s must return %d, but was %d", td.Name, td.Want, got)
+		}
+	}
+	for _, name := range []string{"new.txt", "dir/new.txt"} {
+		if _, err := os.Stat(filepath.Join(outside, filepath.Base(name))); err == nil {
+			t.Errorf("%s must not be created out of the root", name)
+		}
+	}
+}
+
+func Test_SyscallBrkLimits(t *testing.T) {
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{})
+	e.Symbols.Add(Symbol{Name: "_end", Addr: 0x2000})
+	loadCode(e, 0, codeEcall)
+	brk := func(addr uint32) uint32 {
+		e.Cpu.PC = 0
+		e.Cpu.X[Regs["a0"]] = addr
+		e.Cpu.X[Regs["a7"]] = SysBrk
+		e.Step()
+		return e.Cpu.X[Regs["a0"]]
+	}
+
+	if got := brk(0x3000); got != 0x3000 {
+		t.Errorf("brk must be 0x%08x, but was 0x%08x", 0x3000, got)
+	}
+	for _, addr := range []uint32{
+		// below the initial break
+		0x1000,
+		// MMIO
+		CLINTBase + 0x10,
+		// another RAM
+		RAMBase + 0x1000,
+	} {
+		if got := brk(addr); got != 0x3000 {
+			t.Errorf("brk(0x%08x) must keep 0x%08x, but was 0x%08x", addr, 0x3000, got)
+		}
+	}
+	if got := brk(0x2000); got != 0x2000 {
+		t.Errorf("brk must shrink to the initial break, but was 0x%08x", got)
+	}
+}
+
+func Test_SyscallBrkGettimeofday(t *testing.T) {
+	now := time.Unix(0x12345678, 5000)
+
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{Now: func() time.Time { return now }})
+	e.Symbols.Add(Symbol{Name: "_end", Addr: 0x200})
+
+	codes := syscallCode(SysBrk, 0)
+	codes = append(codes, GenCode(OpAddi, Regs["s1"], Regs["a0"], 0))
+	codes = append(codes, syscallCode(SysBrk, 0x300)...)
+	codes = append(codes, syscallCode(SysGettimeofday, 0x400, 0)...)
+	loadCode(e, 0, codes...)
+	e.StepUntil(4 * 5)
+	if e.Cpu.X[Regs["s1"]] != 0x200 {
+		t.Errorf("initial brk must be 0x%08x, but was 0x%08x", 0x200, e.Cpu.X[Regs["s1"]])
+	}
+	e.StepUntil(4 * 7)
+	if e.Cpu.X[10] != 0x300 {
+		t.Errorf("brk must be 0x%08x, but was 0x%08x", 0x300, e.Cpu.X[10])
+	}
+	e.StepUntil(uint32(4 * len(codes)))
+	if e.Cpu.X[10] != 0 {
+		t.Errorf("gettimeofday must return 0, but was %d", int32(e.Cpu.X[10]))
+	}
+	sec, _ := e.ReadU32(0x400)
+	sechi, _ := e.ReadU32(0x404)
+	usec, _ := e.ReadU32(0x408)
+	if sec != 0x12345678 || sechi != 0 || usec != 5 {
+		t.Errorf("Unexpected timeval %x %x %x", sec, sechi, usec)
+	}
+}
+
+func Test_SyscallCustomHandler(t *testing.T) {
+	e := NewEmulator()
+	e.Syscalls = NewSyscalls()
+	e.Syscalls.Register(500, SyscallHandlerFunc(func(c *Cpu) error {
+		c.X[10] = c.X[10] * 2
+		return nil
+	}))
+
+	codes := syscallCode(500, 21)
+	codes = append(codes, syscallCode(501)...)
+	loadCode(e, 0, codes...)
+	e.StepUntil(4 * 3)
+	if e.Cpu.X[10] != 42 {
+		t.Errorf("a0 must be %d, but was %d", 42, e.Cpu.X[10])
+	}
+	e.StepUntil(uint32(4 * len(codes)))
+	if int32(e.Cpu.X[10]) != -ENOSYS {
+		t.Errorf("a0 must be %d, but was %d", -ENOSYS, int32(e.Cpu.X[10]))
+	}
+}
+
+func Test_SyscallGuestHandler(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	e := NewEmulator()
+	e.Syscalls = NewDefaultSyscalls(SyscallConfig{Stdout: stdout})
+	e.WriteBytes(0x400, []byte("x"))
+	loadCode(e, 0, syscallCode(SysWrite, 1, 0x400, 1)...)
+
+	// the guest handles its ecall if it has a trap handler
+	e.Cpu.Csr.Mtvec = 0x200
+	for i := 0; i < 5; i++ {
+		e.Step()
+	}
+	if e.Cpu.PC != 0x200 || e.Cpu.Csr.Mcause != uint32(CauseEnvironmentCallFromM) || e.Cpu.Csr.Mepc != 4*4 {
+		t.Errorf("ecall must trap to 0x200, but PC 0x%x, mcause %d, mepc 0x%x", e.Cpu.PC, e.Cpu.Csr.Mcause, e.Cpu.Csr.Mepc)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("the host must not write, but wrote %q", stdout.String())
+	}
+}
@@ -0,0 +1,133 @@
+// mkelf links a relocatable object made by llvm-mc into an executable ELF.
+// The object must have everything in .text and no relocations, so linking is
+// just placing .text at the base address.
+//
+//	go run mkelf.go -o rv32ui-p-add add.o
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const (
+	ehsize    = 52
+	phentsize = 32
+	shentsize = 40
+)
+
+func main() {
+	out := flag.String("o", "a.out", "Output path")
+	base := flag.Uint64("base", 0x8000_0000, "Address of .text")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mkelf [-o path] [-base addr] input.o")
+		os.Exit(2)
+	}
+	if err := link(flag.Arg(0), *out, uint32(*base)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func link(in string, out string, base uint32) error {
+	f, err := elf.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	text := f.Section(".text")
+	if text == nil {
+		return fmt.Errorf("%s has no .text", in)
+	}
+	for _, s := range f.Sections {
+		if (s.Type == elf.SHT_REL || s.Type == elf.SHT_RELA) && s.Size > 0 {
+			return fmt.Errorf("%s has relocations in %s", in, s.Name)
+		}
+	}
+	code, err := text.Data()
+	if err != nil {
+		return err
+	}
+	syms, err := f.Symbols()
+	if err != nil {
+		return err
+	}
+
+	// symbols in .text, such as tohost and the test labels
+	strtab := []byte{0}
+	symtab := new(bytes.Buffer)
+	binary.Write(symtab, binary.LittleEndian, elf.Sym32{})
+	for _, sym := range syms {
+		if sym.Name == "" || int(sym.Section) >= len(f.Sections) || f.Sections[sym.Section] != text {
+			continue
+		}
+		binary.Write(symtab, binary.LittleEndian, elf.Sym32{
+			Name:  uint32(len(strtab)),
+			Value: base + uint32(sym.Value),
+			Size:  uint32(sym.Size),
+			Info:  elf.ST_INFO(elf.STB_GLOBAL, elf.ST_TYPE(sym.Info)),
+			Shndx: 1,
+		})
+		strtab = append(append(strtab, sym.Name...), 0)
+	}
+	shstrtab := []byte("\x00.text\x00.symtab\x00.strtab\x00.shstrtab\x00")
+
+	// header, program header, .text, .symtab, .strtab, .shstrtab, section headers
+	textOff := uint32(ehsize + phentsize)
+	symtabOff := textOff + uint32(len(code))
+	strtabOff := symtabOff + uint32(symtab.Len())
+	shstrtabOff := strtabOff + uint32(len(strtab))
+	shoff := (shstrtabOff + uint32(len(shstrtab)) + 3) &^ 3
+
+	buf := new(bytes.Buffer)
+	ident := [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS32), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)}
+	buf.Write(ident[:])
+	binary.Write(buf, binary.LittleEndian, struct {
+		Type, Machine                                        uint16
+		Version, Entry, Phoff, Shoff, Flags                  uint32
+		Ehsize, Phentsize, Phnum, Shentsize, Shnum, Shstrndx uint16
+	}{
+		uint16(elf.ET_EXEC), uint16(elf.EM_RISCV),
+		uint32(elf.EV_CURRENT), base, ehsize, shoff, 0,
+		ehsize, phentsize, 1, shentsize, 5, 4,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Prog32{
+		Type:   uint32(elf.PT_LOAD),
+		Off:    textOff,
+		Vaddr:  base,
+		Paddr:  base,
+		Filesz: uint32(len(code)),
+		Memsz:  uint32(len(code)),
+		Flags:  uint32(elf.PF_R | elf.PF_W | elf.PF_X),
+		Align:  4,
+	})
+	buf.Write(code)
+	buf.Write(symtab.Bytes())
+	buf.Write(strtab)
+	buf.Write(shstrtab)
+	buf.Write(make([]byte, shoff-uint32(buf.Len())))
+
+	binary.Write(buf, binary.LittleEndian, elf.Section32{})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint32(elf.SHF_ALLOC | elf.SHF_WRITE | elf.SHF_EXECINSTR),
+		Addr: base, Off: textOff, Size: uint32(len(code)), Addralign: 4,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 7, Type: uint32(elf.SHT_SYMTAB), Off: symtabOff, Size: uint32(symtab.Len()),
+		Link: 3, Info: 1, Addralign: 4, Entsize: elf.Sym32Size,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 15, Type: uint32(elf.SHT_STRTAB), Off: strtabOff, Size: uint32(len(strtab)), Addralign: 1,
+	})
+	binary.Write(buf, binary.LittleEndian, elf.Section32{
+		Name: 23, Type: uint32(elf.SHT_STRTAB), Off: shstrtabOff, Size: uint32(len(shstrtab)), Addralign: 1,
+	})
+
+	return os.WriteFile(out, buf.Bytes(), 0644)
+}
@@ -0,0 +1,180 @@
+package rv32i
+
+import (
+	"fmt"
+)
+
+//go:generate stringer -type TrapCause -trimprefix Cause
+type TrapCause uint32
+
+// mcause exception codes
+const (
+	CauseInstructionAddressMisaligned TrapCause = 0
+	CauseInstructionAccessFault       TrapCause = 1
+	CauseIllegalInstruction           TrapCause = 2
+	CauseBreakpoint                   TrapCause = 3
+	CauseLoadAddressMisaligned        TrapCause = 4
+	CauseLoadAccessFault              TrapCause = 5
+	CauseStoreAddressMisaligned       TrapCause = 6
+	CauseStoreAccessFault             TrapCause = 7
+	CauseEnvironmentCallFromU         TrapCause = 8
+	CauseEnvironmentCallFromS         TrapCause = 9
+	CauseEnvironmentCallFromM         TrapCause = 11
+	CauseInstructionPageFault         TrapCause = 12
+	CauseLoadPageFault                TrapCause = 13
+	CauseStorePageFault               TrapCause = 15
+
+	CauseSupervisorSoftwareInterrupt TrapCause = causeInterrupt | 1
+	CauseMachineSoftwareInterrupt    TrapCause = causeInterrupt | 3
+	CauseSupervisorTimerInterrupt    TrapCause = causeInterrupt | 5
+	CauseMachineTimerInterrupt       TrapCause = causeInterrupt | 7
+	CauseSupervisorExternalInterrupt TrapCause = causeInterrupt | 9
+	CauseMachineExternalInterrupt    TrapCause = causeInterrupt | 11
+)
+
+// causeInterrupt is the interrupt bit of mcause
+const causeInterrupt = 1 << 31
+
+// IsInterrupt returns true if the cause is an interrupt
+func (c TrapCause) IsInterrupt() bool {
+	return c&causeInterrupt != 0
+}
+
+// code returns the exception code without the interrupt bit
+func (c TrapCause) code() uint32 {
+	return uint32(c &^ causeInterrupt)
+}
+
+// Trap is an exception raised by an instruction, or an interrupt.
+// It's returned from Cpu.Step when the guest has no trap handler (mtvec or
+// stvec of the target mode is 0).
+type Trap struct {
+	Cause TrapCause
+	Tval  uint32 // mtval or stval
+	PC    uint32 // mepc or sepc
+}
+
+func (t *Trap) Error() string {
+	return fmt.Sprintf("%v at pc 0x%08x, tval 0x%08x", t.Cause, t.PC, t.Tval)
+}
+
+// raise stops executing the current instruction and takes the exception
+// at the end of Step
+func (c *Cpu) raise(cause TrapCause, tval uint32) {
+	c.err = &Trap{Cause: cause, Tval: tval, PC: c.PC}
+}
+
+// delegated returns true if the trap is taken in S-mode.
+// Traps are never delegated from M-mode.
+func (c *Cpu) delegated(cause TrapCause) bool {
+	if c.Priv == PrivM {
+		return false
+	}
+	deleg := c.Csr.Medeleg
+	if cause.IsInterrupt() {
+		deleg = c.Csr.Mideleg
+	}
+	return deleg&(1<<cause.code()) != 0
+}
+
+// trapVector returns mtvec, or stvec if the trap is delegated. 0 means the
+// guest has no handler for it.
+func (c *Cpu) trapVector(cause TrapCause) uint32 {
+	if c.delegated(cause) {
+		return c.Csr.Stvec
+	}
+	return c.Csr.Mtvec
+}
+
+// handleError delivers a trap to the guest handler of M-mode, or S-mode if
+// it's delegated. If the handler is not set (tvec == 0), the trap is
+// returned to the caller of Step.
+func (c *Cpu) handleError(err error) error {
+	t, ok := err.(*Trap)
+	if !ok {
+		return err
+	}
+	f := &c.Csr
+	s := c.delegated(t.Cause)
+	tvec := c.trapVector(t.Cause)
+	if tvec == 0 {
+		return err
+	}
+	trace("trap: ", t)
+
+	if s {
+		f.Sepc = t.PC
+		f.Scause = uint32(t.Cause)
+		f.Stval = t.Tval
+		// SPIE <- SIE, SIE <- 0, SPP <- Priv
+		if f.Mstatus&MstatusSIE != 0 {
+			f.Mstatus |= MstatusSPIE
+		} else {
+			f.Mstatus &^= MstatusSPIE
+		}
+		f.Mstatus &^= MstatusSIE | MstatusSPP
+		f.Mstatus |= c.Priv << 8
+		c.Priv = PrivS
+	} else {
+		f.Mepc = t.PC
+		f.Mcause = uint32(t.Cause)
+		f.Mtval = t.Tval
+		// MPIE <- MIE, MIE <- 0, MPP <- Priv
+		if f.Mstatus&MstatusMIE != 0 {
+			f.Mstatus |= MstatusMPIE
+		} else {
+			f.Mstatus &^= MstatusMPIE
+		}
+		f.Mstatus &^= MstatusMIE
+		f.Mstatus = f.Mstatus&^MstatusMPP | c.Priv<<11
+		c.Priv = PrivM
+	}
+	// exceptions always jump to BASE in both direct and vectored mode
+	c.PC = tvec &^ 0b11
+	if t.Cause.IsInterrupt() && tvec&0b11 == 1 {
+		c.PC += 4 * t.Cause.code()
+	}
+
+	return nil
+}
+
+// interrupts in the priority order
+var interrupts = []struct {
+	bit   uint32
+	cause TrapCause
+}{
+	{MipMEIP, CauseMachineExternalInterrupt},
+	{MipMSIP, CauseMachineSoftwareInterrupt},
+	{MipMTIP, CauseMachineTimerInterrupt},
+	{MipSEIP, CauseSupervisorExternalInterrupt},
+	{MipSSIP, CauseSupervisorSoftwareInterrupt},
+	{MipSTIP, CauseSupervisorTimerInterrupt},
+}
+
+// pendingInterrupt returns the highest priority interrupt which is pending
+// and enabled. M-mode interrupts are enabled by mstatus.MIE in M-mode and
+// always enabled in lower modes. Delegated interrupts are enabled by
+// mstatus.SIE in S-mode, always enabled in U-mode and never taken in M-mode.
+// Interrupts are not taken without a trap handler (tvec == 0).
+func (c *Cpu) pendingInterrupt() (TrapCause, bool) {
+	f := &c.Csr
+	pending := f.Mip & f.Mie
+	if pending == 0 {
+		return 0, false
+	}
+	m := c.Priv < PrivM || f.Mstatus&MstatusMIE != 0
+	s := c.Priv < PrivS || c.Priv == PrivS && f.Mstatus&MstatusSIE != 0
+	for _, irq := range interrupts {
+		if pending&irq.bit == 0 {
+			continue
+		}
+		if f.Mideleg&irq.bit == 0 {
+			if m && f.Mtvec != 0 {
+				return irq.cause, true
+			}
+		} else if s && f.Stvec != 0 {
+			return irq.cause, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,120 @@
+package rv32i
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_TrapReturned(t *testing.T) {
+	type TestData struct {
+		Name  string
+		Setup func(e *Emulator)
+		Codes []uint32
+		Cause TrapCause
+		Tval  uint32
+		PC    uint32
+	}
+
+	for _, td := range []TestData{
+		{
+			Name:  "illegal instruction",
+			Codes: []uint32{GenCode(OpAddi, 1, 0, 1), 0xffffffff},
+			Cause: CauseIllegalInstruction, Tval: 0xffffffff, PC: 4,
+		},
+		{
+			Name:  "sll with funct7 0100000",
+			Codes: []uint32{0x40001033},
+			Cause: CauseIllegalInstruction, Tval: 0x40001033, PC: 0,
+		},
+		{
+			Name:  "misaligned lw",
+			Codes: []uint32{GenCode(OpLw, 1, 2, 0)},
+			Cause: CauseLoadAddressMisaligned, Tval: 2, PC: 0,
+		},
+		{
+			Name:  "misaligned sh",
+			Codes: []uint32{GenCode(OpSh, 1, 3, 0)},
+			Cause: CauseStoreAddressMisaligned, Tval: 3, PC: 0,
+		},
+		{
+			Name:  "lw out of memory",
+			Setup: func(e *Emulator) { e.Cpu.X[2] = MaxMemory },
+			Codes: []uint32{GenCode(OpLw, 1, 0, 2)},
+			Cause: CauseLoadAccessFault, Tval: MaxMemory, PC: 0,
+		},
+		{
+			Name:  "sb out of memory",
+			Setup: func(e *Emulator) { e.Cpu.X[2] = 0xfffffff0 },
+			Codes: []uint32{GenCode(OpSb, 1, 0, 2)},
+			Cause: CauseStoreAccessFault, Tval: 0xfffffff0, PC: 0,
+		},
+		{
+			Name:  "fetch out of memory",
+			Setup: func(e *Emulator) { e.Cpu.PC = MaxMemory },
+			Cause: CauseInstructionAccessFault, Tval: MaxMemory, PC: MaxMemory,
+		},
+		{
+			Name:  "ebreak",
+			Codes: []uint32{0x00100073},
+			Cause: CauseBreakpoint, Tval: 0, PC: 0,
+		},
+	} {
+		e := NewEmulator()
+		loadCode(e, 0, td.Codes...)
+		if td.Setup != nil {
+			td.Setup(e)
+		}
+
+		err := e.Run()
+		var trap *Trap
+		if !errors.As(err, &trap) {
+			t.Errorf("%s: Run must return *Trap, but was %v", td.Name, err)
+			continue
+		}
+		if trap.Cause != td.Cause || trap.Tval != td.Tval || trap.PC != td.PC {
+			t.Errorf("%s: got %+v, want cause:%v, tval:0x%x, pc:0x%x", td.Name, trap, td.Cause, td.Tval, td.PC)
+		}
+	}
+
+	// the trap value is mtval or stval depending on the delegation
+	trap := &Trap{Cause: CauseLoadPageFault, Tval: 0x1000, PC: 0x10}
+	if got, want := trap.Error(), fmt.Sprintf("%v at pc 0x00000010, tval 0x00001000", CauseLoadPageFault); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// 2-byte aligned targets are legal with RV32C
+	e := NewEmulator()
+	loadCode(e, 0, GenCode(OpJal, 1, 6, 0))
+	err := e.Step()
+	if err != nil || e.Cpu.X[1] != 4 || e.Cpu.PC != 6 {
+		t.Errorf("jal to 6 failed. err:%v, ra:0x%08x, pc:0x%08x", err, e.Cpu.X[1], e.Cpu.PC)
+	}
+}
+
+func Test_TrapDelivered(t *testing.T) {
+	e := NewEmulator()
+	e.Cpu.Csr.Mtvec = 0x100
+	loadCode(e, 0,
+		GenCode(OpAddi, 1, 0, 3),
+		GenCode(OpLw, 2, 0, 1), // misaligned
+	)
+	loadCode(e, 0x100, GenCode(OpAddi, 3, 0, 42))
+
+	err := e.StepUntil(0x104)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Cpu.Csr.Mepc != 4 {
+		t.Errorf("mepc must be 0x%08x, but was 0x%08x", 4, e.Cpu.Csr.Mepc)
+	}
+	if e.Cpu.Csr.Mcause != uint32(CauseLoadAddressMisaligned) {
+		t.Errorf("mcause must be %d, but was %d", CauseLoadAddressMisaligned, e.Cpu.Csr.Mcause)
+	}
+	if e.Cpu.Csr.Mtval != 3 {
+		t.Errorf("mtval must be 0x%08x, but was 0x%08x", 3, e.Cpu.Csr.Mtval)
+	}
+	if e.Cpu.X[2] != 0 || e.Cpu.X[3] != 42 {
+		t.Errorf("Unexpected X2:%d, X3:%d", e.Cpu.X[2], e.Cpu.X[3])
+	}
+}
@@ -0,0 +1,223 @@
+package rv32i
+
+import (
+	"io"
+)
+
+// default UART mapping, same as QEMU virt
+const (
+	UARTBase = uint32(0x1000_0000)
+	UARTSize = uint32(0x100)
+)
+
+// 16550 registers
+const (
+	uartRBR = 0 // receive buffer (read), transmit holding (write), DLL if DLAB
+	uartIER = 1 // interrupt enable, DLM if DLAB
+	uartIIR = 2 // interrupt identification (read), FIFO control (write)
+	uartLCR = 3 // line control
+	uartMCR = 4 // modem control
+	uartLSR = 5 // line status
+	uartMSR = 6 // modem status
+	uartSCR = 7 // scratch
+)
+
+const (
+	uartIerRDA  = uint8(1 << 0) // received data available
+	uartIerTHRE = uint8(1 << 1) // transmit holding register empty
+
+	uartIirNone = uint8(0x01)
+	uartIirTHRE = uint8(0x02)
+	uartIirRDA  = uint8(0x04)
+	uartIirFIFO = uint8(0xc0)
+
+	uartFcrEnable = uint8(1 << 0)
+
+	uartLcrDLAB = uint8(1 << 7)
+
+	uartLsrDR   = uint8(1 << 0) // data ready
+	uartLsrTHRE = uint8(1 << 5)
+	uartLsrTEMT = uint8(1 << 6)
+
+	uartMcrLoop = uint8(1 << 4)
+)
+
+// UART is a NS16550A compatible UART with 1 byte wide registers.
+// Transmitted bytes are written to Writer and received bytes are read from
+// Reader. Reader is read by a goroutine from the first access to the receiver,
+// so it doesn't steal the input of programs which don't use the UART.
+type UART struct {
+	Reader io.Reader
+	Writer io.Writer
+
+	ier, lcr, mcr, scr uint8
+	dll, dlm           uint8
+	fifo               bool
+	// the THRE interrupt is pending until IIR is read or THR is written
+	threPending bool
+
+	rx      chan byte
+	rxData  byte
+	rxReady bool
+}
+
+func NewUART(r io.Reader, w io.Writer) *UART {
+	return &UART{
+		Reader: r,
+		Writer: w,
+	}
+}
+
+// startReceiver starts the reader goroutine
+func (u *UART) startReceiver() {
+	if u.rx == nil && u.Reader != nil {
+		u.rx = make(chan byte, 16)
+		go func(r io.Reader, rx chan<- byte) {
+			buf := make([]byte, 1)
+			for {
+				n, err := r.Read(buf)
+				if n > 0 {
+					rx <- buf[0]
+				}
+				if err != nil {
+					close(rx)
+					return
+				}
+			}
+		}(u.Reader, u.rx)
+	}
+}
+
+// receive moves a received byte into RBR
+func (u *UART) receive() {
+	if u.rxReady || u.rx == nil {
+		return
+	}
+	select {
+	case b, ok := <-u.rx:
+		if ok {
+			u.rxData, u.rxReady = b, true
+		}
+	default:
+	}
+}
+
+func (u *UART) iir() uint8 {
+	iir := uartIirNone
+	if u.ier&uartIerRDA != 0 && u.rxReady {
+		iir = uartIirRDA
+	} else if u.ier&uartIerTHRE != 0 && u.threPending {
+		iir = uartIirTHRE
+	}
+	if u.fifo {
+		iir |= uartIirFIFO
+	}
+	return iir
+}
+
+// Interrupt returns true if an enabled interrupt is pending
+func (u *UART) Interrupt() bool {
+	u.receive()
+	return u.iir()&uartIirNone == 0
+}
+
+// Read reads the register at offset. Wider accesses read the same register.
+func (u *UART) Read(offset uint32, size uint32) (uint32, error) {
+	dlab := u.lcr&uartLcrDLAB != 0
+
+	switch offset {
+	case uartRBR:
+		if dlab {
+			return uint32(u.dll), nil
+		}
+		u.startReceiver()
+		u.receive()
+		data := u.rxData
+		u.rxReady = false
+		return uint32(data), nil
+	case uartIER:
+		if dlab {
+			return uint32(u.dlm), nil
+		}
+		return uint32(u.ier), nil
+	case uartIIR:
+		u.startReceiver()
+		u.receive()
+		iir := u.iir()
+		if iir&^uartIirFIFO == uartIirTHRE {
+			u.threPending = false
+		}
+		return uint32(iir), nil
+	case uartLCR:
+		return uint32(u.lcr), nil
+	case uartMCR:
+		return uint32(u.mcr), nil
+	case uartLSR:
+		u.startReceiver()
+		u.receive()
+		// transmission completes immediately
+		lsr := uartLsrTHRE | uartLsrTEMT
+		if u.rxReady {
+			lsr |= uartLsrDR
+		}
+		return uint32(lsr), nil
+	case uartMSR:
+		return 0, nil
+	case uartSCR:
+		return uint32(u.scr), nil
+	default:
+		return 0, nil
+	}
+}
+
+// Write writes the register at offset. Only the lowest byte of wider accesses
+// is used.
+func (u *UART) Write(offset uint32, size uint32, data uint32) error {
+	dlab := u.lcr&uartLcrDLAB != 0
+	b := uint8(data)
+
+	switch offset {
+	case uartRBR:
+		if dlab {
+			u.dll = b
+			return nil
+		}
+		u.transmit(b)
+		u.threPending = true
+	case uartIER:
+		if dlab {
+			u.dlm = b
+			return nil
+		}
+		// enabling THRE interrupts raises one as THR is always empty
+		if u.ier&uartIerTHRE == 0 && b&uartIerTHRE != 0 {
+			u.threPending = true
+		}
+		u.ier = b & 0x0f
+		if u.ier&uartIerRDA != 0 {
+			u.startReceiver()
+		}
+	case uartIIR:
+		u.fifo = b&uartFcrEnable != 0
+	case uartLCR:
+		u.lcr = b
+	case uartMCR:
+		u.mcr = b & 0x1f
+	case uartSCR:
+		u.scr = b
+	}
+	return nil
+}
+
+func (u *UART) transmit(b byte) {
+	if u.mcr&uartMcrLoop != 0 {
+		// loopback
+		u.rxData, u.rxReady = b, true
+		return
+	}
+	if u.Writer != nil {
+		u.Writer.Write([]byte{b})
+	}
+}
+
+func (u *UART) Tick() {}
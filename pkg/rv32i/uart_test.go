@@ -0,0 +1,120 @@
+package rv32i
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newUARTEmulator(t *testing.T, u *UART) *Emulator {
+	cfg := DefaultEmulatorConfig()
+	cfg.Devices = append(cfg.Devices, Mapping{Base: UARTBase, Size: UARTSize, Device: u})
+	e, err := NewEmulatorWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// waitData polls LSR until a byte is received
+func waitData(t *testing.T, u *UART) {
+	for start := time.Now(); time.Since(start) < time.Second; {
+		if lsr, _ := u.Read(uartLSR, 1); uint8(lsr)&uartLsrDR != 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no data received")
+}
+
+func Test_UARTTransmit(t *testing.T) {
+	out := new(bytes.Buffer)
+	e := newUARTEmulator(t, NewUART(nil, out))
+
+	// a0 <- UARTBase, then writes "ok" to THR
+	loadCode(e, 0,
+		GenCode(OpLui, 10, int(UARTBase>>12), 0),
+		GenCode(OpAddi, 11, 0, 'o'),
+		GenCode(OpSb, 11, 0, 10),
+		GenCode(OpAddi, 11, 0, 'k'),
+		GenCode(OpSb, 11, 0, 10),
+		GenCode(OpLbu, 12, uartLSR, 10),
+	)
+	e.StepUntil(4 * 6)
+
+	if out.String() != "ok" {
+		t.Errorf("output must be ok, but was %q", out.String())
+	}
+	if e.Cpu.X[12] != uint32(uartLsrTHRE|uartLsrTEMT) {
+		t.Errorf("LSR must be 0x%02x, but was 0x%02x", uartLsrTHRE|uartLsrTEMT, e.Cpu.X[12])
+	}
+}
+
+func Test_UARTReceive(t *testing.T) {
+	u := NewUART(strings.NewReader("hi"), nil)
+
+	for _, want := range []byte("hi") {
+		waitData(t, u)
+		got, _ := u.Read(uartRBR, 1)
+		if byte(got) != want {
+			t.Errorf("RBR must be %q, but was %q", want, byte(got))
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if lsr, _ := u.Read(uartLSR, 1); uint8(lsr)&uartLsrDR != 0 {
+		t.Error("LSR.DR must be cleared after all data is read")
+	}
+}
+
+func Test_UARTRegisters(t *testing.T) {
+	u := NewUART(nil, nil)
+
+	// divisor latch
+	u.Write(uartLCR, 1, uint32(uartLcrDLAB|0x03))
+	u.Write(uartRBR, 1, 0x01)
+	u.Write(uartIER, 1, 0x02)
+	if dll, _ := u.Read(uartRBR, 1); dll != 0x01 {
+		t.Errorf("DLL must be 1, but was %d", dll)
+	}
+	if dlm, _ := u.Read(uartIER, 1); dlm != 0x02 {
+		t.Errorf("DLM must be 2, but was %d", dlm)
+	}
+	u.Write(uartLCR, 1, 0x03)
+	if ier, _ := u.Read(uartIER, 1); ier != 0 {
+		t.Errorf("IER must be 0, but was %d", ier)
+	}
+
+	// FIFO enabled, THRE interrupt is cleared by reading IIR
+	u.Write(uartIIR, 1, uint32(uartFcrEnable))
+	u.Write(uartIER, 1, uint32(uartIerTHRE))
+	if !u.Interrupt() {
+		t.Error("THRE interrupt must be pending")
+	}
+	if iir, _ := u.Read(uartIIR, 1); uint8(iir) != uartIirFIFO|uartIirTHRE {
+		t.Errorf("IIR must be 0x%02x, but was 0x%02x", uartIirFIFO|uartIirTHRE, iir)
+	}
+	if iir, _ := u.Read(uartIIR, 1); uint8(iir) != uartIirFIFO|uartIirNone {
+		t.Errorf("IIR must be 0x%02x, but was 0x%02x", uartIirFIFO|uartIirNone, iir)
+	}
+
+	// loopback raises a RDA interrupt
+	u.Write(uartIER, 1, uint32(uartIerRDA))
+	u.Write(uartMCR, 1, uint32(uartMcrLoop))
+	u.Write(uartRBR, 1, 'x')
+	if iir, _ := u.Read(uartIIR, 1); uint8(iir) != uartIirFIFO|uartIirRDA {
+		t.Errorf("IIR must be 0x%02x, but was 0x%02x", uartIirFIFO|uartIirRDA, iir)
+	}
+	if rbr, _ := u.Read(uartRBR, 1); rbr != 'x' {
+		t.Errorf("RBR must be x, but was %q", rune(rbr))
+	}
+	if u.Interrupt() {
+		t.Error("no interrupt must be pending")
+	}
+
+	// scratch
+	u.Write(uartSCR, 1, 0x5a)
+	if scr, _ := u.Read(uartSCR, 1); scr != 0x5a {
+		t.Errorf("SCR must be 0x5a, but was 0x%02x", scr)
+	}
+}
@@ -28,6 +28,15 @@ func chkerr(err error) {
 %type<stmt> lb_stmt lh_stmt lw_stmt lbu_stmt lhu_stmt sb_stmt sh_stmt sw_stmt
 %type<stmt> addi_stmt slti_stmt sltiu_stmt xori_stmt ori_stmt andi_stmt slli_stmt srli_stmt srai_stmt
 %type<stmt> add_stmt sub_stmt sll_stmt slt_stmt sltu_stmt xor_stmt srl_stmt sra_stmt or_stmt and_stmt
+// RV32M
+%type<stmt> mul_stmt mulh_stmt mulhsu_stmt mulhu_stmt div_stmt divu_stmt rem_stmt remu_stmt
+// RV32A
+%type<stmt> lr_w_stmt sc_w_stmt amoswap_w_stmt amoadd_w_stmt amoxor_w_stmt amoand_w_stmt
+%type<stmt> amoor_w_stmt amomin_w_stmt amomax_w_stmt amominu_w_stmt amomaxu_w_stmt
+// RV32F, RV32D
+%type<stmt> fload_stmt fstore_stmt farith_stmt fbinary_stmt ffma_stmt funary_stmt
+%type<stmt> fcvt_xf_stmt fcvt_fx_stmt fcmp_stmt fmv_xf_stmt fmv_fx_stmt fpseudo_stmt
+%type<tok> rm
 // pesudo instructions
 %type<stmt> beqz_stmt bnez_stmt blez_stmt bgez_stmt bltz_stmt bgtz_stmt bgt_stmt ble_stmt bgtu_stmt bleu_stmt
 %type<stmt> call_stmt j_stmt jr_stmt la_stmt li_stmt mv_stmt neg_stmt nop_stmt not_stmt
@@ -42,6 +51,13 @@ func chkerr(err error) {
 %token<tok> LB LH LW LBU LHU SB SH SW
 %token<tok> ADDI SLTI SLTIU XORI ORI ANDI SLLI SRLI SRAI
 %token<tok> ADD SUB SLL SLT SLTU XOR SRL SRA OR AND
+// RV32M
+%token<tok> MUL MULH MULHSU MULHU DIV DIVU REM REMU
+// RV32A
+%token<tok> LR_W SC_W AMOSWAP_W AMOADD_W AMOXOR_W AMOAND_W AMOOR_W AMOMIN_W AMOMAX_W AMOMINU_W AMOMAXU_W
+// RV32F, RV32D. FP instructions are grouped by their operands.
+%token<tok> FREGISTER FLOAD FSTORE FARITH FBINARY FFMA FUNARY
+%token<tok> FCVT_XF FCVT_FX FCMP FMV_XF FMV_FX FPSEUDO
 // pseudo instructions
 %token<tok> BEQZ BNEZ BLEZ BGEZ BLTZ BGTZ BGT BLE BGTU BLEU
 %token<tok> CALL J JR LA LI MV NEG NOP NOT
@@ -112,6 +128,40 @@ stmt: /* empty */ {
     | sra_stmt { $$ = $1 }
     | or_stmt { $$ = $1 }
     | and_stmt { $$ = $1 }
+// RV32M
+    | mul_stmt { $$ = $1 }
+    | mulh_stmt { $$ = $1 }
+    | mulhsu_stmt { $$ = $1 }
+    | mulhu_stmt { $$ = $1 }
+    | div_stmt { $$ = $1 }
+    | divu_stmt { $$ = $1 }
+    | rem_stmt { $$ = $1 }
+    | remu_stmt { $$ = $1 }
+// RV32A
+    | lr_w_stmt { $$ = $1 }
+    | sc_w_stmt { $$ = $1 }
+    | amoswap_w_stmt { $$ = $1 }
+    | amoadd_w_stmt { $$ = $1 }
+    | amoxor_w_stmt { $$ = $1 }
+    | amoand_w_stmt { $$ = $1 }
+    | amoor_w_stmt { $$ = $1 }
+    | amomin_w_stmt { $$ = $1 }
+    | amomax_w_stmt { $$ = $1 }
+    | amominu_w_stmt { $$ = $1 }
+    | amomaxu_w_stmt { $$ = $1 }
+// RV32F, RV32D
+    | fload_stmt { $$ = $1 }
+    | fstore_stmt { $$ = $1 }
+    | farith_stmt { $$ = $1 }
+    | fbinary_stmt { $$ = $1 }
+    | ffma_stmt { $$ = $1 }
+    | funary_stmt { $$ = $1 }
+    | fcvt_xf_stmt { $$ = $1 }
+    | fcvt_fx_stmt { $$ = $1 }
+    | fcmp_stmt { $$ = $1 }
+    | fmv_xf_stmt { $$ = $1 }
+    | fmv_fx_stmt { $$ = $1 }
+    | fpseudo_stmt { $$ = $1 }
 // pseudo instructions
     | beqz_stmt { $$ = $1 }
     | bnez_stmt { $$ = $1 }
@@ -604,6 +654,354 @@ and_stmt: AND REGISTER COMMA REGISTER COMMA REGISTER {
         }
     }
 
+// RV32M
+mul_stmt: MUL REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* mul_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+mulh_stmt: MULH REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* mulh_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+mulhsu_stmt: MULHSU REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* mulhsu_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+mulhu_stmt: MULHU REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* mulhu_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+div_stmt: DIV REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* div_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+divu_stmt: DIVU REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* divu_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+rem_stmt: REM REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* rem_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+remu_stmt: REMU REGISTER COMMA REGISTER COMMA REGISTER {
+        log.Debugf("* remu_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+// RV32A
+lr_w_stmt: LR_W REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* lr_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$5.lit],
+            str1: ordering,
+        }
+    }
+
+sc_w_stmt: SC_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* sc_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amoswap_w_stmt: AMOSWAP_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amoswap_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amoadd_w_stmt: AMOADD_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amoadd_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amoxor_w_stmt: AMOXOR_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amoxor_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amoand_w_stmt: AMOAND_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amoand_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amoor_w_stmt: AMOOR_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amoor_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amomin_w_stmt: AMOMIN_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amomin_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amomax_w_stmt: AMOMAX_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amomax_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amominu_w_stmt: AMOMINU_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amominu_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+amomaxu_w_stmt: AMOMAXU_W REGISTER COMMA REGISTER COMMA LP REGISTER RP {
+        log.Debugf("* amomaxu_w_stmt: %+v", $1)
+        name, ordering := splitOrdering($1.lit)
+        $$ = &statement{
+            opcode: name,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.Regs[$7.lit],
+            op3: rv32i.Regs[$4.lit],
+            str1: ordering,
+        }
+    }
+
+// RV32F, RV32D
+fload_stmt: FLOAD FREGISTER COMMA NUMBER LP REGISTER RP {
+        log.Debugf("* fload_stmt: %+v", $1)
+        val, err := strconv.Atoi($4.lit)
+        chkerr(err)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: val,
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+fstore_stmt: FSTORE FREGISTER COMMA NUMBER LP REGISTER RP {
+        log.Debugf("* fstore_stmt: %+v", $1)
+        val, err := strconv.Atoi($4.lit)
+        chkerr(err)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: val,
+            op3: rv32i.Regs[$6.lit],
+        }
+    }
+
+farith_stmt: FARITH FREGISTER COMMA FREGISTER COMMA FREGISTER rm {
+        log.Debugf("* farith_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            op3: rv32i.FRegs[$6.lit],
+            str1: $7.lit,
+        }
+    }
+
+fbinary_stmt: FBINARY FREGISTER COMMA FREGISTER COMMA FREGISTER {
+        log.Debugf("* fbinary_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            op3: rv32i.FRegs[$6.lit],
+        }
+    }
+
+ffma_stmt: FFMA FREGISTER COMMA FREGISTER COMMA FREGISTER COMMA FREGISTER rm {
+        log.Debugf("* ffma_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            op3: rv32i.FRegs[$6.lit],
+            op4: rv32i.FRegs[$8.lit],
+            str1: $9.lit,
+        }
+    }
+
+funary_stmt: FUNARY FREGISTER COMMA FREGISTER rm {
+        log.Debugf("* funary_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            str1: $5.lit,
+        }
+    }
+
+fcvt_xf_stmt: FCVT_XF REGISTER COMMA FREGISTER rm {
+        log.Debugf("* fcvt_xf_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            str1: $5.lit,
+        }
+    }
+
+fcvt_fx_stmt: FCVT_FX FREGISTER COMMA REGISTER rm {
+        log.Debugf("* fcvt_fx_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+            str1: $5.lit,
+        }
+    }
+
+fcmp_stmt: FCMP REGISTER COMMA FREGISTER COMMA FREGISTER {
+        log.Debugf("* fcmp_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+            op3: rv32i.FRegs[$6.lit],
+        }
+    }
+
+fmv_xf_stmt: FMV_XF REGISTER COMMA FREGISTER {
+        log.Debugf("* fmv_xf_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.Regs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+        }
+    }
+
+fmv_fx_stmt: FMV_FX FREGISTER COMMA REGISTER {
+        log.Debugf("* fmv_fx_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.Regs[$4.lit],
+        }
+    }
+
+fpseudo_stmt: FPSEUDO FREGISTER COMMA FREGISTER {
+        log.Debugf("* fpseudo_stmt: %+v", $1)
+        $$ = &statement{
+            opcode: $1.lit,
+            op1: rv32i.FRegs[$2.lit],
+            op2: rv32i.FRegs[$4.lit],
+        }
+    }
+
+// optional rounding mode
+rm: /* empty */ {
+        $$ = token{}
+    }
+    | COMMA IDENT {
+        _, err := roundingMode($2.lit)
+        chkerr(err)
+        $$ = $2
+    }
+
 // pseudo instructions
 beqz_stmt: BEQZ REGISTER COMMA NUMBER {
         log.Debugf("* beqz_stmt")
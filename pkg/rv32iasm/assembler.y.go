@@ -74,30 +74,62 @@ const SRL = 57387
 const SRA = 57388
 const OR = 57389
 const AND = 57390
-const BEQZ = 57391
-const BNEZ = 57392
-const BLEZ = 57393
-const BGEZ = 57394
-const BLTZ = 57395
-const BGTZ = 57396
-const BGT = 57397
-const BLE = 57398
-const BGTU = 57399
-const BLEU = 57400
-const CALL = 57401
-const J = 57402
-const JR = 57403
-const LA = 57404
-const LI = 57405
-const MV = 57406
-const NEG = 57407
-const NOP = 57408
-const NOT = 57409
-const SEQZ = 57410
-const SNEZ = 57411
-const SLTZ = 57412
-const SGTZ = 57413
-const RET = 57414
+const MUL = 57391
+const MULH = 57392
+const MULHSU = 57393
+const MULHU = 57394
+const DIV = 57395
+const DIVU = 57396
+const REM = 57397
+const REMU = 57398
+const LR_W = 57399
+const SC_W = 57400
+const AMOSWAP_W = 57401
+const AMOADD_W = 57402
+const AMOXOR_W = 57403
+const AMOAND_W = 57404
+const AMOOR_W = 57405
+const AMOMIN_W = 57406
+const AMOMAX_W = 57407
+const AMOMINU_W = 57408
+const AMOMAXU_W = 57409
+const FREGISTER = 57410
+const FLOAD = 57411
+const FSTORE = 57412
+const FARITH = 57413
+const FBINARY = 57414
+const FFMA = 57415
+const FUNARY = 57416
+const FCVT_XF = 57417
+const FCVT_FX = 57418
+const FCMP = 57419
+const FMV_XF = 57420
+const FMV_FX = 57421
+const FPSEUDO = 57422
+const BEQZ = 57423
+const BNEZ = 57424
+const BLEZ = 57425
+const BGEZ = 57426
+const BLTZ = 57427
+const BGTZ = 57428
+const BGT = 57429
+const BLE = 57430
+const BGTU = 57431
+const BLEU = 57432
+const CALL = 57433
+const J = 57434
+const JR = 57435
+const LA = 57436
+const LI = 57437
+const MV = 57438
+const NEG = 57439
+const NOP = 57440
+const NOT = 57441
+const SEQZ = 57442
+const SNEZ = 57443
+const SLTZ = 57444
+const SGTZ = 57445
+const RET = 57446
 
 var assemblerToknames = [...]string{
 	"$end",
@@ -148,6 +180,38 @@ var assemblerToknames = [...]string{
 	"SRA",
 	"OR",
 	"AND",
+	"MUL",
+	"MULH",
+	"MULHSU",
+	"MULHU",
+	"DIV",
+	"DIVU",
+	"REM",
+	"REMU",
+	"LR_W",
+	"SC_W",
+	"AMOSWAP_W",
+	"AMOADD_W",
+	"AMOXOR_W",
+	"AMOAND_W",
+	"AMOOR_W",
+	"AMOMIN_W",
+	"AMOMAX_W",
+	"AMOMINU_W",
+	"AMOMAXU_W",
+	"FREGISTER",
+	"FLOAD",
+	"FSTORE",
+	"FARITH",
+	"FBINARY",
+	"FFMA",
+	"FUNARY",
+	"FCVT_XF",
+	"FCVT_FX",
+	"FCMP",
+	"FMV_XF",
+	"FMV_FX",
+	"FPSEUDO",
 	"BEQZ",
 	"BNEZ",
 	"BLEZ",
@@ -186,7 +250,7 @@ const assemblerEofCode = 1
 const assemblerErrCode = 2
 const assemblerInitialStackSize = 16
 
-//line pkg/rv32iasm/assembler.y:901
+//line pkg/rv32iasm/assembler.y:1299
 
 //line yacctab:1
 var assemblerExca = [...]int8{
@@ -197,105 +261,155 @@ var assemblerExca = [...]int8{
 
 const assemblerPrivate = 57344
 
-const assemblerLast = 414
+const assemblerLast = 650
 
 var assemblerAct = [...]int16{
-	128, 127, 65, 67, 66, 68, 69, 70, 71, 72,
-	73, 74, 75, 76, 77, 78, 79, 80, 81, 82,
-	83, 84, 85, 86, 87, 88, 89, 90, 91, 92,
-	93, 94, 95, 96, 97, 98, 99, 100, 101, 102,
-	103, 104, 105, 108, 107, 106, 109, 110, 111, 112,
-	113, 114, 115, 117, 116, 118, 119, 120, 121, 122,
-	123, 124, 125, 126, 141, 128, 140, 313, 129, 131,
-	132, 133, 134, 393, 262, 131, 132, 133, 134, 133,
-	134, 139, 138, 137, 186, 185, 392, 391, 390, 389,
-	388, 387, 386, 385, 384, 374, 373, 372, 371, 370,
-	369, 368, 367, 360, 320, 319, 318, 317, 316, 315,
-	314, 310, 309, 308, 307, 300, 299, 298, 297, 296,
-	295, 294, 293, 292, 291, 290, 289, 288, 287, 286,
-	285, 284, 199, 129, 200, 201, 202, 203, 283, 282,
-	273, 272, 271, 270, 269, 268, 267, 197, 196, 195,
-	194, 193, 192, 191, 190, 189, 188, 184, 183, 182,
-	181, 180, 179, 178, 177, 176, 175, 174, 173, 172,
-	171, 170, 169, 168, 167, 166, 165, 164, 163, 162,
-	161, 160, 159, 158, 157, 156, 155, 154, 153, 152,
-	151, 150, 149, 148, 147, 146, 145, 144, 143, 142,
-	136, 135, 311, 397, 396, 395, 394, 383, 382, 381,
-	380, 379, 378, 377, 376, 375, 366, 365, 364, 363,
-	362, 361, 312, 306, 305, 304, 303, 302, 301, 281,
-	280, 279, 278, 277, 276, 275, 274, 266, 265, 264,
-	263, 187, 406, 405, 404, 403, 402, 401, 400, 399,
-	398, 322, 336, 335, 334, 333, 332, 331, 330, 329,
-	321, 208, 359, 358, 357, 356, 355, 354, 353, 352,
-	351, 350, 349, 348, 347, 346, 345, 344, 343, 342,
-	341, 340, 339, 338, 337, 328, 327, 326, 325, 324,
-	323, 261, 260, 259, 258, 257, 256, 255, 254, 253,
-	252, 251, 250, 249, 248, 247, 246, 245, 244, 243,
-	242, 241, 240, 239, 238, 237, 236, 235, 234, 233,
-	232, 231, 230, 229, 228, 227, 226, 225, 224, 223,
-	222, 221, 220, 219, 218, 217, 216, 215, 214, 213,
-	212, 211, 210, 209, 207, 206, 205, 204, 198, 130,
-	64, 63, 62, 61, 60, 59, 58, 57, 56, 55,
-	53, 54, 52, 51, 50, 49, 48, 47, 46, 43,
-	44, 45, 42, 41, 40, 39, 38, 37, 36, 35,
-	34, 33, 32, 31, 30, 29, 28, 27, 26, 25,
-	24, 23, 22, 21, 20, 19, 18, 17, 16, 15,
-	14, 13, 12, 11, 10, 9, 8, 7, 6, 5,
-	4, 3, 2, 1,
+	535, 190, 189, 96, 98, 97, 99, 100, 101, 102,
+	103, 104, 105, 106, 107, 108, 109, 110, 111, 112,
+	113, 114, 115, 116, 117, 118, 119, 120, 121, 122,
+	123, 124, 125, 126, 127, 128, 129, 130, 131, 132,
+	133, 134, 135, 136, 137, 138, 139, 140, 141, 142,
+	143, 144, 145, 146, 147, 148, 149, 150, 151, 152,
+	190, 153, 154, 155, 156, 157, 158, 159, 160, 161,
+	162, 163, 164, 165, 166, 167, 170, 169, 168, 171,
+	172, 173, 174, 175, 176, 177, 179, 178, 180, 181,
+	182, 183, 184, 185, 186, 187, 188, 195, 196, 641,
+	603, 191, 193, 194, 195, 196, 601, 386, 193, 194,
+	195, 196, 600, 599, 455, 453, 452, 450, 449, 626,
+	448, 447, 446, 267, 266, 263, 261, 260, 259, 258,
+	257, 256, 201, 200, 199, 279, 278, 203, 625, 202,
+	624, 623, 622, 621, 620, 619, 618, 617, 598, 597,
+	585, 584, 583, 582, 581, 580, 579, 578, 577, 576,
+	191, 575, 574, 573, 572, 571, 570, 569, 568, 558,
+	557, 556, 555, 554, 553, 552, 551, 544, 519, 475,
+	474, 473, 472, 471, 470, 469, 465, 464, 463, 462,
+	454, 451, 443, 442, 441, 292, 440, 293, 294, 295,
+	296, 439, 438, 437, 436, 435, 434, 432, 431, 430,
+	429, 428, 427, 426, 425, 424, 423, 422, 421, 420,
+	419, 418, 417, 416, 415, 414, 413, 412, 411, 410,
+	409, 408, 407, 406, 397, 396, 395, 394, 393, 392,
+	391, 290, 289, 288, 287, 286, 285, 284, 283, 282,
+	281, 277, 276, 275, 274, 273, 272, 271, 270, 269,
+	268, 265, 264, 262, 255, 254, 253, 252, 251, 250,
+	249, 248, 247, 246, 245, 244, 243, 242, 241, 240,
+	239, 238, 237, 236, 235, 234, 233, 232, 231, 230,
+	229, 228, 227, 226, 225, 224, 223, 222, 221, 220,
+	219, 218, 217, 216, 215, 214, 213, 212, 211, 210,
+	209, 208, 207, 206, 205, 204, 198, 197, 602, 468,
+	466, 607, 606, 605, 604, 567, 566, 565, 564, 563,
+	562, 561, 560, 559, 550, 549, 548, 547, 546, 545,
+	467, 461, 460, 459, 458, 457, 456, 445, 444, 405,
+	404, 403, 402, 401, 400, 399, 398, 390, 389, 388,
+	387, 280, 640, 639, 638, 637, 636, 635, 634, 633,
+	632, 631, 628, 627, 616, 615, 614, 613, 612, 611,
+	610, 609, 608, 586, 477, 596, 595, 594, 593, 592,
+	591, 590, 589, 588, 587, 531, 530, 491, 490, 489,
+	488, 487, 486, 485, 484, 476, 433, 301, 536, 630,
+	543, 542, 541, 540, 539, 534, 533, 532, 529, 528,
+	527, 526, 525, 524, 523, 522, 521, 520, 518, 517,
+	516, 515, 514, 513, 512, 511, 510, 509, 508, 507,
+	506, 505, 504, 503, 502, 501, 500, 499, 498, 497,
+	496, 537, 538, 495, 494, 493, 492, 483, 482, 481,
+	480, 479, 478, 385, 384, 383, 382, 381, 380, 379,
+	378, 377, 376, 375, 374, 373, 372, 371, 370, 369,
+	368, 367, 366, 365, 364, 363, 362, 361, 360, 359,
+	358, 357, 356, 355, 354, 353, 352, 351, 350, 349,
+	348, 347, 346, 345, 344, 343, 342, 341, 340, 339,
+	338, 337, 336, 335, 334, 333, 332, 331, 330, 329,
+	328, 327, 326, 325, 324, 323, 322, 321, 320, 319,
+	318, 317, 316, 315, 314, 313, 312, 311, 310, 309,
+	308, 307, 306, 305, 304, 303, 302, 300, 299, 298,
+	297, 291, 192, 95, 94, 93, 92, 91, 90, 89,
+	88, 87, 86, 84, 85, 83, 82, 81, 80, 79,
+	78, 77, 74, 75, 76, 73, 72, 71, 70, 69,
+	68, 67, 66, 65, 64, 63, 62, 61, 60, 59,
+	58, 57, 56, 55, 54, 53, 52, 51, 50, 49,
+	629, 48, 47, 46, 45, 44, 43, 42, 41, 40,
+	39, 38, 37, 36, 35, 34, 33, 32, 31, 30,
+	29, 28, 27, 26, 25, 24, 23, 22, 21, 20,
+	19, 18, 17, 16, 15, 14, 13, 12, 11, 10,
+	9, 8, 642, 7, 6, 5, 4, 3, 2, 1,
 }
 
 var assemblerPact = [...]int16{
-	-1000, -9, 345, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -8, 548, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 2, 190, 189, 72, 55,
-	188, 187, 186, 185, 184, 183, 182, 181, 180, 179,
-	178, 177, 176, 175, 174, 173, 172, 171, 170, 169,
-	168, 167, 166, 165, 164, 163, 162, 161, 160, 159,
-	158, 157, 156, 155, 154, 153, 152, 151, 150, 149,
-	148, 147, 146, 74, 232, 145, 144, 143, 142, 141,
-	-1000, 140, 139, 138, 137, 136, -1000, 343, -1000, 56,
-	-1000, 56, 56, 56, 56, 341, 340, 339, -1000, -1000,
-	338, 254, 337, 336, 335, 334, 333, 332, 331, 330,
-	329, 328, 327, 326, 325, 324, 323, 322, 321, 320,
-	319, 318, 317, 316, 315, 314, 313, 312, 311, 310,
-	309, 308, 307, 306, 305, 304, 303, 302, 301, 300,
-	299, 298, 297, 296, 295, 294, -1000, -1000, -1000, 293,
-	292, 291, 290, 289, 288, 287, 286, 285, -1000, -4,
-	4, 4, -1000, -1000, 231, 230, 229, 228, 135, 134,
-	133, 132, 131, 130, 129, 227, 226, 225, 224, 223,
-	222, 221, 220, 128, 127, 120, 119, 118, 117, 116,
-	115, 114, 113, 112, 111, 110, 109, 108, 107, 106,
-	105, 104, 219, 218, 217, 216, 215, 214, 103, 102,
-	101, 100, 192, 213, 57, 99, 98, 97, 96, 95,
-	94, 93, -1000, -1000, -1000, -1000, 253, 243, 284, 283,
-	282, 281, 280, 279, 252, 251, 250, 249, 248, 247,
-	246, 245, 278, 277, 276, 275, 274, 273, 272, 271,
-	270, 269, 268, 267, 266, 265, 264, 263, 262, 261,
-	260, -1000, -1000, -1000, -1000, -1000, -1000, 259, 258, 257,
-	256, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 92, -1000, 212, 211, 210, 209, 208, 207, 91,
-	90, 89, 88, 87, 86, 85, 84, 206, 205, 204,
-	203, 202, 201, 200, 199, 198, 83, 82, 81, 80,
-	79, 78, 77, 76, 75, 62, 197, 196, 195, 194,
-	242, -1000, -1000, -1000, -1000, -1000, -1000, 241, 240, 239,
-	238, 237, 236, 235, 234, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 3, 306, 305, 123,
+	128, 304, 303, 302, 301, 300, 299, 298, 297, 296,
+	295, 294, 293, 292, 291, 290, 289, 288, 287, 286,
+	285, 284, 283, 282, 281, 280, 279, 278, 277, 276,
+	275, 274, 273, 272, 271, 270, 269, 268, 267, 266,
+	265, 264, 263, 262, 261, 260, 259, 258, 257, 256,
+	255, 254, 253, 63, 62, 61, 60, 59, 58, 252,
+	57, 251, 250, 56, 55, 249, 248, 247, 246, 245,
+	244, 243, 242, 241, 240, 125, 352, 239, 238, 237,
+	236, 235, -1000, 234, 233, 232, 231, 230, -1000, 546,
+	-1000, 51, -1000, 51, 51, 51, 51, 544, 543, 542,
+	-1000, -1000, 541, 400, 540, 539, 538, 537, 536, 535,
+	534, 533, 532, 531, 530, 529, 528, 527, 526, 525,
+	524, 523, 522, 521, 520, 519, 518, 517, 516, 515,
+	514, 513, 512, 511, 510, 509, 508, 507, 506, 505,
+	504, 503, 502, 501, 500, 499, 498, 497, 496, 495,
+	494, 493, 492, 491, 490, 489, 488, 487, 486, 485,
+	484, 483, 482, 481, 480, 479, 478, 477, 476, 475,
+	474, 473, 472, 471, 470, 469, 468, 467, 466, -1000,
+	-1000, -1000, 465, 464, 463, 462, 461, 460, 459, 458,
+	457, -1000, -3, -10, -10, -1000, -1000, 351, 350, 349,
+	348, 229, 228, 227, 226, 225, 224, 223, 347, 346,
+	345, 344, 343, 342, 341, 340, 222, 221, 220, 219,
+	218, 217, 216, 215, 214, 213, 212, 211, 210, 209,
+	208, 207, 206, 205, 204, 203, 202, 201, 200, 199,
+	198, 197, 196, 399, 195, 194, 193, 192, 191, 190,
+	185, 183, 182, 181, 339, 338, 54, 53, 52, 50,
+	49, 180, 48, 47, 179, 46, 337, 336, 335, 334,
+	333, 332, 178, 177, 176, 175, 310, 331, 309, 174,
+	173, 172, 171, 170, 169, 168, -1000, -1000, -1000, -1000,
+	398, 376, 456, 455, 454, 453, 452, 451, 397, 396,
+	395, 394, 393, 392, 391, 390, 450, 449, 448, 447,
+	444, 443, 442, 441, 440, 439, 438, 437, 436, 435,
+	434, 433, 432, 431, 430, 429, 428, 427, 426, 425,
+	424, 423, 422, 167, 421, 420, 419, 418, 417, 416,
+	415, 414, 413, 412, 389, 388, 411, 410, 409, 402,
+	402, 402, 408, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, 407, 406, 405, 404, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 166, -1000, 330, 329,
+	328, 327, 326, 325, 165, 164, 163, 162, 161, 160,
+	159, 158, 324, 323, 322, 321, 320, 319, 318, 317,
+	316, 157, 156, 155, 154, 153, 152, 151, 150, 148,
+	147, 146, 145, 144, 143, 142, 141, 140, 139, 375,
+	387, 386, 385, 384, 383, 382, 381, 380, 379, 378,
+	138, 137, 45, 44, 38, -1000, 308, -1000, -1000, 32,
+	315, 314, 313, 312, 374, -1000, -1000, -1000, -1000, -1000,
+	-1000, 373, 372, 371, 370, 369, 368, 367, 366, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 136, 135, 134,
+	133, 132, 131, 130, 129, 127, 108, 365, 364, 402,
+	-1000, 403, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 363, 362, 361,
+	360, 359, 358, 357, 356, 355, 354, -1000, -1000, -1000,
+	31, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, 402, -1000,
 }
 
 var assemblerPgo = [...]int16{
-	0, 413, 412, 411, 410, 409, 408, 407, 406, 405,
-	404, 403, 402, 401, 400, 399, 398, 397, 396, 395,
-	394, 393, 392, 391, 390, 389, 388, 387, 386, 385,
-	384, 383, 382, 381, 380, 379, 378, 377, 376, 375,
-	374, 373, 372, 371, 370, 369, 368, 367, 366, 365,
-	364, 363, 362, 361, 360, 359, 358, 357, 356, 355,
-	354, 353, 352, 351, 350, 2,
+	0, 649, 648, 647, 646, 645, 644, 643, 641, 640,
+	639, 638, 637, 636, 635, 634, 633, 632, 631, 630,
+	629, 628, 627, 626, 625, 624, 623, 622, 621, 620,
+	619, 618, 617, 616, 615, 614, 613, 612, 611, 610,
+	609, 608, 607, 606, 605, 604, 603, 602, 601, 599,
+	598, 597, 596, 595, 594, 593, 592, 591, 590, 589,
+	588, 587, 586, 585, 584, 583, 582, 581, 580, 579,
+	578, 0, 577, 576, 575, 574, 573, 572, 571, 570,
+	569, 568, 567, 566, 565, 564, 563, 562, 561, 560,
+	559, 558, 557, 556, 555, 554, 553, 3,
 }
 
 var assemblerR1 = [...]int8{
@@ -305,14 +419,21 @@ var assemblerR1 = [...]int8{
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 2, 2, 2, 2, 2, 2, 3, 4, 5,
-	5, 5, 6, 6, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
-	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
-	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
-	42, 43, 44, 45, 46, 47, 48, 49, 51, 52,
-	50, 50, 54, 53, 55, 56, 57, 58, 59, 60,
-	61, 62, 63, 64, 65, 65, 65, 65, 65, 65,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 3, 4,
+	5, 5, 5, 6, 6, 6, 7, 8, 9, 10,
+	11, 12, 13, 14, 15, 16, 17, 18, 19, 20,
+	21, 22, 23, 24, 25, 26, 27, 28, 29, 30,
+	31, 32, 33, 34, 35, 36, 37, 38, 39, 40,
+	41, 42, 43, 44, 45, 46, 47, 48, 49, 50,
+	51, 52, 53, 54, 55, 56, 57, 58, 59, 60,
+	61, 62, 63, 64, 65, 66, 67, 68, 69, 70,
+	71, 71, 72, 73, 74, 75, 76, 77, 78, 79,
+	80, 81, 83, 84, 82, 82, 86, 85, 87, 88,
+	89, 90, 91, 92, 93, 94, 95, 96, 97, 97,
+	97, 97, 97, 97,
 }
 
 var assemblerR2 = [...]int8{
@@ -322,14 +443,21 @@ var assemblerR2 = [...]int8{
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 4, 4, 4,
-	2, 2, 7, 5, 2, 6, 6, 6, 6, 6,
-	6, 7, 7, 7, 7, 7, 7, 7, 7, 6,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 4, 4,
+	4, 2, 2, 7, 5, 2, 6, 6, 6, 6,
+	6, 6, 7, 7, 7, 7, 7, 7, 7, 7,
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
-	6, 6, 6, 6, 6, 6, 6, 6, 4, 4,
-	4, 4, 4, 4, 6, 6, 6, 6, 2, 2,
-	4, 2, 4, 4, 4, 4, 1, 4, 4, 4,
-	4, 4, 1, 2, 1, 3, 3, 3, 3, 3,
+	6, 6, 6, 6, 6, 6, 6, 6, 8, 8,
+	8, 8, 8, 8, 8, 8, 8, 8, 7, 7,
+	7, 6, 9, 5, 5, 5, 6, 4, 4, 4,
+	0, 2, 4, 4, 4, 4, 4, 4, 6, 6,
+	6, 6, 2, 2, 4, 2, 4, 4, 4, 4,
+	1, 4, 4, 4, 4, 4, 1, 2, 1, 3,
+	3, 3, 3, 3,
 }
 
 var assemblerChk = [...]int16{
@@ -337,43 +465,67 @@ var assemblerChk = [...]int16{
 	-10, -11, -12, -13, -14, -15, -16, -17, -18, -19,
 	-20, -21, -22, -23, -24, -25, -26, -27, -28, -29,
 	-30, -31, -32, -33, -34, -35, -36, -37, -38, -39,
-	-40, -41, -42, -45, -44, -43, -46, -47, -48, -49,
-	-50, -51, -52, -54, -53, -55, -56, -57, -58, -59,
-	-60, -61, -62, -63, -64, -65, 13, 12, 14, 15,
-	16, 17, 18, 19, 20, 21, 22, 23, 24, 25,
-	26, 27, 28, 29, 30, 31, 32, 33, 34, 35,
-	36, 37, 38, 39, 40, 41, 42, 43, 44, 45,
-	46, 47, 48, 49, 50, 51, 54, 53, 52, 55,
-	56, 57, 58, 59, 60, 61, 63, 62, 64, 65,
-	66, 67, 68, 69, 70, 71, 72, 10, 9, 77,
-	4, 73, 74, 75, 76, 11, 11, 11, 10, 9,
-	11, 9, 11, 11, 11, 11, 11, 11, 11, 11,
+	-40, -41, -42, -43, -44, -45, -46, -47, -48, -49,
+	-50, -51, -52, -53, -54, -55, -56, -57, -58, -59,
+	-60, -61, -62, -63, -64, -65, -66, -67, -68, -69,
+	-70, -72, -73, -74, -77, -76, -75, -78, -79, -80,
+	-81, -82, -83, -84, -86, -85, -87, -88, -89, -90,
+	-91, -92, -93, -94, -95, -96, -97, 13, 12, 14,
+	15, 16, 17, 18, 19, 20, 21, 22, 23, 24,
+	25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+	35, 36, 37, 38, 39, 40, 41, 42, 43, 44,
+	45, 46, 47, 48, 49, 50, 51, 52, 53, 54,
+	55, 56, 57, 58, 59, 60, 61, 62, 63, 64,
+	65, 66, 67, 69, 70, 71, 72, 73, 74, 75,
+	76, 77, 78, 79, 80, 81, 82, 83, 86, 85,
+	84, 87, 88, 89, 90, 91, 92, 93, 95, 94,
+	96, 97, 98, 99, 100, 101, 102, 103, 104, 10,
+	9, 109, 4, 105, 106, 107, 108, 11, 11, 11,
+	10, 9, 11, 9, 11, 11, 11, 11, 11, 11,
 	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
 	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
 	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
-	11, 11, 11, 11, 11, 11, 10, 9, 11, 11,
-	11, 11, 11, 11, 11, 11, 11, 11, 5, -65,
-	-65, -65, -65, -65, 6, 6, 6, 6, 7, 6,
+	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
+	11, 11, 11, 11, 11, 11, 68, 68, 68, 68,
+	68, 68, 11, 68, 11, 11, 68, 68, 11, 11,
+	11, 11, 11, 11, 11, 11, 11, 11, 11, 10,
+	9, 11, 11, 11, 11, 11, 11, 11, 11, 11,
+	11, 5, -97, -97, -97, -97, -97, 6, 6, 6,
+	6, 7, 6, 6, 6, 6, 6, 6, 6, 6,
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
-	6, 6, 78, 9, 9, 9, 9, 11, 11, 11,
-	11, 11, 11, 11, 9, 9, 9, 9, 9, 9,
-	9, 9, 11, 11, 11, 11, 11, 11, 11, 11,
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	6, 6, 6, 6, 6, 6, 110, 9, 9, 9,
+	9, 11, 11, 11, 11, 11, 11, 11, 9, 9,
+	9, 9, 9, 9, 9, 9, 11, 11, 11, 11,
+	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
 	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
-	11, 9, 9, 9, 9, 9, 9, 11, 11, 11,
-	11, 10, 9, 10, 11, 11, 11, 11, 11, 11,
-	11, 7, 8, 6, 6, 6, 6, 6, 6, 7,
-	7, 7, 7, 7, 7, 7, 7, 6, 6, 6,
+	11, 11, 11, 7, 11, 11, 11, 11, 11, 11,
+	11, 11, 11, 11, 9, 9, 68, 68, 68, 68,
+	68, 11, 68, 68, 11, 68, 9, 9, 9, 9,
+	9, 9, 11, 11, 11, 11, 10, 9, 10, 11,
+	11, 11, 11, 11, 11, 11, 7, 8, 6, 6,
+	6, 6, 6, 6, 7, 7, 7, 7, 7, 7,
+	7, 7, 6, 6, 6, 6, 6, 6, 6, 6,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	6, 6, 6, 6, 6, 6, 6, 6, 6, 11,
 	6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
-	11, 9, 9, 9, 9, 9, 9, 11, 11, 11,
-	11, 11, 11, 11, 11, 9, 9, 9, 9, 9,
-	9, 9, 9, 9, 11, 11, 11, 11, 11, 11,
-	11, 11, 11, 11, 9, 9, 9, 9, 8, 8,
-	8, 8, 8, 8, 8, 8, 8,
+	7, 7, 6, 6, 6, -71, 6, -71, -71, 6,
+	6, 6, 6, 6, 11, 9, 9, 9, 9, 9,
+	9, 11, 11, 11, 11, 11, 11, 11, 11, 9,
+	9, 9, 9, 9, 9, 9, 9, 9, 11, 11,
+	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
+	11, 11, 11, 11, 11, 11, 8, 7, 7, 7,
+	7, 7, 7, 7, 7, 7, 7, 11, 11, 68,
+	68, 68, 10, 68, 9, 9, 9, 9, 8, 8,
+	8, 8, 8, 8, 8, 8, 8, 11, 11, 11,
+	11, 11, 11, 11, 11, 11, 11, 8, 8, -71,
+	6, 8, 8, 8, 8, 8, 8, 8, 8, 8,
+	8, 68, -71,
 }
 
 var assemblerDef = [...]int16{
@@ -383,41 +535,65 @@ var assemblerDef = [...]int16{
 	31, 32, 33, 34, 35, 36, 37, 38, 39, 40,
 	41, 42, 43, 44, 45, 46, 47, 48, 49, 50,
 	51, 52, 53, 54, 55, 56, 57, 58, 59, 60,
-	61, 62, 63, 64, 65, 66, 0, 0, 0, 0,
+	61, 62, 63, 64, 65, 66, 67, 68, 69, 70,
+	71, 72, 73, 74, 75, 76, 77, 78, 79, 80,
+	81, 82, 83, 84, 85, 86, 87, 88, 89, 90,
+	91, 92, 93, 94, 95, 96, 97, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 190, 0, 0, 0, 0, 0, 196, 0,
+	198, 0, 2, 0, 0, 0, 0, 0, 0, 0,
+	101, 102, 105, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	126, 0, 0, 0, 0, 0, 132, 0, 134, 0,
-	2, 0, 0, 0, 0, 0, 0, 0, 70, 71,
-	74, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 185,
+	182, 183, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 197, 0, 199, 200, 201, 202, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 121, 118, 119, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 133, 0,
-	135, 136, 137, 138, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 139, 67, 68, 69, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 203, 98, 99, 100,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 108, 109, 110, 113, 112, 111, 0, 0, 0,
-	0, 120, 122, 123, 124, 125, 127, 128, 129, 130,
-	131, 0, 73, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 75, 76, 77, 78, 79, 80, 0, 0, 0,
-	0, 0, 0, 0, 0, 89, 90, 91, 92, 93,
-	94, 95, 96, 97, 98, 99, 100, 101, 102, 103,
-	104, 105, 106, 107, 114, 115, 116, 117, 72, 81,
-	82, 83, 84, 85, 86, 87, 88,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 170,
+	170, 170, 0, 167, 168, 169, 172, 173, 174, 177,
+	176, 175, 0, 0, 0, 0, 184, 186, 187, 188,
+	189, 191, 192, 193, 194, 195, 0, 104, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 163, 0, 164, 165, 0,
+	0, 0, 0, 0, 0, 106, 107, 108, 109, 110,
+	111, 0, 0, 0, 0, 0, 0, 0, 0, 120,
+	121, 122, 123, 124, 125, 126, 127, 128, 129, 130,
+	131, 132, 133, 134, 135, 136, 137, 138, 139, 140,
+	141, 142, 143, 144, 145, 146, 147, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 170,
+	161, 0, 171, 166, 178, 179, 180, 181, 103, 112,
+	113, 114, 115, 116, 117, 118, 119, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 158, 159, 160,
+	0, 148, 149, 150, 151, 152, 153, 154, 155, 156,
+	157, 170, 162,
 }
 
 var assemblerTok1 = [...]int8{
@@ -425,7 +601,7 @@ var assemblerTok1 = [...]int8{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	77, 78, 75, 73, 3, 74, 3, 76,
+	109, 110, 107, 105, 3, 106, 3, 108,
 }
 
 var assemblerTok2 = [...]int8{
@@ -436,7 +612,10 @@ var assemblerTok2 = [...]int8{
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
-	72,
+	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
+	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
+	92, 93, 94, 95, 96, 97, 98, 99, 100, 101,
+	102, 103, 104,
 }
 
 var assemblerTok3 = [...]int8{
@@ -782,7 +961,7 @@ assemblerdefault:
 
 	case 1:
 		assemblerDollar = assemblerS[assemblerpt-0 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:57
+//line pkg/rv32iasm/assembler.y:73
 		{
 			log.Debug("* empty program")
 			assemblerVAL.program = &Program{
@@ -792,7 +971,7 @@ assemblerdefault:
 		}
 	case 2:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:64
+//line pkg/rv32iasm/assembler.y:80
 		{
 			log.Debugf("* appendind stmt %v, stmt count %d", assemblerDollar[2].stmt, len(assemblerVAL.program.statements))
 			assemblerVAL.program = &Program{
@@ -802,7 +981,7 @@ assemblerdefault:
 		}
 	case 3:
 		assemblerDollar = assemblerS[assemblerpt-0 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:72
+//line pkg/rv32iasm/assembler.y:88
 		{
 			log.Debug("* comment or empty stmt")
 			assemblerVAL.stmt = &statement{
@@ -811,519 +990,705 @@ assemblerdefault:
 		}
 	case 4:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:78
+//line pkg/rv32iasm/assembler.y:94
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 5:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:79
+//line pkg/rv32iasm/assembler.y:95
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 6:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:80
+//line pkg/rv32iasm/assembler.y:96
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 7:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:81
+//line pkg/rv32iasm/assembler.y:97
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 8:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:82
+//line pkg/rv32iasm/assembler.y:98
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 9:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:83
+//line pkg/rv32iasm/assembler.y:99
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 10:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:84
+//line pkg/rv32iasm/assembler.y:100
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 11:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:85
+//line pkg/rv32iasm/assembler.y:101
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 12:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:86
+//line pkg/rv32iasm/assembler.y:102
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 13:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:87
+//line pkg/rv32iasm/assembler.y:103
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 14:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:88
+//line pkg/rv32iasm/assembler.y:104
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 15:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:89
+//line pkg/rv32iasm/assembler.y:105
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 16:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:90
+//line pkg/rv32iasm/assembler.y:106
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 17:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:91
+//line pkg/rv32iasm/assembler.y:107
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 18:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:92
+//line pkg/rv32iasm/assembler.y:108
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 19:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:93
+//line pkg/rv32iasm/assembler.y:109
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 20:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:94
+//line pkg/rv32iasm/assembler.y:110
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 21:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:95
+//line pkg/rv32iasm/assembler.y:111
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 22:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:96
+//line pkg/rv32iasm/assembler.y:112
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 23:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:97
+//line pkg/rv32iasm/assembler.y:113
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 24:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:98
+//line pkg/rv32iasm/assembler.y:114
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 25:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:99
+//line pkg/rv32iasm/assembler.y:115
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 26:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:100
+//line pkg/rv32iasm/assembler.y:116
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 27:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:101
+//line pkg/rv32iasm/assembler.y:117
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 28:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:102
+//line pkg/rv32iasm/assembler.y:118
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 29:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:103
+//line pkg/rv32iasm/assembler.y:119
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 30:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:104
+//line pkg/rv32iasm/assembler.y:120
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 31:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:105
+//line pkg/rv32iasm/assembler.y:121
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 32:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:106
+//line pkg/rv32iasm/assembler.y:122
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 33:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:107
+//line pkg/rv32iasm/assembler.y:123
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 34:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:108
+//line pkg/rv32iasm/assembler.y:124
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 35:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:109
+//line pkg/rv32iasm/assembler.y:125
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 36:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:110
+//line pkg/rv32iasm/assembler.y:126
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 37:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:111
+//line pkg/rv32iasm/assembler.y:127
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 38:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:112
+//line pkg/rv32iasm/assembler.y:128
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 39:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:113
+//line pkg/rv32iasm/assembler.y:129
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 40:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:114
+//line pkg/rv32iasm/assembler.y:130
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 41:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:116
+//line pkg/rv32iasm/assembler.y:132
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 42:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:117
+//line pkg/rv32iasm/assembler.y:133
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 43:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:118
+//line pkg/rv32iasm/assembler.y:134
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 44:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:119
+//line pkg/rv32iasm/assembler.y:135
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 45:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:120
+//line pkg/rv32iasm/assembler.y:136
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 46:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:121
+//line pkg/rv32iasm/assembler.y:137
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 47:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:122
+//line pkg/rv32iasm/assembler.y:138
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 48:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:123
+//line pkg/rv32iasm/assembler.y:139
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 49:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:124
+//line pkg/rv32iasm/assembler.y:141
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 50:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:125
+//line pkg/rv32iasm/assembler.y:142
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 51:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:126
+//line pkg/rv32iasm/assembler.y:143
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 52:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:127
+//line pkg/rv32iasm/assembler.y:144
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 53:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:128
+//line pkg/rv32iasm/assembler.y:145
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 54:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:129
+//line pkg/rv32iasm/assembler.y:146
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 55:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:130
+//line pkg/rv32iasm/assembler.y:147
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 56:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:131
+//line pkg/rv32iasm/assembler.y:148
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 57:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:132
+//line pkg/rv32iasm/assembler.y:149
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 58:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:133
+//line pkg/rv32iasm/assembler.y:150
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 59:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:134
+//line pkg/rv32iasm/assembler.y:151
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 60:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:135
+//line pkg/rv32iasm/assembler.y:153
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 61:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:136
+//line pkg/rv32iasm/assembler.y:154
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 62:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:137
+//line pkg/rv32iasm/assembler.y:155
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 63:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:138
+//line pkg/rv32iasm/assembler.y:156
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 64:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:139
+//line pkg/rv32iasm/assembler.y:157
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 65:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:140
+//line pkg/rv32iasm/assembler.y:158
 		{
 			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 66:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:141
+//line pkg/rv32iasm/assembler.y:159
 		{
-			log.Debugf("* stmt expr %v", assemblerVAL.stmt)
-			assemblerVAL.stmt = &statement{
-				opcode: "expr",
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 67:
-		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:148
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:160
 		{
-			log.Debugf("* lui_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 68:
-		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:159
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:161
 		{
-			log.Debugf("* auipc_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 69:
-		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:170
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:162
 		{
-			log.Debugf("* jal_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 70:
-		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:180
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:163
 		{
-			log.Debugf("* jal_stmt (label): %+v", assemblerDollar[1].tok)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    1, // if rd is omitted, defaults to x1
-				str1:   assemblerDollar[2].tok.lit,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 71:
-		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:188
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:164
 		{
-			log.Debugf("* jal_stmt (offset): %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[2].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    1, // if rd is omitted, defaults to x1
-				op2:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 72:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:199
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:166
 		{
-			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 73:
-		assemblerDollar = assemblerS[assemblerpt-5 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:210
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:167
 		{
-			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[2].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    1, // if rd is omitted, defaults to x1
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 74:
-		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:221
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:168
 		{
-			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
-			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
-				op1:    1, // if rd is omitted, defaults to x1
-				op2:    0,
-				op3:    rv32i.Regs[assemblerDollar[2].tok.lit],
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 75:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:231
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:169
 		{
-			log.Debugf("* beq_stmt")
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: "beq",
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 76:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:243
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:170
 		{
-			log.Debugf("* bne_stmt")
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
-			assemblerVAL.stmt = &statement{
-				opcode: "bne",
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
-			}
+			assemblerVAL.stmt = assemblerDollar[1].stmt
 		}
 	case 77:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:171
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 78:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:172
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 79:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:173
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 80:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:174
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 81:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:175
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 82:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:176
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 83:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:177
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 84:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:178
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 85:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:179
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 86:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:180
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 87:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:181
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 88:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:182
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 89:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:183
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 90:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:184
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 91:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:185
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 92:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:186
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 93:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:187
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 94:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:188
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 95:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:189
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 96:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:190
+		{
+			assemblerVAL.stmt = assemblerDollar[1].stmt
+		}
+	case 97:
+		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:191
+		{
+			log.Debugf("* stmt expr %v", assemblerVAL.stmt)
+			assemblerVAL.stmt = &statement{
+				opcode: "expr",
+			}
+		}
+	case 98:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:198
+		{
+			log.Debugf("* lui_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+			}
+		}
+	case 99:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:209
+		{
+			log.Debugf("* auipc_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+			}
+		}
+	case 100:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:220
+		{
+			log.Debugf("* jal_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+			}
+		}
+	case 101:
+		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:230
+		{
+			log.Debugf("* jal_stmt (label): %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    1, // if rd is omitted, defaults to x1
+				str1:   assemblerDollar[2].tok.lit,
+			}
+		}
+	case 102:
+		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:238
+		{
+			log.Debugf("* jal_stmt (offset): %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[2].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    1, // if rd is omitted, defaults to x1
+				op2:    val,
+			}
+		}
+	case 103:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:249
+		{
+			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 104:
+		assemblerDollar = assemblerS[assemblerpt-5 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:260
+		{
+			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[2].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    1, // if rd is omitted, defaults to x1
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+			}
+		}
+	case 105:
+		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:271
+		{
+			log.Debugf("* jalr_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    1, // if rd is omitted, defaults to x1
+				op2:    0,
+				op3:    rv32i.Regs[assemblerDollar[2].tok.lit],
+			}
+		}
+	case 106:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:281
+		{
+			log.Debugf("* beq_stmt")
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: "beq",
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 107:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:293
+		{
+			log.Debugf("* bne_stmt")
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: "bne",
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 108:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:255
+//line pkg/rv32iasm/assembler.y:305
 		{
 			log.Debugf("* blt_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1335,9 +1700,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 78:
+	case 109:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:267
+//line pkg/rv32iasm/assembler.y:317
 		{
 			log.Debugf("* bge_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1349,9 +1714,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 79:
+	case 110:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:279
+//line pkg/rv32iasm/assembler.y:329
 		{
 			log.Debugf("* bltu_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1363,9 +1728,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 80:
+	case 111:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:291
+//line pkg/rv32iasm/assembler.y:341
 		{
 			log.Debugf("* bgeu_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1377,9 +1742,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 81:
+	case 112:
 		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:303
+//line pkg/rv32iasm/assembler.y:353
 		{
 			log.Debugf("* lb_stmt: %+v", assemblerDollar[1].tok)
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1391,353 +1756,764 @@ assemblerdefault:
 				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 82:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:315
+	case 113:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:365
+		{
+			log.Debugf("* lh_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 114:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:377
+		{
+			log.Debugf("* lw_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 115:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:389
+		{
+			log.Debugf("* lbu_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 116:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:401
+		{
+			log.Debugf("* lhu_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 117:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:413
+		{
+			log.Debugf("* sb_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 118:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:425
+		{
+			log.Debugf("* sh_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 119:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:437
+		{
+			log.Debugf("* sw_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 120:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:449
+		{
+			log.Debugf("* addi_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 121:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:461
+		{
+			log.Debugf("* slti_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 122:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:473
+		{
+			log.Debugf("* sltiu_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 123:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:485
+		{
+			log.Debugf("* xori_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 124:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:497
+		{
+			log.Debugf("* ori_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 125:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:509
+		{
+			log.Debugf("* andi_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 126:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:521
+		{
+			log.Debugf("* slli_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 127:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:533
+		{
+			log.Debugf("* srli_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 128:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:545
+		{
+			log.Debugf("* srai_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			chkerr(err)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    val,
+			}
+		}
+	case 129:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:557
+		{
+			log.Debugf("* add_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 130:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:567
+		{
+			log.Debugf("* sub_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 131:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:577
+		{
+			log.Debugf("* sll_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 132:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:587
+		{
+			log.Debugf("* slt_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 133:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:597
+		{
+			log.Debugf("* sltu_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 134:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:607
+		{
+			log.Debugf("* xor_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 135:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:617
+		{
+			log.Debugf("* srl_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 136:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:627
+		{
+			log.Debugf("* sra_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 137:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:637
+		{
+			log.Debugf("* or_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 138:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:647
+		{
+			log.Debugf("* and_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 139:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:658
+		{
+			log.Debugf("* mul_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 140:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:668
+		{
+			log.Debugf("* mulh_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 141:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:678
+		{
+			log.Debugf("* mulhsu_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 142:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:688
+		{
+			log.Debugf("* mulhu_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 143:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:698
+		{
+			log.Debugf("* div_stmt: %+v", assemblerDollar[1].tok)
+			assemblerVAL.stmt = &statement{
+				opcode: assemblerDollar[1].tok.lit,
+				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+			}
+		}
+	case 144:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:708
 		{
-			log.Debugf("* lh_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* divu_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
 				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 83:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:327
+	case 145:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:718
 		{
-			log.Debugf("* lw_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* rem_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
 				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 84:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:339
+	case 146:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:728
 		{
-			log.Debugf("* lbu_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* remu_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
+				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
 				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 85:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:351
+	case 147:
+		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:739
 		{
-			log.Debugf("* lhu_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* lr_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[5].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 86:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:363
+	case 148:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:750
 		{
-			log.Debugf("* sb_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* sc_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 87:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:375
+	case 149:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:762
 		{
-			log.Debugf("* sh_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* amoswap_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 88:
-		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:387
+	case 150:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:774
 		{
-			log.Debugf("* sw_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
-			chkerr(err)
+			log.Debugf("* amoadd_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    val,
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 89:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:399
+	case 151:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:786
 		{
-			log.Debugf("* addi_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amoxor_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 90:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:411
+	case 152:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:798
 		{
-			log.Debugf("* slti_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amoand_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 91:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:423
+	case 153:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:810
 		{
-			log.Debugf("* sltiu_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amoor_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 92:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:435
+	case 154:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:822
 		{
-			log.Debugf("* xori_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amomin_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 93:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:447
+	case 155:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:834
 		{
-			log.Debugf("* ori_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amomax_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 94:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:459
+	case 156:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:846
 		{
-			log.Debugf("* andi_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amominu_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 95:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:471
+	case 157:
+		assemblerDollar = assemblerS[assemblerpt-8 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:858
 		{
-			log.Debugf("* slli_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
-			chkerr(err)
+			log.Debugf("* amomaxu_w_stmt: %+v", assemblerDollar[1].tok)
+			name, ordering := splitOrdering(assemblerDollar[1].tok.lit)
 			assemblerVAL.stmt = &statement{
-				opcode: assemblerDollar[1].tok.lit,
+				opcode: name,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op2:    rv32i.Regs[assemblerDollar[7].tok.lit],
+				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
+				str1:   ordering,
 			}
 		}
-	case 96:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:483
+	case 158:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:871
 		{
-			log.Debugf("* srli_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			log.Debugf("* fload_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
 			chkerr(err)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 97:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:495
+	case 159:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:883
 		{
-			log.Debugf("* srai_stmt: %+v", assemblerDollar[1].tok)
-			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
+			log.Debugf("* fstore_stmt: %+v", assemblerDollar[1].tok)
+			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
 			chkerr(err)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    val,
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    val,
+				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 98:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:507
+	case 160:
+		assemblerDollar = assemblerS[assemblerpt-7 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:895
 		{
-			log.Debugf("* add_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* farith_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.FRegs[assemblerDollar[6].tok.lit],
+				str1:   assemblerDollar[7].tok.lit,
 			}
 		}
-	case 99:
+	case 161:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:517
+//line pkg/rv32iasm/assembler.y:906
 		{
-			log.Debugf("* sub_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fbinary_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.FRegs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 100:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:527
+	case 162:
+		assemblerDollar = assemblerS[assemblerpt-9 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:916
 		{
-			log.Debugf("* sll_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* ffma_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.FRegs[assemblerDollar[6].tok.lit],
+				op4:    rv32i.FRegs[assemblerDollar[8].tok.lit],
+				str1:   assemblerDollar[9].tok.lit,
 			}
 		}
-	case 101:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:537
+	case 163:
+		assemblerDollar = assemblerS[assemblerpt-5 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:928
 		{
-			log.Debugf("* slt_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* funary_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				str1:   assemblerDollar[5].tok.lit,
 			}
 		}
-	case 102:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:547
+	case 164:
+		assemblerDollar = assemblerS[assemblerpt-5 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:938
 		{
-			log.Debugf("* sltu_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fcvt_xf_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				str1:   assemblerDollar[5].tok.lit,
 			}
 		}
-	case 103:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:557
+	case 165:
+		assemblerDollar = assemblerS[assemblerpt-5 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:948
 		{
-			log.Debugf("* xor_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fcvt_fx_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
 				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				str1:   assemblerDollar[5].tok.lit,
 			}
 		}
-	case 104:
+	case 166:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:567
+//line pkg/rv32iasm/assembler.y:958
 		{
-			log.Debugf("* srl_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fcmp_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
+				op3:    rv32i.FRegs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 105:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:577
+	case 167:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:968
 		{
-			log.Debugf("* sra_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fmv_xf_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
 				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 106:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:587
+	case 168:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:977
 		{
-			log.Debugf("* or_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fmv_fx_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
 				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
 			}
 		}
-	case 107:
-		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:597
+	case 169:
+		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:986
 		{
-			log.Debugf("* and_stmt: %+v", assemblerDollar[1].tok)
+			log.Debugf("* fpseudo_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
-				op1:    rv32i.Regs[assemblerDollar[2].tok.lit],
-				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
-				op3:    rv32i.Regs[assemblerDollar[6].tok.lit],
+				op1:    rv32i.FRegs[assemblerDollar[2].tok.lit],
+				op2:    rv32i.FRegs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 108:
+	case 170:
+		assemblerDollar = assemblerS[assemblerpt-0 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:996
+		{
+			assemblerVAL.tok = token{}
+		}
+	case 171:
+		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
+//line pkg/rv32iasm/assembler.y:999
+		{
+			_, err := roundingMode(assemblerDollar[2].tok.lit)
+			chkerr(err)
+			assemblerVAL.tok = assemblerDollar[2].tok
+		}
+	case 172:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:608
+//line pkg/rv32iasm/assembler.y:1006
 		{
 			log.Debugf("* beqz_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1749,9 +2525,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 109:
+	case 173:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:620
+//line pkg/rv32iasm/assembler.y:1018
 		{
 			log.Debugf("* bnez_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1763,9 +2539,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 110:
+	case 174:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:632
+//line pkg/rv32iasm/assembler.y:1030
 		{
 			log.Debugf("* blez_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1777,9 +2553,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 111:
+	case 175:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:644
+//line pkg/rv32iasm/assembler.y:1042
 		{
 			log.Debugf("* bgez_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1791,9 +2567,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 112:
+	case 176:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:656
+//line pkg/rv32iasm/assembler.y:1054
 		{
 			log.Debugf("* bltz_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1805,9 +2581,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 113:
+	case 177:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:668
+//line pkg/rv32iasm/assembler.y:1066
 		{
 			log.Debugf("* bgtz_stmt")
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1819,9 +2595,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 114:
+	case 178:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:680
+//line pkg/rv32iasm/assembler.y:1078
 		{
 			log.Debugf("* bgt_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1833,9 +2609,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 115:
+	case 179:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:692
+//line pkg/rv32iasm/assembler.y:1090
 		{
 			log.Debugf("* ble_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1847,9 +2623,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 116:
+	case 180:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:704
+//line pkg/rv32iasm/assembler.y:1102
 		{
 			log.Debugf("* bgtu_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1861,9 +2637,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 117:
+	case 181:
 		assemblerDollar = assemblerS[assemblerpt-6 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:716
+//line pkg/rv32iasm/assembler.y:1114
 		{
 			log.Debugf("* bleu_stmt")
 			val, err := strconv.Atoi(assemblerDollar[6].tok.lit)
@@ -1875,9 +2651,9 @@ assemblerdefault:
 				op3:    val,
 			}
 		}
-	case 118:
+	case 182:
 		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:728
+//line pkg/rv32iasm/assembler.y:1126
 		{
 			log.Debugf("* j_stmt: %+v", assemblerDollar[1].tok)
 			val, err := strconv.Atoi(assemblerDollar[2].tok.lit)
@@ -1888,9 +2664,9 @@ assemblerdefault:
 				op2:    val,
 			}
 		}
-	case 119:
+	case 183:
 		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:739
+//line pkg/rv32iasm/assembler.y:1137
 		{
 			log.Debugf("* jr_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1900,9 +2676,9 @@ assemblerdefault:
 				op3:    rv32i.Regs[assemblerDollar[2].tok.lit],
 			}
 		}
-	case 120:
+	case 184:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:749
+//line pkg/rv32iasm/assembler.y:1147
 		{
 			log.Debugf("* call_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1911,9 +2687,9 @@ assemblerdefault:
 				str1:   assemblerDollar[4].tok.lit,
 			}
 		}
-	case 121:
+	case 185:
 		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:757
+//line pkg/rv32iasm/assembler.y:1155
 		{
 			assemblerVAL.stmt = &statement{
 				opcode: assemblerDollar[1].tok.lit,
@@ -1921,9 +2697,9 @@ assemblerdefault:
 				str1:   assemblerDollar[2].tok.lit,
 			}
 		}
-	case 122:
+	case 186:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:765
+//line pkg/rv32iasm/assembler.y:1163
 		{
 			log.Debugf("* li_stmt: %+v", assemblerDollar[1].tok)
 			val, err := strconv.Atoi(assemblerDollar[4].tok.lit)
@@ -1934,9 +2710,9 @@ assemblerdefault:
 				op2:    val,
 			}
 		}
-	case 123:
+	case 187:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:776
+//line pkg/rv32iasm/assembler.y:1174
 		{
 			log.Debugf("* la_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1945,9 +2721,9 @@ assemblerdefault:
 				str1:   assemblerDollar[4].tok.lit,
 			}
 		}
-	case 124:
+	case 188:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:785
+//line pkg/rv32iasm/assembler.y:1183
 		{
 			log.Debugf("* mv_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1956,9 +2732,9 @@ assemblerdefault:
 				op2:    rv32i.Regs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 125:
+	case 189:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:794
+//line pkg/rv32iasm/assembler.y:1192
 		{
 			log.Debugf("* neg_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1968,9 +2744,9 @@ assemblerdefault:
 				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 126:
+	case 190:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:804
+//line pkg/rv32iasm/assembler.y:1202
 		{
 			log.Debugf("* nop_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1980,9 +2756,9 @@ assemblerdefault:
 				op3:    0,
 			}
 		}
-	case 127:
+	case 191:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:814
+//line pkg/rv32iasm/assembler.y:1212
 		{
 			log.Debugf("* not_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -1992,9 +2768,9 @@ assemblerdefault:
 				op3:    -1,
 			}
 		}
-	case 128:
+	case 192:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:824
+//line pkg/rv32iasm/assembler.y:1222
 		{
 			log.Debugf("* seqz_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -2004,9 +2780,9 @@ assemblerdefault:
 				op3:    1,
 			}
 		}
-	case 129:
+	case 193:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:834
+//line pkg/rv32iasm/assembler.y:1232
 		{
 			log.Debugf("* snez_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -2016,9 +2792,9 @@ assemblerdefault:
 				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 130:
+	case 194:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:844
+//line pkg/rv32iasm/assembler.y:1242
 		{
 			log.Debugf("* sltz_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -2028,9 +2804,9 @@ assemblerdefault:
 				op3:    0,
 			}
 		}
-	case 131:
+	case 195:
 		assemblerDollar = assemblerS[assemblerpt-4 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:854
+//line pkg/rv32iasm/assembler.y:1252
 		{
 			log.Debugf("* sgtz_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -2040,9 +2816,9 @@ assemblerdefault:
 				op3:    rv32i.Regs[assemblerDollar[4].tok.lit],
 			}
 		}
-	case 132:
+	case 196:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:864
+//line pkg/rv32iasm/assembler.y:1262
 		{
 			log.Debugf("* ret_stmt")
 			assemblerVAL.stmt = &statement{
@@ -2052,9 +2828,9 @@ assemblerdefault:
 				op3:    1,
 			}
 		}
-	case 133:
+	case 197:
 		assemblerDollar = assemblerS[assemblerpt-2 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:874
+//line pkg/rv32iasm/assembler.y:1272
 		{
 			log.Debugf("* label_stmt: %+v", assemblerDollar[1].tok)
 			assemblerVAL.stmt = &statement{
@@ -2062,39 +2838,39 @@ assemblerdefault:
 				str1:   assemblerDollar[1].tok.lit,
 			}
 		}
-	case 134:
+	case 198:
 		assemblerDollar = assemblerS[assemblerpt-1 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:882
+//line pkg/rv32iasm/assembler.y:1280
 		{
 			assemblerVAL.expr = &numberExpression{Lit: assemblerDollar[1].tok.lit}
 		}
-	case 135:
+	case 199:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:885
+//line pkg/rv32iasm/assembler.y:1283
 		{
 			assemblerVAL.expr = &binOpExpression{LHS: assemblerDollar[1].expr, Operator: int('+'), RHS: assemblerDollar[3].expr}
 		}
-	case 136:
+	case 200:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:888
+//line pkg/rv32iasm/assembler.y:1286
 		{
 			assemblerVAL.expr = &binOpExpression{LHS: assemblerDollar[1].expr, Operator: int('-'), RHS: assemblerDollar[3].expr}
 		}
-	case 137:
+	case 201:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:891
+//line pkg/rv32iasm/assembler.y:1289
 		{
 			assemblerVAL.expr = &binOpExpression{LHS: assemblerDollar[1].expr, Operator: int('*'), RHS: assemblerDollar[3].expr}
 		}
-	case 138:
+	case 202:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:894
+//line pkg/rv32iasm/assembler.y:1292
 		{
 			assemblerVAL.expr = &binOpExpression{LHS: assemblerDollar[1].expr, Operator: int('/'), RHS: assemblerDollar[3].expr}
 		}
-	case 139:
+	case 203:
 		assemblerDollar = assemblerS[assemblerpt-3 : assemblerpt+1]
-//line pkg/rv32iasm/assembler.y:897
+//line pkg/rv32iasm/assembler.y:1295
 		{
 			assemblerVAL.expr = &parenExpression{SubExpr: assemblerDollar[2].expr}
 		}
@@ -220,6 +220,240 @@ func (e *Evaluator) gen_code(stmt *statement) ([]uint32, bool) {
 	case "and":
 		// op1: rd, op2: rs1: op3: rs2
 		return []uint32{rv32i.GenCode(rv32i.OpAnd, stmt.op1, stmt.op2, stmt.op3)}, true
+	// RV32M
+	case "mul":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpMul, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "mulh":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpMulh, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "mulhsu":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpMulhsu, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "mulhu":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpMulhu, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "div":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpDiv, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "divu":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpDivu, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "rem":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpRem, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "remu":
+		// op1: rd, op2: rs1: op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpRemu, stmt.op1, stmt.op2, stmt.op3)}, true
+	// RV32A
+	case "lr.w":
+		// op1: rd, op2: rs1, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpLrW, stmt.op1, stmt.op2, 0) | orderingBits(stmt.str1)}, true
+	case "sc.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpScW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amoswap.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmoswapW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amoadd.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmoaddW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amoxor.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmoxorW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amoand.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmoandW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amoor.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmoorW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amomin.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmominW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amomax.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmomaxW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amominu.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmominuW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	case "amomaxu.w":
+		// op1: rd, op2: rs1, op3: rs2, str1: aq/rl
+		return []uint32{rv32i.GenCode(rv32i.OpAmomaxuW, stmt.op1, stmt.op2, stmt.op3) | orderingBits(stmt.str1)}, true
+	// RV32F, RV32D
+	case "flw":
+		// op1: rd, op2: offset, op3: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFlw, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fld":
+		// op1: rd, op2: offset, op3: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFld, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsw":
+		// op1: rs2, op2: offset, op3: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsw, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsd":
+		// op1: rs2, op2: offset, op3: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsd, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fadd.s":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFaddS, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fsub.s":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFsubS, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fmul.s":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFmulS, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fdiv.s":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFdivS, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fadd.d":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFaddD, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fsub.d":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFsubD, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fmul.d":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFmulD, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fdiv.d":
+		// op1: rd, op2: rs1, op3: rs2, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFdivD, stmt.op1, stmt.op2, stmt.op3), stmt.str1)}, true
+	case "fsgnj.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsgnjn.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjnS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsgnjx.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjxS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmin.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFminS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmax.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFmaxS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsgnj.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsgnjn.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjnD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fsgnjx.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjxD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmin.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFminD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmax.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFmaxD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmadd.s":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFmaddS, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fmsub.s":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFmsubS, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fnmsub.s":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFnmsubS, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fnmadd.s":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFnmaddS, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fmadd.d":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFmaddD, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fmsub.d":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFmsubD, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fnmsub.d":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFnmsubD, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fnmadd.d":
+		// op1: rd, op2: rs1, op3: rs2, op4: rs3, str1: rm
+		return []uint32{withRm(rv32i.GenCodeR4(rv32i.OpFnmaddD, stmt.op1, stmt.op2, stmt.op3, stmt.op4), stmt.str1)}, true
+	case "fsqrt.s":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFsqrtS, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fsqrt.d":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFsqrtD, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.s.d":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtSD, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.d.s":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtDS, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.w.s":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtWS, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.wu.s":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtWuS, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.w.d":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtWD, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.wu.d":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtWuD, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.s.w":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtSW, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.s.wu":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtSWu, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.d.w":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtDW, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "fcvt.d.wu":
+		// op1: rd, op2: rs1, str1: rm
+		return []uint32{withRm(rv32i.GenCode(rv32i.OpFcvtDWu, stmt.op1, stmt.op2, 0), stmt.str1)}, true
+	case "feq.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFeqS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "flt.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFltS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fle.s":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFleS, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "feq.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFeqD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "flt.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFltD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fle.d":
+		// op1: rd, op2: rs1, op3: rs2
+		return []uint32{rv32i.GenCode(rv32i.OpFleD, stmt.op1, stmt.op2, stmt.op3)}, true
+	case "fmv.x.w":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFmvXW, stmt.op1, stmt.op2, 0)}, true
+	case "fclass.s":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFclassS, stmt.op1, stmt.op2, 0)}, true
+	case "fclass.d":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFclassD, stmt.op1, stmt.op2, 0)}, true
+	case "fmv.w.x":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFmvWX, stmt.op1, stmt.op2, 0)}, true
+	case "fmv.s":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjS, stmt.op1, stmt.op2, stmt.op2)}, true
+	case "fmv.d":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjD, stmt.op1, stmt.op2, stmt.op2)}, true
+	case "fneg.s":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjnS, stmt.op1, stmt.op2, stmt.op2)}, true
+	case "fneg.d":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjnD, stmt.op1, stmt.op2, stmt.op2)}, true
+	case "fabs.s":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjxS, stmt.op1, stmt.op2, stmt.op2)}, true
+	case "fabs.d":
+		// op1: rd, op2: rs1
+		return []uint32{rv32i.GenCode(rv32i.OpFsgnjxD, stmt.op1, stmt.op2, stmt.op2)}, true
 	// pseudo instructions
 	case "call":
 		// op1: rd, str1: symbol
@@ -355,3 +589,37 @@ func (e *Evaluator) evaluate_expr(expr expression) (int, error) {
 		return 0, errors.New("Unknown Expression type")
 	}
 }
+
+// orderingBits returns the aq and rl bits of atomic instructions
+func orderingBits(ordering string) uint32 {
+	switch ordering {
+	case "aq":
+		return 1 << 26
+	case "rl":
+		return 1 << 25
+	case "aqrl":
+		return 1<<26 | 1<<25
+	default:
+		return 0
+	}
+}
+
+// roundingMode returns the rm field of the rounding mode name
+func roundingMode(name string) (uint32, error) {
+	for rm := rv32i.RmRNE; rm <= rv32i.RmDYN; rm++ {
+		if name == rv32i.RmName(rm) {
+			return rm, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q", name)
+}
+
+// withRm sets the rounding mode of FP instructions. The dynamic rounding
+// mode is used if it's omitted.
+func withRm(code uint32, name string) uint32 {
+	if name == "" {
+		return code
+	}
+	rm, _ := roundingMode(name)
+	return code&^(0b111<<12) | rm<<12
+}
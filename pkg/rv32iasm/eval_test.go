@@ -226,3 +226,158 @@ hoge:
 		t.Errorf("x6 must be 0x%08x, but was 0x%08x", want, e.Cpu.X[6])
 	}
 }
+
+func Test_EvaluateM(t *testing.T) {
+	src := `	mul a2, a0, a1
+	mulh a2, a0, a1
+	mulhsu a2, a0, a1
+	mulhu a2, a0, a1
+	div a2, a0, a1
+	divu a2, a0, a1
+	rem a2, a0, a1
+	remu a2, a0, a1
+`
+	// llvm-mc -triple=riscv32 -mattr=+m -show-encoding
+	wants := []uint32{
+		0x02b50633, 0x02b51633, 0x02b52633, 0x02b53633,
+		0x02b54633, 0x02b55633, 0x02b56633, 0x02b57633,
+	}
+
+	scanner := NewScanner(strings.NewReader(src))
+	program, err := scanner.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := NewEvaluator()
+	_, err = ev.EvaluateProgram(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ev.Code) != len(wants) {
+		t.Fatalf("Unexpected length. got:%d, want:%d", len(ev.Code), len(wants))
+	}
+	for idx, got := range ev.Code {
+		if got != wants[idx] {
+			t.Errorf("Unexpected code at %d. got:0x%08x, want:0x%08x", idx, got, wants[idx])
+		}
+	}
+}
+
+func Test_EvaluateA(t *testing.T) {
+	src := `	lr.w a2, (a0)
+	sc.w a2, a1, (a0)
+	amoswap.w a2, a1, (a0)
+	amoadd.w a2, a1, (a0)
+	amoxor.w a2, a1, (a0)
+	amoand.w a2, a1, (a0)
+	amoor.w a2, a1, (a0)
+	amomin.w a2, a1, (a0)
+	amomax.w a2, a1, (a0)
+	amominu.w a2, a1, (a0)
+	amomaxu.w a2, a1, (a0)
+	lr.w.aq a2, (a0)
+	sc.w.rl a2, a1, (a0)
+	amoadd.w.aqrl a2, a1, (a0)
+`
+	// llvm-mc -triple=riscv32 -mattr=+a -show-encoding
+	wants := []uint32{
+		0x1005262f, 0x18b5262f, 0x08b5262f, 0x00b5262f,
+		0x20b5262f, 0x60b5262f, 0x40b5262f, 0x80b5262f,
+		0xa0b5262f, 0xc0b5262f, 0xe0b5262f,
+		0x1405262f, 0x1ab5262f, 0x06b5262f,
+	}
+
+	scanner := NewScanner(strings.NewReader(src))
+	program, err := scanner.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := NewEvaluator()
+	_, err = ev.EvaluateProgram(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ev.Code) != len(wants) {
+		t.Fatalf("Unexpected length. got:%d, want:%d", len(ev.Code), len(wants))
+	}
+	for idx, got := range ev.Code {
+		if got != wants[idx] {
+			t.Errorf("Unexpected code at %d. got:0x%08x, want:0x%08x", idx, got, wants[idx])
+		}
+	}
+}
+
+func Test_EvaluateFP(t *testing.T) {
+	src := `	flw fa0, -8(a0)
+	fsd fs11, 2040(sp)
+	fadd.s fa0, fa1, fa2
+	fdiv.d fa0, fa1, fa2, rtz
+	fsgnjx.s fa0, fa1, fa2
+	fmax.d ft0, ft1, ft2
+	fmadd.s fa0, fa1, fa2, fa3
+	fnmadd.d fa0, fa1, fa2, fa3, rne
+	fsqrt.d fa0, fa1
+	fcvt.s.d fa0, fa1, rdn
+	fcvt.d.s fa0, fa1
+	fcvt.w.s a0, fa1, rtz
+	fcvt.wu.d a0, fa1
+	fcvt.s.w fa0, a1
+	fcvt.d.wu fa0, a1
+	feq.s a0, fa0, fa1
+	fle.d a0, fa0, fa1
+	fmv.x.w a0, fa0
+	fclass.d a0, fa0
+	fmv.w.x fa0, a0
+	fmv.s fa0, fa1
+	fneg.d fa0, fa1
+	fabs.s fa0, fa1
+`
+	// llvm-mc -triple=riscv32 -mattr=+f,+d -show-encoding
+	wants := []uint32{
+		0xff852507, 0x7fb13c27, 0x00c5f553, 0x1ac59553,
+		0x20c5a553, 0x2a209053, 0x68c5f543, 0x6ac5854f,
+		0x5a05f553, 0x4015a553, 0x42058553, 0xc0059553,
+		0xc215f553, 0xd005f553, 0xd2158553, 0xa0b52553,
+		0xa2b50553, 0xe0050553, 0xe2051553, 0xf0050553,
+		0x20b58553, 0x22b59553, 0x20b5a553,
+	}
+
+	scanner := NewScanner(strings.NewReader(src))
+	program, err := scanner.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := NewEvaluator()
+	_, err = ev.EvaluateProgram(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ev.Code) != len(wants) {
+		t.Fatalf("Unexpected length. got:%d, want:%d", len(ev.Code), len(wants))
+	}
+	for idx, got := range ev.Code {
+		if got != wants[idx] {
+			t.Errorf("Unexpected code at %d. got:0x%08x, want:0x%08x", idx, got, wants[idx])
+		}
+	}
+
+	// GetCodeString must round-trip
+	for idx, code := range ev.Code {
+		text := rv32i.NewInstruction(code).GetCodeString()
+		program, err := NewScanner(strings.NewReader(text)).Parse()
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		ev2 := NewEvaluator()
+		ev2.EvaluateProgram(program)
+		if len(ev2.Code) != 1 || ev2.Code[0] != code {
+			t.Errorf("[%d] %s doesn't round-trip", idx, text)
+		}
+	}
+}
@@ -141,7 +141,7 @@ func (s *Scanner) skipComment() {
 
 func (s *Scanner) scanIdentifier() string {
 	var ret []rune
-	for isLetter(s.peek()) || isDigit(s.peek()) {
+	for isLetter(s.peek()) || isDigit(s.peek()) || s.peek() == '.' {
 		ret = append(ret, s.peek())
 		s.next()
 	}
@@ -152,8 +152,12 @@ func (s *Scanner) tokFromLit(lit string) int {
 	if _, ok := rv32i.Regs[lit]; ok {
 		return REGISTER
 	}
+	if _, ok := rv32i.FRegs[lit]; ok {
+		return FREGISTER
+	}
 
-	switch lit {
+	name, _ := splitOrdering(lit)
+	switch name {
 	case "lui":
 		return LUI
 	case "auipc":
@@ -228,6 +232,71 @@ func (s *Scanner) tokFromLit(lit string) int {
 		return OR
 	case "and":
 		return AND
+	// RV32M
+	case "mul":
+		return MUL
+	case "mulh":
+		return MULH
+	case "mulhsu":
+		return MULHSU
+	case "mulhu":
+		return MULHU
+	case "div":
+		return DIV
+	case "divu":
+		return DIVU
+	case "rem":
+		return REM
+	case "remu":
+		return REMU
+	// RV32A
+	case "lr.w":
+		return LR_W
+	case "sc.w":
+		return SC_W
+	case "amoswap.w":
+		return AMOSWAP_W
+	case "amoadd.w":
+		return AMOADD_W
+	case "amoxor.w":
+		return AMOXOR_W
+	case "amoand.w":
+		return AMOAND_W
+	case "amoor.w":
+		return AMOOR_W
+	case "amomin.w":
+		return AMOMIN_W
+	case "amomax.w":
+		return AMOMAX_W
+	case "amominu.w":
+		return AMOMINU_W
+	case "amomaxu.w":
+		return AMOMAXU_W
+	// RV32F, RV32D
+	case "flw", "fld":
+		return FLOAD
+	case "fsw", "fsd":
+		return FSTORE
+	case "fadd.s", "fsub.s", "fmul.s", "fdiv.s", "fadd.d", "fsub.d", "fmul.d", "fdiv.d":
+		return FARITH
+	case "fsgnj.s", "fsgnjn.s", "fsgnjx.s", "fmin.s", "fmax.s", "fsgnj.d", "fsgnjn.d", "fsgnjx.d", "fmin.d", "fmax.d":
+		return FBINARY
+	case "fmadd.s", "fmsub.s", "fnmsub.s", "fnmadd.s", "fmadd.d", "fmsub.d", "fnmsub.d", "fnmadd.d":
+		return FFMA
+	case "fsqrt.s", "fsqrt.d", "fcvt.s.d", "fcvt.d.s":
+		return FUNARY
+	case "fcvt.w.s", "fcvt.wu.s", "fcvt.w.d", "fcvt.wu.d":
+		return FCVT_XF
+	case "fcvt.s.w", "fcvt.s.wu", "fcvt.d.w", "fcvt.d.wu":
+		return FCVT_FX
+	case "feq.s", "flt.s", "fle.s", "feq.d", "flt.d", "fle.d":
+		return FCMP
+	case "fmv.x.w", "fclass.s", "fclass.d":
+		return FMV_XF
+	case "fmv.w.x":
+		return FMV_FX
+	case "fmv.s", "fneg.s", "fabs.s", "fmv.d", "fneg.d", "fabs.d":
+		return FPSEUDO
 	// pseudo instructions
 	case "beqz":
 		return BEQZ
@@ -282,6 +351,16 @@ func (s *Scanner) tokFromLit(lit string) int {
 	}
 }
 
+// splitOrdering splits "amoadd.w.aqrl" into "amoadd.w" and "aqrl"
+func splitOrdering(lit string) (string, string) {
+	for _, ordering := range []string{"aqrl", "aq", "rl"} {
+		if strings.HasSuffix(lit, ".w."+ordering) {
+			return strings.TrimSuffix(lit, "."+ordering), ordering
+		}
+	}
+	return lit, ""
+}
+
 func (s *Scanner) scanNumber() string {
 	var ret []rune
 	for isDigit(s.peek()) {
@@ -43,6 +43,21 @@ func Test_Parse(t *testing.T) {
 	sra ra, a0, a1
 	or ra, a0, a1
 	and ra, a0, a1
+	mul ra, a0, a1
+	mulh ra, a0, a1
+	mulhsu ra, a0, a1
+	mulhu ra, a0, a1
+	div ra, a0, a1
+	divu ra, a0, a1
+	rem ra, a0, a1
+	remu ra, a0, a1
+	lr.w ra, (a0)
+	sc.w.rl ra, a1, (a0)
+	amoadd.w.aqrl ra, a1, (a0)
+	fld fa0, 8(a0)
+	fadd.s ft0, fa1, f2, rtz
+	fnmsub.d fa0, fa1, fa2, fa3
+	fcvt.w.s a0, fa1
 	call a0, hoge
 	call hoge
 	li ra, 0
@@ -59,50 +74,65 @@ func Test_Parse(t *testing.T) {
 	}
 
 	wants := []statement{
-		{"label", 0, 0, 0, "boot"},
-		{"comment", 0, 0, 0, ""},
-		{"lui", 10, 4, 0, ""},
-		{"auipc", 2, 1, 0, ""},
-		{"beq", 1, 10, 123, ""},
-		{"bne", 1, 10, 123, ""},
-		{"blt", 1, 10, 123, ""},
-		{"bge", 1, 10, 123, ""},
-		{"bltu", 1, 10, 123, ""},
-		{"bgeu", 1, 10, 123, ""},
-		{"lb", 1, -100, 10, ""},
-		{"lh", 1, -100, 10, ""},
-		{"lw", 1, -100, 10, ""},
-		{"lbu", 1, -100, 10, ""},
-		{"lhu", 1, -100, 10, ""},
-		{"sb", 1, -100, 10, ""},
-		{"sh", 1, -100, 10, ""},
-		{"sw", 1, -100, 10, ""},
-		{"addi", 1, 10, -123, ""},
-		{"slti", 1, 10, -123, ""},
-		{"sltiu", 1, 10, 123, ""},
-		{"xori", 1, 10, -123, ""},
-		{"ori", 1, 10, -123, ""},
-		{"andi", 1, 10, -123, ""},
-		{"slli", 1, 10, 123, ""},
-		{"srli", 1, 10, 123, ""},
-		{"srai", 1, 10, 123, ""},
-		{"add", 1, 10, 11, ""},
-		{"sub", 1, 10, 11, ""},
-		{"sll", 1, 10, 11, ""},
-		{"slt", 1, 10, 11, ""},
-		{"sltu", 1, 10, 11, ""},
-		{"xor", 1, 10, 11, ""},
-		{"srl", 1, 10, 11, ""},
-		{"sra", 1, 10, 11, ""},
-		{"or", 1, 10, 11, ""},
-		{"and", 1, 10, 11, ""},
-		{"call", 10, 0, 0, "hoge"},
-		{"call", 1, 0, 0, "hoge"},
-		{"li", 1, 0, 0, ""},
-		{"li", 8, 0, 0, ""},
-		{"sltiu", 1, 10, 1, ""},
-		{"jalr", 0, 0, 1, ""},
-		{"comment", 0, 0, 0, ""},
+		{"label", 0, 0, 0, 0, "boot"},
+		{"comment", 0, 0, 0, 0, ""},
+		{"lui", 10, 4, 0, 0, ""},
+		{"auipc", 2, 1, 0, 0, ""},
+		{"beq", 1, 10, 123, 0, ""},
+		{"bne", 1, 10, 123, 0, ""},
+		{"blt", 1, 10, 123, 0, ""},
+		{"bge", 1, 10, 123, 0, ""},
+		{"bltu", 1, 10, 123, 0, ""},
+		{"bgeu", 1, 10, 123, 0, ""},
+		{"lb", 1, -100, 10, 0, ""},
+		{"lh", 1, -100, 10, 0, ""},
+		{"lw", 1, -100, 10, 0, ""},
+		{"lbu", 1, -100, 10, 0, ""},
+		{"lhu", 1, -100, 10, 0, ""},
+		{"sb", 1, -100, 10, 0, ""},
+		{"sh", 1, -100, 10, 0, ""},
+		{"sw", 1, -100, 10, 0, ""},
+		{"addi", 1, 10, -123, 0, ""},
+		{"slti", 1, 10, -123, 0, ""},
+		{"sltiu", 1, 10, 123, 0, ""},
+		{"xori", 1, 10, -123, 0, ""},
+		{"ori", 1, 10, -123, 0, ""},
+		{"andi", 1, 10, -123, 0, ""},
+		{"slli", 1, 10, 123, 0, ""},
+		{"srli", 1, 10, 123, 0, ""},
+		{"srai", 1, 10, 123, 0, ""},
+		{"add", 1, 10, 11, 0, ""},
+		{"sub", 1, 10, 11, 0, ""},
+		{"sll", 1, 10, 11, 0, ""},
+		{"slt", 1, 10, 11, 0, ""},
+		{"sltu", 1, 10, 11, 0, ""},
+		{"xor", 1, 10, 11, 0, ""},
+		{"srl", 1, 10, 11, 0, ""},
+		{"sra", 1, 10, 11, 0, ""},
+		{"or", 1, 10, 11, 0, ""},
+		{"and", 1, 10, 11, 0, ""},
+		{"mul", 1, 10, 11, 0, ""},
+		{"mulh", 1, 10, 11, 0, ""},
+		{"mulhsu", 1, 10, 11, 0, ""},
+		{"mulhu", 1, 10, 11, 0, ""},
+		{"div", 1, 10, 11, 0, ""},
+		{"divu", 1, 10, 11, 0, ""},
+		{"rem", 1, 10, 11, 0, ""},
+		{"remu", 1, 10, 11, 0, ""},
+		{"lr.w", 1, 10, 0, 0, ""},
+		{"sc.w", 1, 10, 11, 0, "rl"},
+		{"amoadd.w", 1, 10, 11, 0, "aqrl"},
+		{"fld", 10, 8, 10, 0, ""},
+		{"fadd.s", 0, 11, 2, 0, "rtz"},
+		{"fnmsub.d", 10, 11, 12, 13, ""},
+		{"fcvt.w.s", 10, 11, 0, 0, ""},
+		{"call", 10, 0, 0, 0, "hoge"},
+		{"call", 1, 0, 0, 0, "hoge"},
+		{"li", 1, 0, 0, 0, ""},
+		{"li", 8, 0, 0, 0, ""},
+		{"sltiu", 1, 10, 1, 0, ""},
+		{"jalr", 0, 0, 1, 0, ""},
+		{"comment", 0, 0, 0, 0, ""},
 	}
 
 	if len(program.statements) != len(wants) {
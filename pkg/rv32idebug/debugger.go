@@ -0,0 +1,521 @@
+// Package rv32idebug is a command line debugger of the emulator, which works
+// on a plain terminal
+package rv32idebug
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+const (
+	prompt       = "(rv32i) "
+	defaultDump  = 64 // bytes of x without the length
+	defaultDisas = 10 // instructions of disas without the count
+	maxBacktrace = 64
+	bytesPerLine = 16
+	regsPerLine  = 4
+)
+
+// command is a debugger command. args doesn't have the command name.
+type command struct {
+	names []string
+	usage string
+	help  string
+	run   func(d *Debugger, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{[]string{"help", "h"}, "help", "shows this help", (*Debugger).help},
+		{[]string{"break", "b"}, "break [addr|symbol]", "sets a breakpoint, or lists them without the argument", (*Debugger).breakCmd},
+		{[]string{"delete", "d"}, "delete [addr|symbol|all]", "deletes a breakpoint, or all of them", (*Debugger).deleteCmd},
+		{[]string{"step", "s"}, "step [n]", "executes n instructions (1 by default)", (*Debugger).step},
+		{[]string{"continue", "c"}, "continue", "runs until a breakpoint, exit or Ctrl-C", (*Debugger).cont},
+		{[]string{"regs", "r"}, "regs", "shows pc, priv and the integer registers", (*Debugger).regs},
+		{[]string{"print", "p"}, "print reg...", "shows registers by ABI name, x name, f name or CSR name", (*Debugger).print},
+		{[]string{"set"}, "set reg value", "writes a register", (*Debugger).set},
+		{[]string{"x"}, "x addr|symbol [len]", "hexdumps the memory", (*Debugger).hexdump},
+		{[]string{"disas", "dis"}, "disas [addr|symbol] [n]", "disassembles n instructions from addr (pc by default)", (*Debugger).disas},
+		{[]string{"backtrace", "bt"}, "backtrace", "shows the call stack from the ra/fp frames", (*Debugger).backtrace},
+		{[]string{"quit", "q"}, "quit", "quits the debugger", nil},
+	}
+}
+
+func findCommand(name string) (*command, bool) {
+	for _, cmd := range commands {
+		for _, n := range cmd.names {
+			if n == name {
+				return cmd, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Debugger reads commands from In and writes the results to Out
+type Debugger struct {
+	Emu *rv32i.Emulator
+	In  io.Reader
+	Out io.Writer
+
+	exited   bool
+	exitCode int
+}
+
+func NewDebugger(emu *rv32i.Emulator, in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		Emu: emu,
+		In:  in,
+		Out: out,
+	}
+}
+
+// Run reads and executes commands until quit or the end of In. An empty
+// line repeats the previous command like GDB.
+func (d *Debugger) Run() error {
+	sc := bufio.NewScanner(d.In)
+	d.showPC()
+	last := ""
+	for {
+		fmt.Fprint(d.Out, prompt)
+		if !sc.Scan() {
+			fmt.Fprintln(d.Out)
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			line = last
+		}
+		last = line
+		if !d.Exec(line) {
+			return nil
+		}
+	}
+}
+
+// Exec executes a command line, and returns false for quit
+func (d *Debugger) Exec(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	cmd, ok := findCommand(fields[0])
+	if !ok {
+		fmt.Fprintf(d.Out, "unknown command %s, try help\n", fields[0])
+		return true
+	}
+	if cmd.run == nil {
+		return false
+	}
+	if err := cmd.run(d, fields[1:]); err != nil {
+		fmt.Fprintf(d.Out, "error: %v\n", err)
+	}
+	return true
+}
+
+func (d *Debugger) help(args []string) error {
+	for _, cmd := range commands {
+		fmt.Fprintf(d.Out, "  %-26s %s\n", cmd.usage, cmd.help)
+	}
+	fmt.Fprintln(d.Out, "An empty line repeats the previous command. Addresses are physical.")
+	return nil
+}
+
+// parseValue accepts a hex (0x...), decimal or negative decimal value, or a
+// symbol name
+func (d *Debugger) parseValue(s string) (uint32, error) {
+	if strings.HasPrefix(s, "0x") {
+		v, err := strconv.ParseUint(s[2:], 16, 32)
+		return uint32(v), err
+	}
+	if len(s) > 0 && (s[0] == '-' || s[0] >= '0' && s[0] <= '9') {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err == nil && (v < -1<<31 || v > 1<<32-1) {
+			err = fmt.Errorf("%s is out of range", s)
+		}
+		return uint32(v), err
+	}
+	if addr, ok := d.Emu.Symbols.Lookup(s); ok {
+		return addr, nil
+	}
+	return 0, fmt.Errorf("symbol %s not found", s)
+}
+
+// location formats addr with the symbol if any
+func (d *Debugger) location(addr uint32) string {
+	if _, ok := d.Emu.Symbols.Find(addr); ok {
+		return fmt.Sprintf("0x%08x <%s>", addr, d.Emu.Symbols.Format(addr))
+	}
+	return fmt.Sprintf("0x%08x", addr)
+}
+
+func (d *Debugger) breakCmd(args []string) error {
+	if len(args) == 0 {
+		bps := d.breakpoints()
+		if len(bps) == 0 {
+			fmt.Fprintln(d.Out, "no breakpoints")
+		}
+		for _, bp := range bps {
+			fmt.Fprintf(d.Out, "  %d %s\n", bp.ID, d.location(bp.PC))
+		}
+		return nil
+	}
+	for _, arg := range args {
+		addr, err := d.parseValue(arg)
+		if err != nil {
+			return err
+		}
+		if len(d.breakpointsAt(addr)) == 0 {
+			d.Emu.AddBreakpoint(addr, nil)
+		}
+		fmt.Fprintf(d.Out, "breakpoint at %s\n", d.location(addr))
+	}
+	return nil
+}
+
+func (d *Debugger) deleteCmd(args []string) error {
+	if len(args) == 0 || args[0] == "all" {
+		for _, bp := range d.breakpoints() {
+			d.Emu.RemoveBreakpoint(bp.ID)
+		}
+		return nil
+	}
+	for _, arg := range args {
+		addr, err := d.parseValue(arg)
+		if err != nil {
+			return err
+		}
+		bps := d.breakpointsAt(addr)
+		if len(bps) == 0 {
+			return fmt.Errorf("no breakpoint at %s", d.location(addr))
+		}
+		for _, bp := range bps {
+			d.Emu.RemoveBreakpoint(bp.ID)
+		}
+	}
+	return nil
+}
+
+// breakpoints returns the execution breakpoints of the Emulator
+func (d *Debugger) breakpoints() []rv32i.Breakpoint {
+	var bps []rv32i.Breakpoint
+	for _, bp := range d.Emu.Breakpoints() {
+		if bp.Kind == rv32i.WatchNone {
+			bps = append(bps, bp)
+		}
+	}
+	return bps
+}
+
+func (d *Debugger) breakpointsAt(addr uint32) []rv32i.Breakpoint {
+	var bps []rv32i.Breakpoint
+	for _, bp := range d.breakpoints() {
+		if bp.PC == addr {
+			bps = append(bps, bp)
+		}
+	}
+	return bps
+}
+
+func (d *Debugger) step(args []string) error {
+	n := uint64(1)
+	if len(args) > 0 {
+		v, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil || v == 0 {
+			return fmt.Errorf("invalid count %s", args[0])
+		}
+		n = v
+	}
+	return d.resume(context.Background(), n)
+}
+
+func (d *Debugger) cont(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return d.resume(ctx, 0)
+}
+
+// resume executes up to max instructions (unlimited if 0) and stops at
+// breakpoints. The instruction at pc is executed even if it has a
+// breakpoint, so that continue leaves it.
+func (d *Debugger) resume(ctx context.Context, max uint64) error {
+	if d.exited {
+		return fmt.Errorf("the program has exited with %d", d.exitCode)
+	}
+	r := d.Emu.RunWithOptions(ctx, rv32i.RunOptions{MaxInstructions: max})
+
+	switch r.Kind {
+	case rv32i.StopMaxInstructions:
+	case rv32i.StopBreakpoint:
+		fmt.Fprintf(d.Out, "breakpoint at %s\n", d.location(r.PC))
+	case rv32i.StopExit:
+		d.exited = true
+		d.exitCode = r.ExitCode
+		fmt.Fprintf(d.Out, "exited with %d after %d steps\n", r.ExitCode, r.Steps)
+		return nil
+	default:
+		fmt.Fprintf(d.Out, "stopped: %v\n", r)
+	}
+	d.showPC()
+	return nil
+}
+
+// showPC disassembles the instruction at pc
+func (d *Debugger) showPC() {
+	d.disassemble(d.Emu.Cpu.PC, 1)
+}
+
+// register returns a register by name, and a function to write it
+func (d *Debugger) register(name string) (uint64, func(uint32) error, bool) {
+	cpu := d.Emu.Cpu
+	if i, ok := rv32i.Regs[name]; ok {
+		return uint64(cpu.X[i]), func(v uint32) error {
+			if i != 0 {
+				cpu.X[i] = v
+			}
+			return nil
+		}, true
+	}
+	if i, ok := rv32i.FRegs[name]; ok {
+		return cpu.F[i], func(v uint32) error {
+			// NaN-boxed single
+			cpu.F[i] = 0xffffffff_00000000 | uint64(v)
+			return nil
+		}, true
+	}
+	switch name {
+	case "pc":
+		return uint64(cpu.PC), func(v uint32) error {
+			cpu.PC = v
+			return nil
+		}, true
+	case "priv":
+		return uint64(cpu.Priv), func(v uint32) error {
+			switch v {
+			case rv32i.PrivU, rv32i.PrivS, rv32i.PrivM:
+				cpu.Priv = v
+				return nil
+			}
+			return fmt.Errorf("invalid privilege mode %d", v)
+		}, true
+	}
+	for _, addr := range rv32i.CsrAddrs() {
+		if rv32i.CsrName(addr) != name {
+			continue
+		}
+		v, ok := cpu.ReadCsr(addr)
+		if !ok {
+			return 0, nil, false
+		}
+		return uint64(v), func(v uint32) error {
+			if !cpu.WriteCsr(addr, v) {
+				return fmt.Errorf("%s is read-only", name)
+			}
+			return nil
+		}, true
+	}
+	return 0, nil, false
+}
+
+func (d *Debugger) regs(args []string) error {
+	cpu := d.Emu.Cpu
+	fmt.Fprintf(d.Out, "pc   %s\n", d.location(cpu.PC))
+	fmt.Fprintf(d.Out, "priv %d\n", cpu.Priv)
+	for i := 0; i < len(cpu.X); i++ {
+		fmt.Fprintf(d.Out, "%-4s 0x%08x", rv32i.RegName(uint8(i)), cpu.X[i])
+		if i%regsPerLine == regsPerLine-1 {
+			fmt.Fprintln(d.Out)
+		} else {
+			fmt.Fprint(d.Out, "  ")
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) print(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: print reg...")
+	}
+	for _, name := range args {
+		v, _, ok := d.register(name)
+		if !ok {
+			return fmt.Errorf("unknown register %s", name)
+		}
+		if _, ok := rv32i.FRegs[name]; ok {
+			fmt.Fprintf(d.Out, "%s = 0x%016x\n", name, v)
+			continue
+		}
+		if _, ok := rv32i.Regs[name]; ok || name == "pc" {
+			fmt.Fprintf(d.Out, "%s = %s, %d\n", name, d.location(uint32(v)), int32(v))
+			continue
+		}
+		fmt.Fprintf(d.Out, "%s = 0x%08x, %d\n", name, v, int32(v))
+	}
+	return nil
+}
+
+func (d *Debugger) set(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set reg value")
+	}
+	_, write, ok := d.register(args[0])
+	if !ok {
+		return fmt.Errorf("unknown register %s", args[0])
+	}
+	v, err := d.parseValue(args[1])
+	if err != nil {
+		return err
+	}
+	return write(v)
+}
+
+func (d *Debugger) hexdump(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: x addr [len]")
+	}
+	addr, err := d.parseValue(args[0])
+	if err != nil {
+		return err
+	}
+	size := uint32(defaultDump)
+	if len(args) > 1 {
+		if size, err = d.parseValue(args[1]); err != nil {
+			return err
+		}
+	}
+	data, err := d.Emu.ReadBytes(addr, size)
+	if err != nil {
+		return err
+	}
+	for off := 0; off < len(data); off += bytesPerLine {
+		line := data[off:]
+		if len(line) > bytesPerLine {
+			line = line[:bytesPerLine]
+		}
+		fmt.Fprintf(d.Out, "0x%08x: ", addr+uint32(off))
+		for i := 0; i < bytesPerLine; i++ {
+			if i < len(line) {
+				fmt.Fprintf(d.Out, "%02x ", line[i])
+			} else {
+				fmt.Fprint(d.Out, "   ")
+			}
+		}
+		fmt.Fprint(d.Out, " ")
+		for _, b := range line {
+			if b < 0x20 || b > 0x7e {
+				b = '.'
+			}
+			fmt.Fprintf(d.Out, "%c", b)
+		}
+		fmt.Fprintln(d.Out)
+	}
+	return nil
+}
+
+func (d *Debugger) disas(args []string) error {
+	addr := d.Emu.Cpu.PC
+	n := defaultDisas
+	var err error
+	if len(args) > 0 {
+		if addr, err = d.parseValue(args[0]); err != nil {
+			return err
+		}
+	}
+	if len(args) > 1 {
+		if n, err = strconv.Atoi(args[1]); err != nil {
+			return err
+		}
+	}
+	d.disassemble(addr, n)
+	return nil
+}
+
+// disassemble prints n instructions from addr. pc is marked with "=>" and
+// breakpoints are marked with "*".
+func (d *Debugger) disassemble(addr uint32, n int) {
+	for i := 0; i < n; i++ {
+		lo, err := d.Emu.ReadU16(addr)
+		if err != nil {
+			fmt.Fprintf(d.Out, "error: %v\n", err)
+			return
+		}
+		code := uint32(lo)
+		if !rv32i.IsCompressed(code) {
+			hi, err := d.Emu.ReadU16(addr + 2)
+			if err != nil {
+				fmt.Fprintf(d.Out, "error: %v\n", err)
+				return
+			}
+			code |= uint32(hi) << 16
+		}
+		instr := rv32i.Decode(code)
+
+		mark := "  "
+		if addr == d.Emu.Cpu.PC {
+			mark = "=>"
+		}
+		bp := " "
+		if len(d.breakpointsAt(addr)) > 0 {
+			bp = "*"
+		}
+		hex := fmt.Sprintf("%08x", code)
+		if instr.Len() == 2 {
+			hex = fmt.Sprintf("    %04x", code)
+		}
+		fmt.Fprintf(d.Out, "%s%s %s: %s  %s\n", mark, bp, d.location(addr), hex, instr.GetCodeString())
+		addr += instr.Len()
+	}
+}
+
+// frames returns the return addresses of the call stack with pc at first.
+// It needs the frame pointer (-fno-omit-frame-pointer), where fp is the sp
+// on the entry, and ra and the previous fp are saved at fp-4 and fp-8.
+// A leaf function saves only the previous fp at fp-4, and its ra is still
+// in the register. A function before its prologue isn't detected, and
+// its caller is missing then.
+func (d *Debugger) frames() []uint32 {
+	cpu := d.Emu.Cpu
+	frames := []uint32{cpu.PC}
+	fp := cpu.X[rv32i.Regs["fp"]]
+	for i := 0; len(frames) < maxBacktrace && fp != 0; i++ {
+		ra, err := d.Emu.ReadU32(fp - 4)
+		if err != nil {
+			break
+		}
+		var next uint32
+		if i == 0 && ra > fp {
+			// a leaf function, stack addresses are above the code
+			next = ra
+			ra = cpu.X[rv32i.Regs["ra"]]
+		} else if next, err = d.Emu.ReadU32(fp - 8); err != nil {
+			break
+		}
+		if ra == 0 {
+			break
+		}
+		frames = append(frames, ra)
+		// the stack grows down
+		if next <= fp {
+			break
+		}
+		fp = next
+	}
+	return frames
+}
+
+func (d *Debugger) backtrace(args []string) error {
+	for i, addr := range d.frames() {
+		fmt.Fprintf(d.Out, "#%-2d %s\n", i, d.location(addr))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package rv32idebug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+const stackTop = 0x1000
+
+// newTestDebugger loads main, which calls the leaf function f with the
+// frame pointer, and exits with 3
+func newTestDebugger() (*Debugger, *bytes.Buffer) {
+	sp, fp, ra := rv32i.Regs["sp"], rv32i.Regs["fp"], rv32i.Regs["ra"]
+	a0, a1, a7 := rv32i.Regs["a0"], rv32i.Regs["a1"], rv32i.Regs["a7"]
+	codes := []uint32{
+		// main
+		rv32i.GenCode(rv32i.OpAddi, sp, sp, -16),
+		rv32i.GenCode(rv32i.OpSw, ra, 12, sp),
+		rv32i.GenCode(rv32i.OpSw, fp, 8, sp),
+		rv32i.GenCode(rv32i.OpAddi, fp, sp, 16),
+		rv32i.GenCode(rv32i.OpJal, ra, 0x10, 0), // 0x10: call f
+		rv32i.GenCode(rv32i.OpAddi, a0, 0, 3),
+		rv32i.GenCode(rv32i.OpAddi, a7, 0, int(rv32i.SysExit)),
+		0x00000073, // ecall
+		// f
+		rv32i.GenCode(rv32i.OpAddi, sp, sp, -16),
+		rv32i.GenCode(rv32i.OpSw, fp, 12, sp),
+		rv32i.GenCode(rv32i.OpAddi, fp, sp, 16),
+		rv32i.GenCode(rv32i.OpAddi, a1, 0, 7), // 0x2c
+		rv32i.GenCode(rv32i.OpLw, fp, 12, sp),
+		rv32i.GenCode(rv32i.OpAddi, sp, sp, 16),
+		rv32i.GenCode(rv32i.OpJalr, 0, 0, ra),
+	}
+
+	emu := rv32i.NewEmulator()
+	for idx, code := range codes {
+		emu.WriteU32(uint32(idx*4), code)
+	}
+	emu.Cpu.X[sp] = stackTop
+	emu.Syscalls = rv32i.NewDefaultSyscalls(rv32i.SyscallConfig{})
+	emu.Symbols.Add(rv32i.Symbol{Name: "main", Addr: 0, Size: 0x20, Func: true})
+	emu.Symbols.Add(rv32i.Symbol{Name: "f", Addr: 0x20, Size: 0x1c, Func: true})
+
+	out := &bytes.Buffer{}
+	return NewDebugger(emu, strings.NewReader(""), out), out
+}
+
+// exec executes a command and returns its output
+func exec(d *Debugger, out *bytes.Buffer, line string) string {
+	out.Reset()
+	d.Exec(line)
+	return out.String()
+}
+
+func Test_BreakStepContinue(t *testing.T) {
+	d, out := newTestDebugger()
+	cpu := d.Emu.Cpu
+
+	if s := exec(d, out, "break f"); !strings.Contains(s, "0x00000020 <f>") {
+		t.Errorf("break must set at f, but was %q", s)
+	}
+	exec(d, out, "b 0x14")
+	if s := exec(d, out, "break"); !strings.Contains(s, "1 0x00000020 <f>\n  2 0x00000014 <main+0x14>") {
+		t.Errorf("break must list the breakpoints, but was %q", s)
+	}
+	if s := exec(d, out, "step 2"); cpu.PC != 8 || !strings.Contains(s, "=>  0x00000008 <main+0x8>: 00812423  Sw s0, 8(sp)") {
+		t.Errorf("step 2 must stop at 8, but was 0x%x, %q", cpu.PC, s)
+	}
+	if s := exec(d, out, "c"); cpu.PC != 0x20 || !strings.Contains(s, "breakpoint at 0x00000020 <f>") {
+		t.Errorf("c must stop at f, but was 0x%x, %q", cpu.PC, s)
+	}
+	// step stops at breakpoints too
+	if exec(d, out, "s 100"); cpu.PC != 0x14 {
+		t.Errorf("step must stop at 0x14, but was 0x%x", cpu.PC)
+	}
+	exec(d, out, "delete all")
+	if s := exec(d, out, "c"); !strings.Contains(s, "exited with 3") {
+		t.Errorf("c must exit, but was %q", s)
+	}
+	if s := exec(d, out, "s"); !strings.Contains(s, "error: the program has exited with 3") {
+		t.Errorf("s must fail after exit, but was %q", s)
+	}
+}
+
+func Test_Registers(t *testing.T) {
+	type TestData struct {
+		Command string
+		Want    string
+	}
+	d, out := newTestDebugger()
+	for _, td := range []TestData{
+		{"set a0 -1", ""},
+		{"p a0", "a0 = 0xffffffff, -1"},
+		{"set x11 main", ""},
+		{"p a1 pc", "a1 = 0x00000000 <main>, 0\npc = 0x00000000 <main>, 0"},
+		{"set mscratch 0x10", ""},
+		{"p mscratch", "mscratch = 0x00000010, 16"},
+		{"set mvendorid 1", "error: mvendorid is read-only"},
+		{"set priv 2", "error: invalid privilege mode 2"},
+		{"p priv", "priv = 0x00000003, 3"},
+		{"set zero 1", ""},
+		{"p x0", "x0 = 0x00000000 <main>, 0"},
+		{"p foo", "error: unknown register foo"},
+		{"set fa0 0x3f800000", ""},
+		{"p f10", "f10 = 0xffffffff3f800000"},
+	} {
+		if s := strings.TrimSpace(exec(d, out, td.Command)); s != td.Want {
+			t.Errorf("%s must output %q, but was %q", td.Command, td.Want, s)
+		}
+	}
+	if s := exec(d, out, "regs"); !strings.Contains(s, "a0   0xffffffff") || !strings.Contains(s, "sp   0x00001000") {
+		t.Errorf("regs must show a0 and sp, but was %q", s)
+	}
+}
+
+func Test_Memory(t *testing.T) {
+	d, out := newTestDebugger()
+	d.Emu.WriteBytes(0x100, []byte("hello, world!\x00\x01\x02xyz"))
+
+	want := "0x00000100: 68 65 6c 6c 6f 2c 20 77 6f 72 6c 64 21 00 01 02  hello, world!...\n" +
+		"0x00000110: 78 79 7a                                         xyz\n"
+	if s := exec(d, out, "x 0x100 19"); s != want {
+		t.Errorf("x must hexdump, but was\n%s", s)
+	}
+	// c.li a0, 1 and ret
+	d.Emu.WriteU16(0x200, 0x4505)
+	d.Emu.WriteU32(0x202, 0x00008067)
+	want = "    0x00000200:     4505  c.li a0, 1\n" +
+		"    0x00000202: 00008067  Jalr zero, 0(ra)\n"
+	if s := exec(d, out, "disas 0x200 2"); s != want {
+		t.Errorf("disas must disassemble, but was %q", s)
+	}
+}
+
+func Test_Backtrace(t *testing.T) {
+	d, out := newTestDebugger()
+
+	exec(d, out, "b 0x2c")
+	exec(d, out, "c")
+	want := "#0  0x0000002c <f+0xc>\n#1  0x00000014 <main+0x14>\n"
+	if s := exec(d, out, "bt"); s != want {
+		t.Errorf("bt in the leaf function must be\n%s, but was\n%s", want, s)
+	}
+}
+
+func Test_Run(t *testing.T) {
+	d, out := newTestDebugger()
+	d.In = strings.NewReader("s\n\n\nfoo\nq\ns\n")
+
+	if err := d.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// the empty lines repeat step
+	if d.Emu.Cpu.PC != 0xc {
+		t.Errorf("pc must be 0xc, but was 0x%x", d.Emu.Cpu.PC)
+	}
+	if s := out.String(); !strings.Contains(s, "unknown command foo") || !strings.HasSuffix(s, prompt) {
+		t.Errorf("Run must stop at q, but was %q", s)
+	}
+}
@@ -0,0 +1,336 @@
+// Package rv32idiff runs rv32i.Emulator in lockstep with a reference commit
+// log of spike or an RTL simulator, and finds the first instruction whose
+// PC, register writes or memory writes differ.
+package rv32idiff
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+// DefaultHistory is the number of instructions reported before a divergence
+const DefaultHistory = 16
+
+// Options of Run
+type Options struct {
+	rv32i.RunOptions
+	History int  // DefaultHistory if 0
+	CSRs    bool // compares CSR writes, which differ among simulators
+	Loads   bool // compares load addresses
+}
+
+// Divergence is the first instruction which differs from the reference, or
+// which the reference retires after the emulator stopped
+type Divergence struct {
+	Index   uint64 // of the instruction, from 0
+	Reason  string
+	Stop    *rv32i.StopReason // of the emulator if it stopped before the instruction
+	Got     rv32i.Commit      // of the emulator, empty if Stop is set
+	Want    rv32i.Commit      // of the reference
+	X       []uint32          // registers of the emulator after the instruction
+	RefX    []uint32          // registers of the reference after the instruction
+	History []rv32i.Commit    // the last instructions before it
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("divergence at instruction #%d (pc 0x%08x): %s", d.Index, d.Want.PC, d.Reason)
+}
+
+// Result of Run
+type Result struct {
+	Instructions uint64           // compared with the reference
+	Stop         rv32i.StopReason // of the emulator
+	Divergence   *Divergence      // nil if the run matched the reference
+}
+
+// errReferenceEnd stops the run at the end of the reference
+var errReferenceEnd = errors.New("end of the reference")
+
+// NewReader returns the reader of the binary commit log of rv32i, or of the
+// spike format otherwise
+func NewReader(r io.Reader) rv32i.CommitReader {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(8); rv32i.IsBinaryCommitLog(head) {
+		return rv32i.NewBinaryCommitReader(br)
+	}
+	return rv32i.NewSpikeCommitReader(br)
+}
+
+// Run runs the emulator until it diverges from ref, ref ends or one of the
+// stop conditions of opts is met. A stop while ref has more instructions is a
+// Divergence too. The registers of the reference are tracked from the ones
+// of the emulator at the start. The error is of reading ref.
+func Run(ctx context.Context, emu *rv32i.Emulator, ref rv32i.CommitReader, opts Options) (Result, error) {
+	history := opts.History
+	if history == 0 {
+		history = DefaultHistory
+	}
+	d := &differ{
+		ref:     ref,
+		opts:    opts,
+		refX:    append([]uint32(nil), emu.Cpu.X...),
+		history: make([]rv32i.Commit, history),
+	}
+
+	prev := emu.Tracer
+	emu.Tracer = rv32i.TracerFunc(func(c *rv32i.Commit) error {
+		if prev != nil {
+			if err := prev.Trace(c); err != nil {
+				return err
+			}
+		}
+		return d.compare(emu, c)
+	})
+	defer func() { emu.Tracer = prev }()
+
+	r := emu.RunWithOptions(ctx, opts.RunOptions)
+	result := Result{Instructions: d.count, Stop: r}
+	var div *Divergence
+	switch {
+	case errors.As(r.Err, &div):
+		result.Divergence = div
+	case errors.Is(r.Err, errReferenceEnd):
+		// the instruction after the end was not compared
+	case d.err != nil:
+		return result, d.err
+	default:
+		// the reference must end where the emulator stopped
+		want, err := d.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		div := d.divergence(emu, fmt.Sprintf("the emulator stopped, but the reference retires it: %v", r), nil, want)
+		div.Stop = &r
+		result.Divergence = div
+	}
+	return result, nil
+}
+
+type differ struct {
+	ref     rv32i.CommitReader
+	opts    Options
+	refX    []uint32
+	count   uint64
+	history []rv32i.Commit // ring buffer of the last instructions
+	err     error          // of reading ref
+}
+
+// compare is the Tracer which compares the commit with the next one of the
+// reference
+func (d *differ) compare(emu *rv32i.Emulator, got *rv32i.Commit) error {
+	want, err := d.next()
+	if errors.Is(err, io.EOF) {
+		return errReferenceEnd
+	}
+	if err != nil {
+		d.err = err
+		return err
+	}
+	if reason := d.diff(got, want); reason != "" {
+		return d.divergence(emu, reason, got, want)
+	}
+
+	h := &d.history[d.count%uint64(len(d.history))]
+	h.PC, h.Instr, h.Priv = got.PC, got.Instr, got.Priv
+	h.Regs = append(h.Regs[:0], got.Regs...)
+	h.Mem = append(h.Mem[:0], got.Mem...)
+	d.count++
+	return nil
+}
+
+// next reads the next instruction of the reference, and updates the
+// registers of the reference with it
+func (d *differ) next() (*rv32i.Commit, error) {
+	want, err := d.ref.Next()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range want.Regs {
+		if r.File == rv32i.RegX && r.Num > 0 && r.Num < 32 {
+			d.refX[r.Num] = uint32(r.Value)
+		}
+	}
+	return want, nil
+}
+
+// divergence returns the Divergence at the current instruction. got is nil
+// if the emulator stopped.
+func (d *differ) divergence(emu *rv32i.Emulator, reason string, got, want *rv32i.Commit) *Divergence {
+	div := &Divergence{
+		Index:  d.count,
+		Reason: reason,
+		Want:   copyCommit(want),
+		X:      append([]uint32(nil), emu.Cpu.X...),
+		RefX:   append([]uint32(nil), d.refX...),
+	}
+	if got != nil {
+		div.Got = copyCommit(got)
+	}
+	n := uint64(len(d.history))
+	start := uint64(0)
+	if d.count > n {
+		start = d.count - n
+	}
+	for i := start; i < d.count; i++ {
+		div.History = append(div.History, d.history[i%n])
+	}
+	return div
+}
+
+// diff returns how got differs from want, or "" if they are the same
+func (d *differ) diff(got, want *rv32i.Commit) string {
+	switch {
+	case got.PC != want.PC:
+		return fmt.Sprintf("pc is 0x%08x, but the reference is 0x%08x", got.PC, want.PC)
+	case got.Instr != want.Instr:
+		return fmt.Sprintf("instruction is 0x%08x, but the reference is 0x%08x", got.Instr, want.Instr)
+	case got.Priv != want.Priv:
+		return fmt.Sprintf("privilege mode is %d, but the reference is %d", got.Priv, want.Priv)
+	}
+
+	gotRegs, wantRegs := d.regs(got), d.regs(want)
+	for _, w := range sortedRegs(wantRegs) {
+		g, ok := gotRegs[w.key]
+		switch {
+		case !ok:
+			return fmt.Sprintf("%s is not written, but the reference writes 0x%x", w.name, w.Value)
+		case g.Value != w.Value:
+			return fmt.Sprintf("%s is 0x%x, but the reference writes 0x%x", w.name, g.Value, w.Value)
+		}
+	}
+	for _, g := range sortedRegs(gotRegs) {
+		if _, ok := wantRegs[g.key]; !ok {
+			return fmt.Sprintf("%s is written with 0x%x, but not by the reference", g.name, g.Value)
+		}
+	}
+
+	gotMem, wantMem := d.mem(got), d.mem(want)
+	for i := 0; i < len(gotMem) || i < len(wantMem); i++ {
+		switch {
+		case i >= len(wantMem):
+			return fmt.Sprintf("%s, but not the reference", memString(gotMem[i]))
+		case i >= len(gotMem):
+			return fmt.Sprintf("the reference %s, but not the emulator", memString(wantMem[i]))
+		case !sameAccess(gotMem[i], wantMem[i]):
+			return fmt.Sprintf("%s, but the reference %s", memString(gotMem[i]), memString(wantMem[i]))
+		}
+	}
+	return ""
+}
+
+type reg struct {
+	rv32i.RegWrite
+	key  uint64
+	name string
+}
+
+// regs returns the register writes to compare by the file and the number
+func (d *differ) regs(c *rv32i.Commit) map[uint64]reg {
+	regs := map[uint64]reg{}
+	for _, r := range c.Regs {
+		var name string
+		switch r.File {
+		case rv32i.RegX:
+			if r.Num == 0 {
+				continue
+			}
+			name = rv32i.RegName(uint8(r.Num))
+		case rv32i.RegF:
+			name = rv32i.FRegName(uint8(r.Num))
+		case rv32i.RegCsr:
+			if !d.opts.CSRs {
+				continue
+			}
+			name = rv32i.CsrName(r.Num)
+		}
+		key := uint64(r.File)<<32 | uint64(r.Num)
+		regs[key] = reg{RegWrite: r, key: key, name: name}
+	}
+	return regs
+}
+
+func sortedRegs(regs map[uint64]reg) []reg {
+	list := make([]reg, 0, len(regs))
+	for _, r := range regs {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].key < list[j].key })
+	return list
+}
+
+// mem returns the memory accesses to compare
+func (d *differ) mem(c *rv32i.Commit) []rv32i.MemAccess {
+	var list []rv32i.MemAccess
+	for _, m := range c.Mem {
+		if m.Write || d.opts.Loads {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// sameAccess compares the sizes and values of stores. Loads are compared by
+// the addresses as spike doesn't log the rest.
+func sameAccess(a, b rv32i.MemAccess) bool {
+	if a.Write != b.Write || a.Addr != b.Addr {
+		return false
+	}
+	return !a.Write || a.Size == b.Size && a.Value == b.Value
+}
+
+func memString(m rv32i.MemAccess) string {
+	if m.Write {
+		return fmt.Sprintf("writes 0x%0*x to 0x%08x", m.Size*2, m.Value, m.Addr)
+	}
+	return fmt.Sprintf("reads 0x%08x", m.Addr)
+}
+
+func copyCommit(c *rv32i.Commit) rv32i.Commit {
+	copied := *c
+	copied.Regs = append([]rv32i.RegWrite(nil), c.Regs...)
+	copied.Mem = append([]rv32i.MemAccess(nil), c.Mem...)
+	return copied
+}
+
+// Report writes the divergence with the last instructions and the registers
+// of both
+func (d *Divergence) Report(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintln(&sb, d.Error())
+	fmt.Fprintf(&sb, "instruction: %s\n", d.Want.Disassembly())
+
+	fmt.Fprintf(&sb, "last %d instructions:\n", len(d.History))
+	log := rv32i.NewSpikeCommitWriter(&sb)
+	for i := range d.History {
+		log.Trace(&d.History[i])
+	}
+	fmt.Fprintln(&sb, "emulator:")
+	if d.Stop != nil {
+		fmt.Fprintf(&sb, "stopped: %v\n", *d.Stop)
+	} else {
+		log.Trace(&d.Got)
+	}
+	fmt.Fprintln(&sb, "reference:")
+	log.Trace(&d.Want)
+
+	fmt.Fprintln(&sb, "registers:   emulator    reference")
+	for i := range d.X {
+		mark := ""
+		if d.X[i] != d.RefX[i] {
+			mark = " *"
+		}
+		fmt.Fprintf(&sb, "  %-4s       0x%08x  0x%08x%s\n", rv32i.RegName(uint8(i)), d.X[i], d.RefX[i], mark)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
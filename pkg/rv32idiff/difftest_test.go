@@ -0,0 +1,184 @@
+package rv32idiff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+func newTestEmulator() *rv32i.Emulator {
+	t0, t1 := rv32i.Regs["t0"], rv32i.Regs["t1"]
+	codes := []uint32{
+		rv32i.GenCode(rv32i.OpAddi, t0, 0, -1),
+		rv32i.GenCode(rv32i.OpSlt, t1, t0, 0),
+		rv32i.GenCode(rv32i.OpSw, t1, 0x100, 0),
+		rv32i.GenCode(rv32i.OpLw, t0, 0x100, 0),
+		rv32i.GenCode(rv32i.OpJal, 0, 0, 0),
+	}
+	emu := rv32i.NewEmulator()
+	for idx, code := range codes {
+		emu.WriteU32(uint32(idx*4), code)
+	}
+	return emu
+}
+
+// reference returns the commit log of 4 instructions in the spike format,
+// or in the binary format
+func reference(t *testing.T, binary bool) []byte {
+	emu := newTestEmulator()
+	out := &bytes.Buffer{}
+	if binary {
+		emu.Tracer = rv32i.NewBinaryCommitWriter(out)
+	} else {
+		emu.Tracer = rv32i.NewSpikeCommitWriter(out)
+	}
+	if r := emu.RunWithOptions(context.Background(), rv32i.RunOptions{MaxInstructions: 4}); r.Kind != rv32i.StopMaxInstructions {
+		t.Fatalf("reference must run 4 instructions, but was %v", r)
+	}
+	return out.Bytes()
+}
+
+func Test_Match(t *testing.T) {
+	for _, binary := range []bool{false, true} {
+		emu := newTestEmulator()
+		r, err := Run(context.Background(), emu, NewReader(bytes.NewReader(reference(t, binary))), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Divergence != nil || r.Instructions != 4 {
+			t.Errorf("binary %v: run must match 4 instructions, but was %d, %v", binary, r.Instructions, r.Divergence)
+		}
+		if emu.Tracer != nil {
+			t.Error("Tracer must be restored")
+		}
+	}
+}
+
+func Test_Divergence(t *testing.T) {
+	type TestData struct {
+		Old    string
+		New    string
+		Opts   Options
+		Index  uint64
+		Reason string
+	}
+	for _, td := range []TestData{
+		{"0x00000004 (0x", "0x00000008 (0x", Options{}, 1, "pc is 0x00000004, but the reference is 0x00000008"},
+		{"x6  0x00000001", "x6  0x00000000", Options{}, 1, "t1 is 0x1, but the reference writes 0x0"},
+		{"x6  0x00000001", "x7  0x00000001", Options{}, 1, "t2 is not written, but the reference writes 0x1"},
+		{"mem 0x00000100 0x00000001", "mem 0x00000100 0x0001", Options{}, 2,
+			"writes 0x00000001 to 0x00000100, but the reference writes 0x0001 to 0x00000100"},
+		{"mem 0x00000100\n", "mem 0x00000104\n", Options{}, 4, ""},
+		{"mem 0x00000100\n", "mem 0x00000104\n", Options{Loads: true}, 3,
+			"reads 0x00000100, but the reference reads 0x00000104"},
+		{"x5  0x00000001 mem", "mem", Options{History: 2}, 3, "t0 is written with 0x1, but not by the reference"},
+	} {
+		ref := strings.Replace(string(reference(t, false)), td.Old, td.New, 1)
+		r, err := Run(context.Background(), newTestEmulator(), NewReader(strings.NewReader(ref)), td.Opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if td.Reason == "" {
+			if r.Divergence != nil || r.Instructions != td.Index {
+				t.Errorf("%s must match %d instructions, but was %d, %v", td.New, td.Index, r.Instructions, r.Divergence)
+			}
+			continue
+		}
+		d := r.Divergence
+		if d == nil || d.Index != td.Index || d.Reason != td.Reason {
+			t.Errorf("%s must diverge at #%d with %q, but was %v", td.New, td.Index, td.Reason, d)
+			continue
+		}
+		history := int(td.Index)
+		if td.Opts.History > 0 && history > td.Opts.History {
+			history = td.Opts.History
+		}
+		if len(d.History) != history || history > 0 && d.History[history-1].PC != d.Got.PC-4 {
+			t.Errorf("%s must have %d instructions before it, but was %+v", td.New, history, d.History)
+		}
+	}
+}
+
+func Test_Stop(t *testing.T) {
+	type TestData struct {
+		Illegal uint32 // address of an illegal instruction if not 0
+		Max     uint64
+		Kind    rv32i.StopKind
+		Index   uint64
+	}
+	for _, td := range []TestData{
+		{4, 0, rv32i.StopTrap, 1},
+		{0, 2, rv32i.StopMaxInstructions, 2},
+		// the reference ends at the stop
+		{0, 4, rv32i.StopMaxInstructions, 4},
+	} {
+		emu := newTestEmulator()
+		if td.Illegal != 0 {
+			emu.WriteU32(td.Illegal, 0)
+		}
+		opts := Options{RunOptions: rv32i.RunOptions{MaxInstructions: td.Max}}
+		r, err := Run(context.Background(), emu, NewReader(bytes.NewReader(reference(t, false))), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Stop.Kind != td.Kind || r.Instructions != td.Index {
+			t.Errorf("%+v: run must stop with %v after %d instructions, but was %v, %d", td, td.Kind, td.Index, r.Stop, r.Instructions)
+		}
+		d := r.Divergence
+		if td.Index == 4 {
+			if d != nil {
+				t.Errorf("%+v: run must match, but was %v", td, d)
+			}
+			continue
+		}
+		if d == nil || d.Index != td.Index || d.Stop == nil || d.Stop.Kind != td.Kind || uint64(d.Want.PC) != td.Index*4 {
+			t.Errorf("%+v: run must diverge at #%d as the emulator stopped, but was %v", td, td.Index, d)
+			continue
+		}
+		out := &bytes.Buffer{}
+		d.Report(out)
+		if s := fmt.Sprintf("emulator:\nstopped: %v\nreference:\n", r.Stop); !strings.Contains(out.String(), s) {
+			t.Errorf("report must have %q, but was\n%s", s, out.String())
+		}
+	}
+}
+
+func Test_Report(t *testing.T) {
+	ref := strings.Replace(string(reference(t, false)), "x6  0x00000001", "x6  0x00000000", 1)
+	r, err := Run(context.Background(), newTestEmulator(), NewReader(strings.NewReader(ref)), Options{})
+	if err != nil || r.Divergence == nil {
+		t.Fatalf("run must diverge, but was %v, %v", r.Divergence, err)
+	}
+	if r.Stop.Kind != rv32i.StopError {
+		t.Errorf("run must stop with the divergence, but was %v", r.Stop)
+	}
+
+	out := &bytes.Buffer{}
+	if err := r.Divergence.Report(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"divergence at instruction #1 (pc 0x00000004): t1 is 0x1, but the reference writes 0x0\n",
+		"instruction: Slt t1, t0, zero\n",
+		"last 1 instructions:\ncore   0: 3 0x00000000 (0xfff00293) x5  0xffffffff\n",
+		"emulator:\ncore   0: 3 0x00000004 (0x0002a333) x6  0x00000001\n",
+		"reference:\ncore   0: 3 0x00000004 (0x0002a333) x6  0x00000000\n",
+		"  t0         0xffffffff  0xffffffff\n",
+		"  t1         0x00000001  0x00000000 *\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("report must have %q, but was\n%s", s, out.String())
+		}
+	}
+}
+
+func Test_ReadError(t *testing.T) {
+	ref := "core   0: 3 0x00000000 (0xfff00293) x5\n"
+	if _, err := Run(context.Background(), newTestEmulator(), NewReader(strings.NewReader(ref)), Options{}); err == nil {
+		t.Error("a broken reference must be an error")
+	}
+}
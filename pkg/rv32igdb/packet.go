@@ -0,0 +1,142 @@
+package rv32igdb
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// interrupt is sent by GDB out of packets to stop the target (Ctrl-C)
+const interrupt = 0x03
+
+// packet is a packet from GDB, or interrupt if ctrlC is true
+type packet struct {
+	data  string
+	ctrlC bool
+}
+
+// readPackets sends the packets read from r to ch until r is closed or
+// done is closed. A packet with a bad checksum is nacked with '-' so that
+// GDB resends it.
+func readPackets(r io.Reader, w io.Writer, ch chan<- packet, done <-chan struct{}) {
+	defer close(ch)
+	br := bufio.NewReader(r)
+	send := func(p packet) bool {
+		select {
+		case ch <- p:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return
+		}
+		switch b {
+		case interrupt:
+			if !send(packet{ctrlC: true}) {
+				return
+			}
+		case '$':
+			data, err := br.ReadString('#')
+			if err != nil {
+				return
+			}
+			var cs [2]byte
+			if _, err = io.ReadFull(br, cs[:]); err != nil {
+				return
+			}
+			data = data[:len(data)-1]
+			if sum, err := strconv.ParseUint(string(cs[:]), 16, 8); err != nil || uint8(sum) != checksum(data) {
+				w.Write([]byte{'-'})
+				continue
+			}
+			if !send(packet{data: unescape(data)}) {
+				return
+			}
+		default:
+			// '+' and '-' of our packets, TCP doesn't lose them
+		}
+	}
+}
+
+func checksum(data string) uint8 {
+	var sum uint8
+	for i := 0; i < len(data); i++ {
+		sum += data[i]
+	}
+	return sum
+}
+
+// escape escapes '#', '$', '}' and '*' of binary data
+func escape(data string) string {
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '#', '$', '}', '*':
+			sb.WriteByte('}')
+			sb.WriteByte(c ^ 0x20)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func unescape(data string) string {
+	if !strings.Contains(data, "}") {
+		return data
+	}
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		if data[i] == '}' && i+1 < len(data) {
+			i++
+			sb.WriteByte(data[i] ^ 0x20)
+			continue
+		}
+		sb.WriteByte(data[i])
+	}
+	return sb.String()
+}
+
+// encodePacket frames data as $data#checksum
+func encodePacket(data string) []byte {
+	data = escape(data)
+	return []byte(fmt.Sprintf("$%s#%02x", data, checksum(data)))
+}
+
+// hexU32 encodes v in the target byte order, which is little endian
+func hexU32(v uint32) string {
+	return hex.EncodeToString([]byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)})
+}
+
+func hexU64(v uint64) string {
+	return hexU32(uint32(v)) + hexU32(uint32(v>>32))
+}
+
+// parseHexLE decodes a little endian value of up to 8 bytes
+func parseHexLE(s string) (uint64, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > 8 {
+		return 0, fmt.Errorf("%s is too long", s)
+	}
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, nil
+}
+
+// parseHex parses a big endian hex number like an address or a length
+func parseHex(s string) (uint32, error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	return uint32(v), err
+}
@@ -0,0 +1,496 @@
+// Package rv32igdb is a GDB remote serial protocol stub for rv32i.Emulator.
+//
+// m and M packets read and write physical memory through the Emulator,
+// while breakpoints and watchpoints are the ones of the Emulator and compare
+// virtual addresses, PC and the data addresses of the guest. Software and
+// hardware breakpoints are the same and don't modify the guest memory.
+package rv32igdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+// Signals in stop replies
+const (
+	sigINT  = 2
+	sigILL  = 4
+	sigTRAP = 5
+	sigSEGV = 11
+)
+
+// breakpoint and watchpoint types of Z/z packets
+const (
+	zSoftware = 0
+	zHardware = 1
+	zWrite    = 2
+	zRead     = 3
+	zAccess   = 4
+)
+
+var watchKinds = map[int]rv32i.WatchKind{
+	zSoftware: rv32i.WatchNone,
+	zHardware: rv32i.WatchNone,
+	zWrite:    rv32i.WatchWrite,
+	zRead:     rv32i.WatchRead,
+	zAccess:   rv32i.WatchAccess,
+}
+
+// zpoint is a breakpoint or a watchpoint of Z packets
+type zpoint struct {
+	typ  int
+	addr uint32
+	kind uint32
+}
+
+// Server serves a GDB session for the Emulator
+type Server struct {
+	Emu *rv32i.Emulator
+
+	// breakpoints and watchpoints added to the Emulator by Z packets
+	breakpoints map[zpoint]rv32i.BreakpointID
+	types       map[rv32i.BreakpointID]int
+
+	conn     io.Writer
+	packets  chan packet
+	noAck    bool
+	swbreak  bool // GDB knows swbreak and hwbreak stop reasons
+	exited   bool // the guest exited and can't run
+	lastStop string
+}
+
+func NewServer(emu *rv32i.Emulator) *Server {
+	return &Server{
+		Emu:         emu,
+		breakpoints: map[zpoint]rv32i.BreakpointID{},
+		types:       map[rv32i.BreakpointID]int{},
+	}
+}
+
+// ListenAndServe waits for GDB on the TCP address and serves the session.
+// It returns when GDB detaches, kills the target or disconnects.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	log.Infof("gdb: waiting for a connection on %s", l.Addr())
+	conn, err := l.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	log.Infof("gdb: connected from %s", conn.RemoteAddr())
+
+	return s.Serve(conn)
+}
+
+// Serve serves a GDB session on conn until it ends
+func (s *Server) Serve(conn io.ReadWriter) error {
+	s.conn = conn
+	s.packets = make(chan packet)
+	s.noAck = false
+	s.exited = false
+	s.lastStop = fmt.Sprintf("S%02x", sigTRAP)
+	done := make(chan struct{})
+	defer close(done)
+	go readPackets(conn, conn, s.packets, done)
+
+	defer s.clearBreakpoints()
+
+	for p := range s.packets {
+		if p.ctrlC {
+			// the target is already stopped
+			continue
+		}
+		if !s.noAck {
+			if _, err := conn.Write([]byte{'+'}); err != nil {
+				return err
+			}
+		}
+		log.Tracef("gdb: <- %s", p.data)
+		reply, end := s.handle(p.data)
+		if p.data == "k" {
+			// no reply to kill
+			return nil
+		}
+		if err := s.send(reply); err != nil {
+			return err
+		}
+		if end {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Server) send(data string) error {
+	log.Tracef("gdb: -> %s", data)
+	_, err := s.conn.Write(encodePacket(data))
+	return err
+}
+
+// handle returns the reply to the packet, and true if the session ends
+func (s *Server) handle(data string) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	cpu := s.Emu.Cpu
+	args := data[1:]
+
+	switch data[0] {
+	case '?':
+		return s.lastStop, false
+	case 'g':
+		var sb strings.Builder
+		for n := 0; n < numGPRegs; n++ {
+			v, _ := readRegister(cpu, n)
+			sb.WriteString(v)
+		}
+		return sb.String(), false
+	case 'G':
+		if len(args) < numGPRegs*8 {
+			return "E01", false
+		}
+		for n := 0; n < numGPRegs; n++ {
+			v, err := parseHexLE(args[n*8 : n*8+8])
+			if err != nil {
+				return "E01", false
+			}
+			writeRegister(cpu, n, v)
+		}
+		return "OK", false
+	case 'p':
+		n, err := parseHex(args)
+		if err != nil {
+			return "E01", false
+		}
+		if v, ok := readRegister(cpu, int(n)); ok {
+			return v, false
+		}
+		// unavailable
+		return "xxxxxxxx", false
+	case 'P':
+		reg, val, ok := strings.Cut(args, "=")
+		n, err := parseHex(reg)
+		if !ok || err != nil {
+			return "E01", false
+		}
+		v, err := parseHexLE(val)
+		if err != nil || !writeRegister(cpu, int(n), v) {
+			return "E01", false
+		}
+		return "OK", false
+	case 'm':
+		addr, size, err := parseAddrLen(args)
+		if err != nil {
+			return "E01", false
+		}
+		b, err := s.Emu.ReadBytes(addr, size)
+		if err != nil {
+			return "E14", false
+		}
+		return fmt.Sprintf("%x", b), false
+	case 'M':
+		al, hexData, ok := strings.Cut(args, ":")
+		addr, size, err := parseAddrLen(al)
+		if !ok || err != nil || uint32(len(hexData)) != size*2 {
+			return "E01", false
+		}
+		return s.writeMemory(addr, hexData), false
+	case 'X':
+		// binary data is not supported, GDB uses 'M' instead
+		return "", false
+	case 'c':
+		if err := s.resume(args); err != nil {
+			return "E01", false
+		}
+		return s.cont(), false
+	case 's':
+		if err := s.resume(args); err != nil {
+			return "E01", false
+		}
+		return s.step(), false
+	case 'Z', 'z':
+		return s.breakpoint(data[0] == 'Z', args), false
+	case 'H', 'T':
+		// a single thread
+		return "OK", false
+	case 'k':
+		return "", true
+	case 'D':
+		return "OK", true
+	case 'q':
+		return s.query(args), false
+	case 'Q':
+		if args == "StartNoAckMode" {
+			s.noAck = true
+			return "OK", false
+		}
+		return "", false
+	case 'v':
+		return s.vPacket(args), false
+	}
+	return "", false
+}
+
+func (s *Server) query(args string) string {
+	switch {
+	case strings.HasPrefix(args, "Supported"):
+		s.swbreak = strings.Contains(args, "swbreak+")
+		return "PacketSize=4000;qXfer:features:read+;QStartNoAckMode+;swbreak+;hwbreak+;vContSupported+"
+	case strings.HasPrefix(args, "Xfer:features:read:"):
+		// qXfer:features:read:annex:offset,length
+		parts := strings.Split(args, ":")
+		if len(parts) != 5 {
+			return "E01"
+		}
+		if parts[3] != "target.xml" {
+			return "E00"
+		}
+		offset, length, err := parseAddrLen(parts[4])
+		if err != nil {
+			return "E01"
+		}
+		xml := targetXML(s.Emu.Cpu)
+		if offset >= uint32(len(xml)) {
+			return "l"
+		}
+		if end := offset + length; end < uint32(len(xml)) {
+			return "m" + xml[offset:end]
+		}
+		return "l" + xml[offset:]
+	case args == "Attached":
+		return "1"
+	case args == "C":
+		return "QC1"
+	case args == "fThreadInfo":
+		return "m1"
+	case args == "sThreadInfo":
+		return "l"
+	}
+	return ""
+}
+
+func (s *Server) vPacket(args string) string {
+	switch {
+	case args == "Cont?":
+		return "vCont;c;C;s;S"
+	case strings.HasPrefix(args, "Cont;"):
+		// the first action is for the only thread
+		action, _, _ := strings.Cut(args[len("Cont;"):], ";")
+		action, _, _ = strings.Cut(action, ":")
+		if len(action) == 0 {
+			return "E01"
+		}
+		switch action[0] {
+		case 'c', 'C':
+			return s.cont()
+		case 's', 'S':
+			return s.step()
+		}
+		return "E01"
+	}
+	return ""
+}
+
+// resume sets PC to the address of 'c' and 's' if it has one
+func (s *Server) resume(args string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	addr, err := parseHex(args)
+	if err != nil {
+		return err
+	}
+	s.Emu.Cpu.PC = addr
+	return nil
+}
+
+func (s *Server) writeMemory(addr uint32, hexData string) string {
+	b := make([]byte, len(hexData)/2)
+	for i := range b {
+		v, err := strconv.ParseUint(hexData[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return "E01"
+		}
+		b[i] = byte(v)
+	}
+	if err := s.Emu.WriteBytes(addr, b); err != nil {
+		return "E14"
+	}
+	return "OK"
+}
+
+func (s *Server) breakpoint(insert bool, args string) string {
+	// type,addr,kind
+	parts := strings.Split(args, ",")
+	if len(parts) < 3 {
+		return "E01"
+	}
+	typ, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "E01"
+	}
+	addr, err := parseHex(parts[1])
+	if err != nil {
+		return "E01"
+	}
+	kind, err := parseHex(strings.SplitN(parts[2], ";", 2)[0])
+	if err != nil {
+		return "E01"
+	}
+
+	watch, ok := watchKinds[typ]
+	if !ok {
+		return ""
+	}
+	z := zpoint{typ: typ, addr: addr, kind: kind}
+	if !insert {
+		if id, ok := s.breakpoints[z]; ok {
+			s.Emu.RemoveBreakpoint(id)
+			delete(s.breakpoints, z)
+			delete(s.types, id)
+		}
+		return "OK"
+	}
+	if _, ok := s.breakpoints[z]; ok {
+		return "OK"
+	}
+
+	var id rv32i.BreakpointID
+	if watch == rv32i.WatchNone {
+		id = s.Emu.AddBreakpoint(addr, nil)
+	} else {
+		id, err = s.Emu.AddWatchpoint(watch, addr, kind)
+		if err != nil {
+			return "E01"
+		}
+	}
+	s.breakpoints[z] = id
+	s.types[id] = typ
+	return "OK"
+}
+
+// clearBreakpoints removes the breakpoints and watchpoints of the session
+// from the Emulator
+func (s *Server) clearBreakpoints() {
+	for z, id := range s.breakpoints {
+		s.Emu.RemoveBreakpoint(id)
+		delete(s.breakpoints, z)
+		delete(s.types, id)
+	}
+}
+
+// step runs an instruction and returns the stop reply
+func (s *Server) step() string {
+	return s.run(context.Background(), 1)
+}
+
+// cont runs until a breakpoint, a watchpoint, an error or Ctrl-C, and
+// returns the stop reply
+func (s *Server) cont() string {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case p, ok := <-s.packets:
+				if !ok || p.ctrlC {
+					cancel()
+					return
+				}
+				log.Warnf("gdb: %s is ignored while running", p.data)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	return s.run(ctx, 0)
+}
+
+// run runs up to max instructions, unlimited if 0, and returns the stop
+// reply. Breakpoints are not checked for the first instruction, so that the
+// guest can leave the one it stopped at.
+func (s *Server) run(ctx context.Context, max uint64) string {
+	if s.exited {
+		return s.lastStop
+	}
+	r := s.Emu.RunWithOptions(ctx, rv32i.RunOptions{MaxInstructions: max})
+
+	var trap *rv32i.Trap
+	switch r.Kind {
+	case rv32i.StopMaxInstructions:
+		return s.stop(fmt.Sprintf("S%02x", sigTRAP))
+	case rv32i.StopBreakpoint:
+		reason := ""
+		if s.swbreak {
+			reason = "swbreak:;"
+			if s.types[r.Breakpoint.ID] == zHardware {
+				reason = "hwbreak:;"
+			}
+		}
+		return s.stop(fmt.Sprintf("T%02x%s", sigTRAP, reason))
+	case rv32i.StopWatchpoint:
+		kind := map[rv32i.WatchKind]string{rv32i.WatchWrite: "watch", rv32i.WatchRead: "rwatch", rv32i.WatchAccess: "awatch"}[r.Breakpoint.Kind]
+		return s.stop(fmt.Sprintf("T%02x%s:%x;", sigTRAP, kind, r.WatchAddr))
+	case rv32i.StopExit:
+		s.exited = true
+		return s.stop(fmt.Sprintf("W%02x", uint8(r.ExitCode)))
+	case rv32i.StopCanceled:
+		return s.stop(fmt.Sprintf("S%02x", sigINT))
+	case rv32i.StopTrap:
+		if errors.As(r.Err, &trap) {
+			return s.stop(fmt.Sprintf("S%02x", trapSignal(trap.Cause)))
+		}
+	}
+	log.Errorf("gdb: %v", r)
+	return s.stop(fmt.Sprintf("S%02x", sigSEGV))
+}
+
+// stop remembers the stop reply for '?'
+func (s *Server) stop(reply string) string {
+	s.lastStop = reply
+	return reply
+}
+
+// trapSignal returns the signal of the trap no handler took
+func trapSignal(cause rv32i.TrapCause) int {
+	switch cause {
+	case rv32i.CauseIllegalInstruction:
+		return sigILL
+	case rv32i.CauseBreakpoint, rv32i.CauseEnvironmentCallFromU, rv32i.CauseEnvironmentCallFromS, rv32i.CauseEnvironmentCallFromM:
+		return sigTRAP
+	default:
+		return sigSEGV
+	}
+}
+
+// parseAddrLen parses "addr,length"
+func parseAddrLen(s string) (uint32, uint32, error) {
+	a, l, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("%s has no length", s)
+	}
+	addr, err := parseHex(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err := parseHex(l)
+	return addr, length, err
+}
@@ -0,0 +1,254 @@
+package rv32igdb
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newTestClient serves the emulator on a pipe
+func newTestClient(t *testing.T, emu *rv32i.Emulator) (*testClient, chan error) {
+	client, server := net.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- NewServer(emu).Serve(server)
+		server.Close()
+	}()
+	t.Cleanup(func() { client.Close() })
+	return &testClient{t: t, conn: client, r: bufio.NewReader(client)}, errCh
+}
+
+// request sends a packet and returns the reply
+func (c *testClient) request(data string) string {
+	c.t.Helper()
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write(encodePacket(data)); err != nil {
+		c.t.Fatal(err)
+	}
+	if b, err := c.r.ReadByte(); err != nil || b != '+' {
+		c.t.Fatalf("%s must be acked, but got %q, %v", data, b, err)
+	}
+	return c.reply()
+}
+
+func (c *testClient) reply() string {
+	c.t.Helper()
+	if _, err := c.r.ReadString('$'); err != nil {
+		c.t.Fatal(err)
+	}
+	s, err := c.r.ReadString('#')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	cs := make([]byte, 2)
+	if _, err := c.r.Read(cs); err != nil {
+		c.t.Fatal(err)
+	}
+	return unescape(s[:len(s)-1])
+}
+
+func newTestEmulator(codes ...uint32) *rv32i.Emulator {
+	emu := rv32i.NewEmulator()
+	for idx, code := range codes {
+		emu.WriteU32(uint32(idx*4), code)
+	}
+	return emu
+}
+
+func Test_Registers(t *testing.T) {
+	emu := newTestEmulator()
+	emu.Cpu.X[rv32i.Regs["a0"]] = 0x12345678
+	emu.Cpu.PC = 0x80000000
+	c, _ := newTestClient(t, emu)
+
+	g := c.request("g")
+	if len(g) != numGPRegs*8 || g[10*8:11*8] != "78563412" || g[32*8:] != "00000080" {
+		t.Errorf("g must have a0 and pc, but was %s", g)
+	}
+	if r := c.request("p20"); r != "00000080" {
+		t.Errorf("pc must be 00000080, but was %s", r)
+	}
+	if r := c.request("Pb=efbeadde"); r != "OK" || emu.Cpu.X[11] != 0xdeadbeef {
+		t.Errorf("a1 must be written, but was %s, 0x%08x", r, emu.Cpu.X[11])
+	}
+	// mscratch
+	if r := c.request("P381=01000000"); r != "OK" || emu.Cpu.Csr.Mscratch != 1 {
+		t.Errorf("mscratch must be written, but was %s, %d", r, emu.Cpu.Csr.Mscratch)
+	}
+	if r := c.request("p381"); r != "01000000" {
+		t.Errorf("mscratch must be 01000000, but was %s", r)
+	}
+	// mvendorid is read-only
+	if r := c.request("Pf52=01000000"); r != "E01" {
+		t.Errorf("mvendorid must not be written, but was %s", r)
+	}
+	// f1 is 64-bit with D
+	emu.Cpu.F[1] = 0x3ff00000_00000000
+	if r := c.request("p22"); r != "000000000000f03f" {
+		t.Errorf("f1 must be 1.0, but was %s", r)
+	}
+	if r := c.request("p1041"); r != "03000000" {
+		t.Errorf("priv must be M, but was %s", r)
+	}
+}
+
+func Test_Memory(t *testing.T) {
+	emu := newTestEmulator(0x00000513)
+	c, _ := newTestClient(t, emu)
+
+	if r := c.request("m0,4"); r != "13050000" {
+		t.Errorf("m must read the code, but was %s", r)
+	}
+	if r := c.request("M100,3:aabbcc"); r != "OK" {
+		t.Errorf("M must be OK, but was %s", r)
+	}
+	if v, _ := emu.ReadU32(0x100); v != 0x00ccbbaa {
+		t.Errorf("memory must be written, but was 0x%08x", v)
+	}
+	if r := c.request("m100,4"); r != "aabbcc00" {
+		t.Errorf("m must read the written data, but was %s", r)
+	}
+}
+
+func Test_TargetXML(t *testing.T) {
+	c, _ := newTestClient(t, newTestEmulator())
+
+	if r := c.request("qSupported:multiprocess+;swbreak+;hwbreak+"); !strings.Contains(r, "qXfer:features:read+") {
+		t.Errorf("qXfer must be supported, but was %s", r)
+	}
+	xml := ""
+	for {
+		r := c.request(fmt.Sprintf("qXfer:features:read:target.xml:%x,100", len(xml)))
+		xml += r[1:]
+		if r[0] == 'l' {
+			break
+		}
+	}
+	for _, s := range []string{"riscv:rv32", "org.gnu.gdb.riscv.cpu", "org.gnu.gdb.riscv.fpu", `name="mstatus"`, `name="priv"`} {
+		if !strings.Contains(xml, s) {
+			t.Errorf("target.xml must have %s", s)
+		}
+	}
+}
+
+func Test_BreakpointStep(t *testing.T) {
+	nop := rv32i.GenCode(rv32i.OpAddi, 0, 0, 0)
+	emu := newTestEmulator(
+		rv32i.GenCode(rv32i.OpAddi, 5, 0, 1),
+		rv32i.GenCode(rv32i.OpAddi, 5, 5, 1),
+		nop,
+		rv32i.GenCode(rv32i.OpJal, 0, 0, 0),
+	)
+	c, _ := newTestClient(t, emu)
+	c.request("qSupported:swbreak+;hwbreak+")
+
+	if r := c.request("s"); r != "S05" || emu.Cpu.PC != 4 {
+		t.Errorf("s must stop at 4, but was %s at 0x%x", r, emu.Cpu.PC)
+	}
+	if r := c.request("Z0,8,4"); r != "OK" {
+		t.Errorf("Z0 must be OK, but was %s", r)
+	}
+	if r := c.request("c"); r != "T05swbreak:;" || emu.Cpu.PC != 8 || emu.Cpu.X[5] != 2 {
+		t.Errorf("c must stop at 8, but was %s at 0x%x", r, emu.Cpu.PC)
+	}
+	if r := c.request("?"); r != "T05swbreak:;" {
+		t.Errorf("? must be the last stop, but was %s", r)
+	}
+	c.request("z0,8,4")
+	if r := c.request("Z1,c,4"); r != "OK" {
+		t.Errorf("Z1 must be OK, but was %s", r)
+	}
+	if r := c.request("vCont;c"); r != "T05hwbreak:;" || emu.Cpu.PC != 0xc {
+		t.Errorf("vCont;c must stop at c, but was %s at 0x%x", r, emu.Cpu.PC)
+	}
+	// continuing from a breakpoint leaves it, and comes back to it
+	if r := c.request("c"); r != "T05hwbreak:;" || emu.Cpu.PC != 0xc {
+		t.Errorf("c must stop at c again, but was %s at 0x%x", r, emu.Cpu.PC)
+	}
+}
+
+func Test_Watchpoint(t *testing.T) {
+	type TestData struct {
+		Z    string
+		Stop string
+		PC   uint32
+	}
+	for _, td := range []TestData{
+		{"Z2,102,2", "T05watch:100;", 0x8},
+		{"Z3,100,4", "T05rwatch:100;", 0xc},
+		{"Z4,100,1", "T05awatch:100;", 0x8},
+	} {
+		emu := newTestEmulator(
+			rv32i.GenCode(rv32i.OpAddi, 5, 0, 1),
+			rv32i.GenCode(rv32i.OpSw, 5, 0x100, 0),
+			rv32i.GenCode(rv32i.OpLw, 6, 0x100, 0),
+			rv32i.GenCode(rv32i.OpJal, 0, 0, 0),
+		)
+		c, _ := newTestClient(t, emu)
+		if r := c.request(td.Z); r != "OK" {
+			t.Errorf("%s must be OK, but was %s", td.Z, r)
+		}
+		if r := c.request("c"); r != td.Stop || emu.Cpu.PC != td.PC {
+			t.Errorf("%s: c must stop with %s at 0x%x, but was %s at 0x%x", td.Z, td.Stop, td.PC, r, emu.Cpu.PC)
+		}
+	}
+}
+
+func Test_Interrupt(t *testing.T) {
+	emu := newTestEmulator(rv32i.GenCode(rv32i.OpJal, 0, 0, 0))
+	c, _ := newTestClient(t, emu)
+
+	c.conn.Write(encodePacket("c"))
+	if b, err := c.r.ReadByte(); err != nil || b != '+' {
+		t.Fatalf("c must be acked, but got %q, %v", b, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	c.conn.Write([]byte{interrupt})
+	if r := c.reply(); r != "S02" {
+		t.Errorf("Ctrl-C must stop with SIGINT, but was %s", r)
+	}
+}
+
+func Test_ExitDetach(t *testing.T) {
+	emu := newTestEmulator(
+		rv32i.GenCode(rv32i.OpAddi, rv32i.Regs["a0"], 0, 3),
+		rv32i.GenCode(rv32i.OpAddi, rv32i.Regs["a7"], 0, int(rv32i.SysExit)),
+		0x00000073, // ecall
+	)
+	emu.Syscalls = rv32i.NewDefaultSyscalls(rv32i.SyscallConfig{})
+	c, errCh := newTestClient(t, emu)
+
+	// a breakpoint of the emulator is kept after the session
+	emu.AddBreakpoint(0x100, nil)
+	if r := c.request("Z2,100,4"); r != "OK" {
+		t.Errorf("Z2 must be OK, but was %s", r)
+	}
+	if r := c.request("QStartNoAckMode"); r != "OK" {
+		t.Errorf("QStartNoAckMode must be OK, but was %s", r)
+	}
+	c.conn.Write(encodePacket("c"))
+	if r := c.reply(); r != "W03" {
+		t.Errorf("c must exit with 3, but was %s", r)
+	}
+	c.conn.Write(encodePacket("D"))
+	if r := c.reply(); r != "OK" {
+		t.Errorf("D must be OK, but was %s", r)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("Serve must end without error, but was %v", err)
+	}
+	if bps := emu.Breakpoints(); len(bps) != 1 || bps[0].PC != 0x100 {
+		t.Errorf("breakpoints of the session must be removed, but were %v", bps)
+	}
+}
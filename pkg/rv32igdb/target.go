@@ -0,0 +1,186 @@
+package rv32igdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sokoide/rv32i-go/pkg/rv32i"
+)
+
+// GDB register numbers of RISC-V
+const (
+	regPC     = 32
+	regF0     = 33
+	regCsr0   = 65 // regCsr0 + the CSR address
+	regPriv   = regCsr0 + 4096
+	numGPRegs = 33 // x0-x31 and pc, which are in the 'g' packet
+	misaF     = uint32(1 << ('F' - 'A'))
+	misaD     = uint32(1 << ('D' - 'A'))
+)
+
+// flen returns the width of the floating point registers, or 0 without F
+func flen(cpu *rv32i.Cpu) int {
+	switch {
+	case cpu.Csr.Misa&misaD != 0:
+		return 64
+	case cpu.Csr.Misa&misaF != 0:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// targetXML is the target description of the CPU, which has the FPU only
+// if misa has F or D
+func targetXML(cpu *rv32i.Cpu) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<?xml version="1.0"?>
+<!DOCTYPE target SYSTEM "gdb-target.dtd">
+<target version="1.0">
+<architecture>riscv:rv32</architecture>
+<feature name="org.gnu.gdb.riscv.cpu">
+`)
+	for i := 0; i < 32; i++ {
+		typ := "int"
+		switch i {
+		case 1:
+			typ = "code_ptr"
+		case 2, 8:
+			typ = "data_ptr"
+		}
+		fmt.Fprintf(&sb, "<reg name=\"%s\" bitsize=\"32\" type=\"%s\" regnum=\"%d\"/>\n", gdbRegName(i), typ, i)
+	}
+	fmt.Fprintf(&sb, "<reg name=\"pc\" bitsize=\"32\" type=\"code_ptr\" regnum=\"%d\"/>\n", regPC)
+	sb.WriteString("</feature>\n")
+
+	if n := flen(cpu); n > 0 {
+		typ := "ieee_single"
+		if n == 64 {
+			typ = "ieee_double"
+		}
+		sb.WriteString("<feature name=\"org.gnu.gdb.riscv.fpu\">\n")
+		for i := 0; i < 32; i++ {
+			fmt.Fprintf(&sb, "<reg name=\"%s\" bitsize=\"%d\" type=\"%s\" regnum=\"%d\"/>\n",
+				rv32i.FRegName(uint8(i)), n, typ, regF0+i)
+		}
+		for _, addr := range []uint32{rv32i.CsrFflags, rv32i.CsrFrm, rv32i.CsrFcsr} {
+			fmt.Fprintf(&sb, "<reg name=\"%s\" bitsize=\"32\" type=\"int\" regnum=\"%d\" group=\"float\"/>\n",
+				rv32i.CsrName(addr), regCsr0+addr)
+		}
+		sb.WriteString("</feature>\n")
+	}
+
+	sb.WriteString("<feature name=\"org.gnu.gdb.riscv.csr\">\n")
+	for _, addr := range rv32i.CsrAddrs() {
+		switch addr {
+		case rv32i.CsrFflags, rv32i.CsrFrm, rv32i.CsrFcsr:
+			continue
+		}
+		fmt.Fprintf(&sb, "<reg name=\"%s\" bitsize=\"32\" type=\"int\" regnum=\"%d\" group=\"csr\"/>\n",
+			rv32i.CsrName(addr), regCsr0+addr)
+	}
+	sb.WriteString("</feature>\n")
+
+	fmt.Fprintf(&sb, `<feature name="org.gnu.gdb.riscv.virtual">
+<reg name="priv" bitsize="32" type="int" regnum="%d" group="general"/>
+</feature>
+</target>
+`, regPriv)
+	return sb.String()
+}
+
+// gdbRegName returns the ABI name GDB uses. It's fp for s0.
+func gdbRegName(i int) string {
+	if i == 0 {
+		return "zero"
+	}
+	if i == 8 {
+		return "fp"
+	}
+	return rv32i.RegName(uint8(i))
+}
+
+// readRegister returns the register n in hex, or false if it doesn't exist
+func readRegister(cpu *rv32i.Cpu, n int) (string, bool) {
+	switch {
+	case n < 32:
+		return hexU32(cpu.X[n]), true
+	case n == regPC:
+		return hexU32(cpu.PC), true
+	case n >= regF0 && n < regF0+32:
+		switch flen(cpu) {
+		case 64:
+			return hexU64(cpu.F[n-regF0]), true
+		case 32:
+			return hexU32(uint32(cpu.F[n-regF0])), true
+		}
+		return "", false
+	case n == regPriv:
+		return hexU32(cpu.Priv), true
+	case n >= regCsr0 && n < regPriv:
+		addr := uint32(n - regCsr0)
+		switch addr {
+		// GDB can read them while mstatus.FS is off
+		case rv32i.CsrFflags:
+			return hexU32(cpu.Csr.Fcsr & 0b11111), true
+		case rv32i.CsrFrm:
+			return hexU32(cpu.Csr.Fcsr >> 5), true
+		case rv32i.CsrFcsr:
+			return hexU32(cpu.Csr.Fcsr), true
+		}
+		v, ok := cpu.ReadCsr(addr)
+		if !ok {
+			return "", false
+		}
+		return hexU32(v), true
+	}
+	return "", false
+}
+
+// writeRegister writes v to the register n. It returns false if the
+// register doesn't exist or is read-only.
+func writeRegister(cpu *rv32i.Cpu, n int, v uint64) bool {
+	switch {
+	case n == 0:
+		// x0 is hardwired to 0
+		return true
+	case n < 32:
+		cpu.X[n] = uint32(v)
+	case n == regPC:
+		cpu.PC = uint32(v)
+	case n >= regF0 && n < regF0+32:
+		switch flen(cpu) {
+		case 64:
+			cpu.F[n-regF0] = v
+		case 32:
+			// NaN-boxed
+			cpu.F[n-regF0] = 0xffffffff_00000000 | v&0xffffffff
+		default:
+			return false
+		}
+	case n == regPriv:
+		switch uint32(v) {
+		case rv32i.PrivU, rv32i.PrivS, rv32i.PrivM:
+			cpu.Priv = uint32(v)
+		default:
+			return false
+		}
+	case n >= regCsr0 && n < regPriv:
+		f := &cpu.Csr
+		switch addr := uint32(n - regCsr0); addr {
+		// GDB can write them while mstatus.FS is off
+		case rv32i.CsrFflags:
+			f.Fcsr = f.Fcsr&^0b11111 | uint32(v)&0b11111
+		case rv32i.CsrFrm:
+			f.Fcsr = f.Fcsr&0b11111 | (uint32(v)&0b111)<<5
+		case rv32i.CsrFcsr:
+			f.Fcsr = uint32(v) & 0xff
+		default:
+			return cpu.WriteCsr(addr, uint32(v))
+		}
+	default:
+		return false
+	}
+	return true
+}